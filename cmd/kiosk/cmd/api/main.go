@@ -11,7 +11,9 @@ import (
 	"time"
 
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/api"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/certs"
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/tokens"
 )
 
 func main() {
@@ -27,18 +29,58 @@ func main() {
 
 	token := cfg.Get("API_TOKEN")
 	if token == "" {
-		log.Fatal("API_TOKEN is not configured. Run: kiosk api token regenerate")
+		store, err := tokens.Load(tokens.DefaultPath)
+		if err != nil {
+			log.Fatalf("Failed to load token store: %v", err)
+		}
+		if len(store.Tokens) == 0 {
+			log.Fatal("No API tokens configured. Run: kiosk api token regenerate or sudo kiosk api token create")
+		}
 	}
 
-	srv := api.NewServer(port, token)
+	srv := api.NewServer(port, token, cfg.Get("METRICS_TOKEN"))
+
+	tlsEnabled := cfg.Get("API_TLS_ENABLED") == "true"
+	if tlsEnabled {
+		certPath := cfg.Get("API_TLS_CERT")
+		if certPath == "" {
+			certPath = certs.DefaultCertPath
+		}
+		keyPath := cfg.Get("API_TLS_KEY")
+		if keyPath == "" {
+			keyPath = certs.DefaultKeyPath
+		}
+
+		created, err := certs.EnsureSelfSigned(certPath, keyPath)
+		if err != nil {
+			log.Fatalf("Failed to create self-signed certificate: %v", err)
+		}
+		if created {
+			log.Printf("Generated self-signed certificate %s", certPath)
+		}
+
+		tlsConfig, err := certs.ServerConfig(certPath, keyPath,
+			cfg.Get("API_TLS_CLIENT_CA"), cfg.Get("API_TLS_CLIENT_AUTH") == "required")
+		if err != nil {
+			log.Fatalf("Failed to configure TLS: %v", err)
+		}
+		srv.TLSConfig = tlsConfig
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
 	go func() {
 		addr := fmt.Sprintf("0.0.0.0:%s", port)
-		log.Printf("Starting API server on %s", addr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if tlsEnabled {
+			log.Printf("Starting API server on %s (TLS)", addr)
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			log.Printf("Starting API server on %s", addr)
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Server failed: %v", err)
 		}
 	}()
@@ -3,33 +3,50 @@ package main
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
-	"os/exec"
+	"os"
 	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/certs"
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/tokens"
 
 	"github.com/spf13/cobra"
 )
 
-const apiServiceName = "wpe-webkit-kiosk-api"
+const apiServiceName = service.APIUnit
+
+// legacyTokenName is how the API_TOKEN config value appears in the audit log.
+const legacyTokenName = "api_token"
 
 var apiCmd = &cobra.Command{
-	Use:   "api",
-	Short: "Manage REST API service",
+	Use:         "api",
+	Short:       "Manage REST API service",
+	Annotations: map[string]string{localOnlyAnnotation: ""},
+}
+
+// apiStatus is the structured output of "kiosk api status".
+type apiStatus struct {
+	Service    string `json:"service"`
+	Port       string `json:"port"`
+	TLS        bool   `json:"tls"`
+	ClientAuth string `json:"client_auth,omitempty"` // set when client certificates are verified
+	Reachable  bool   `json:"reachable"`
 }
 
 var apiStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show API service status",
+	Long: `Show API service status.
+
+Exits with status 3 if the API service is not active.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		state := "unknown"
-		if out, err := exec.Command("systemctl", "show", apiServiceName,
-			"--property=ActiveState", "--value").Output(); err == nil {
-			state = strings.TrimSpace(string(out))
-		}
+		state := service.State(cmd.Context(), service.Default(), apiServiceName)
 
 		cfg, err := config.Load(config.DefaultPath)
 		if err != nil {
@@ -41,23 +58,48 @@ var apiStatusCmd = &cobra.Command{
 			port = "8100"
 		}
 
-		reachable := "no"
+		st := apiStatus{Service: state, Port: port, TLS: cfg.Get("API_TLS_ENABLED") == "true"}
 		conn, err := net.DialTimeout("tcp", "127.0.0.1:"+port, 2*time.Second)
 		if err == nil {
 			conn.Close()
-			reachable = "yes"
+			st.Reachable = true
+		}
+		if st.TLS && cfg.Get("API_TLS_CLIENT_CA") != "" {
+			st.ClientAuth = clientAuthMode(cfg)
+		}
+
+		err = printResult(st, func() {
+			tlsMode := "disabled"
+			if st.TLS {
+				tlsMode = "enabled"
+				if st.ClientAuth != "" {
+					tlsMode += ", client certificates " + st.ClientAuth
+				}
+			}
+			reachable := "no"
+			if st.Reachable {
+				reachable = "yes"
+			}
+			fmt.Printf("Service:    %s\n", st.Service)
+			fmt.Printf("Port:       %s\n", st.Port)
+			fmt.Printf("TLS:        %s\n", tlsMode)
+			fmt.Printf("Reachable:  %s\n", reachable)
+		})
+		if err != nil {
+			return err
 		}
 
-		fmt.Printf("Service:    %s\n", state)
-		fmt.Printf("Port:       %s\n", port)
-		fmt.Printf("Reachable:  %s\n", reachable)
+		if st.Service != "active" {
+			cmd.SilenceUsage = true
+			return &serviceDownError{fmt.Errorf("%s is %s", apiServiceName, st.Service)}
+		}
 		return nil
 	},
 }
 
 var apiTokenCmd = &cobra.Command{
 	Use:   "token",
-	Short: "Manage API authentication token",
+	Short: "Manage API authentication tokens",
 }
 
 var apiTokenShowCmd = &cobra.Command{
@@ -69,12 +111,13 @@ var apiTokenShowCmd = &cobra.Command{
 			return err
 		}
 		token := cfg.Get("API_TOKEN")
-		if token == "" {
+		if token == "" && !structuredOutput() {
 			fmt.Println("API_TOKEN is not configured. Run: kiosk api token regenerate")
 			return nil
 		}
-		fmt.Println(token)
-		return nil
+		return printResult(map[string]string{"token": token}, func() {
+			fmt.Println(token)
+		})
 	},
 }
 
@@ -94,21 +137,295 @@ var apiTokenRegenerateCmd = &cobra.Command{
 
 		cfg.Set("API_TOKEN", token)
 		if err := cfg.Save(); err != nil {
+			recordAudit("token.regenerate", legacyTokenName, nil, nil, err)
 			return err
 		}
+		recordAudit("token.regenerate", legacyTokenName, nil, nil, nil)
 
-		fmt.Printf("New token: %s\n", token)
+		humanf("New token: %s\n", token)
 
-		if err := exec.Command("sudo", "systemctl", "restart", apiServiceName).Run(); err != nil {
-			fmt.Println("Token saved. API service restart failed — restart manually: sudo systemctl restart " + apiServiceName)
+		restarted := service.Default().Restart(cmd.Context(), apiServiceName) == nil
+		if !restarted {
+			humanf("Token saved. API service restart failed — restart manually: sudo systemctl restart %s\n", apiServiceName)
 		} else {
-			fmt.Println("API service restarted.")
+			humanf("API service restarted.\n")
 		}
 
-		return nil
+		return printStructured(map[string]any{"token": token, "api_restarted": restarted})
+	},
+}
+
+var (
+	tokenScopes  string
+	tokenExpires string
+)
+
+var apiTokenCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a named API token with scopes",
+	Long: "Create a named API token. Scopes: " + strings.Join(tokens.ScopeNames(), ", ") + " (admin implies all).\n" +
+		"The token secret is shown only once.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		expires, err := tokens.ParseExpiry(tokenExpires, time.Now())
+		if err != nil {
+			return err
+		}
+
+		store, err := loadTokenStore()
+		if err != nil {
+			return err
+		}
+
+		scopes := tokens.ParseScopes(tokenScopes)
+		secret, err := store.Create(args[0], scopes, expires)
+		if err != nil {
+			return err
+		}
+		if err := store.Save(); err != nil {
+			recordAudit("token.create", args[0], nil, scopes, err)
+			return err
+		}
+		recordAudit("token.create", args[0], nil, scopes, nil)
+
+		t, _ := store.Get(args[0])
+		result := tokenEntry(t, time.Now())
+		return printResult(struct {
+			tokenInfo
+			Secret string `json:"secret"`
+		}{result, secret}, func() {
+			fmt.Printf("Token %q created.\n", args[0])
+			fmt.Printf("Secret: %s\n", secret)
+			fmt.Println("Store it now — it cannot be shown again.")
+		})
+	},
+}
+
+var apiTokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List named API tokens",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadTokenStore()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		list := make([]tokenInfo, len(store.Tokens))
+		for i := range store.Tokens {
+			list[i] = tokenEntry(&store.Tokens[i], now)
+		}
+
+		return printResult(list, func() {
+			if len(list) == 0 {
+				fmt.Println("No named tokens. Create one with: sudo kiosk api token create <name> --scopes read")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSCOPES\tCREATED\tEXPIRES\tSTATUS")
+			for _, t := range list {
+				expires := "never"
+				if t.Expires != nil {
+					expires = t.Expires.Local().Format("2006-01-02 15:04")
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", t.Name, strings.Join(t.Scopes, ","),
+					t.Created.Local().Format("2006-01-02 15:04"), expires, t.Status)
+			}
+			w.Flush()
+		})
+	},
+}
+
+var apiTokenRevokeCmd = &cobra.Command{
+	Use:   "revoke <name>",
+	Short: "Revoke a named API token",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadTokenStore()
+		if err != nil {
+			return err
+		}
+		if err := store.Revoke(args[0]); err != nil {
+			return err
+		}
+		if err := store.Save(); err != nil {
+			recordAudit("token.revoke", args[0], nil, nil, err)
+			return err
+		}
+		recordAudit("token.revoke", args[0], nil, nil, nil)
+		return printResult(map[string]string{"token": args[0], "status": "revoked"}, func() {
+			fmt.Printf("Token %q revoked.\n", args[0])
+		})
+	},
+}
+
+// loadTokenStore loads the token store, which only root can read.
+func loadTokenStore() (*tokens.Store, error) {
+	store, err := tokens.Load(tokens.DefaultPath)
+	if errors.Is(err, os.ErrPermission) {
+		return nil, fmt.Errorf("%w — run with sudo", err)
+	}
+	return store, err
+}
+
+// tokenInfo describes a named token without its hash.
+type tokenInfo struct {
+	Name    string     `json:"name"`
+	Scopes  []string   `json:"scopes"`
+	Created time.Time  `json:"created"`
+	Expires *time.Time `json:"expires"`
+	Status  string     `json:"status"` // active or expired
+}
+
+func tokenEntry(t *tokens.Token, now time.Time) tokenInfo {
+	status := "active"
+	if t.Expired(now) {
+		status = "expired"
+	}
+	return tokenInfo{Name: t.Name, Scopes: t.Scopes, Created: t.Created, Expires: t.Expires, Status: status}
+}
+
+var apiCertCmd = &cobra.Command{
+	Use:   "cert",
+	Short: "Manage the API TLS certificate",
+}
+
+var apiCertShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the API certificate and its fingerprint",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(config.DefaultPath)
+		if err != nil {
+			return err
+		}
+		certPath, _ := tlsPaths(cfg)
+
+		info, err := certs.Load(certPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("no certificate at %s; it is generated when kiosk-api starts with API_TLS_ENABLED=\"true\"", certPath)
+			}
+			return err
+		}
+
+		names := append(append([]string{}, info.DNSNames...), info.IPAddresses...)
+		result := certInfo{
+			File:        certPath,
+			Subject:     info.Subject,
+			Issuer:      info.Issuer,
+			SelfSigned:  info.SelfSigned,
+			Names:       names,
+			NotBefore:   info.NotBefore,
+			NotAfter:    info.NotAfter,
+			Fingerprint: info.Fingerprint,
+			ClientCA:    cfg.Get("API_TLS_CLIENT_CA"),
+			TLSEnabled:  cfg.Get("API_TLS_ENABLED") == "true",
+		}
+		if result.ClientCA != "" {
+			result.ClientAuth = clientAuthMode(cfg)
+		}
+
+		return printResult(result, func() {
+			kind := "CA-issued"
+			if info.SelfSigned {
+				kind = "self-signed"
+			}
+			fmt.Printf("File:         %s\n", certPath)
+			fmt.Printf("Subject:      %s\n", info.Subject)
+			fmt.Printf("Issuer:       %s (%s)\n", info.Issuer, kind)
+			fmt.Printf("Names:        %s\n", strings.Join(names, ", "))
+			fmt.Printf("Valid:        %s — %s\n", info.NotBefore.Local().Format("2006-01-02"), info.NotAfter.Local().Format("2006-01-02"))
+			fmt.Printf("SHA-256:      %s\n", info.Fingerprint)
+			if result.ClientCA != "" {
+				fmt.Printf("Client CA:    %s (%s)\n", result.ClientCA, result.ClientAuth)
+			}
+			if !result.TLSEnabled {
+				fmt.Println("TLS is disabled. Enable it with: kiosk config set API_TLS_ENABLED true")
+			}
+		})
+	},
+}
+
+// certInfo is the structured output of "kiosk api cert show".
+type certInfo struct {
+	File        string    `json:"file"`
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	SelfSigned  bool      `json:"self_signed"`
+	Names       []string  `json:"names"`
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
+	Fingerprint string    `json:"fingerprint"`
+	ClientCA    string    `json:"client_ca,omitempty"`
+	ClientAuth  string    `json:"client_auth,omitempty"`
+	TLSEnabled  bool      `json:"tls_enabled"`
+}
+
+var certRotateForce bool
+
+var apiCertRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Replace the self-signed API certificate with a new one",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(config.DefaultPath)
+		if err != nil {
+			return err
+		}
+		certPath, keyPath := tlsPaths(cfg)
+
+		if info, err := certs.Load(certPath); err == nil && !info.SelfSigned && !certRotateForce {
+			return fmt.Errorf("%s is not self-signed; replace it with a new CA-issued certificate or use --force", certPath)
+		}
+
+		if err := certs.GenerateSelfSigned(certPath, keyPath); err != nil {
+			recordAudit("cert.rotate", certPath, nil, nil, err)
+			if errors.Is(err, os.ErrPermission) {
+				return fmt.Errorf("%w — run with sudo: sudo kiosk api cert rotate", err)
+			}
+			return err
+		}
+
+		info, err := certs.Load(certPath)
+		if err != nil {
+			return err
+		}
+		recordAudit("cert.rotate", certPath, nil, info.Fingerprint, nil)
+
+		humanf("New certificate: %s\n", certPath)
+		humanf("SHA-256: %s\n", info.Fingerprint)
+
+		restarted := service.Default().Restart(cmd.Context(), apiServiceName) == nil
+		if !restarted {
+			humanf("Certificate saved. API service restart failed — restart manually: sudo systemctl restart %s\n", apiServiceName)
+		} else {
+			humanf("API service restarted.\n")
+		}
+		return printStructured(map[string]any{
+			"file":          certPath,
+			"fingerprint":   info.Fingerprint,
+			"api_restarted": restarted,
+		})
 	},
 }
 
+func tlsPaths(cfg *config.Config) (certPath, keyPath string) {
+	certPath, keyPath = cfg.Get("API_TLS_CERT"), cfg.Get("API_TLS_KEY")
+	if certPath == "" {
+		certPath = certs.DefaultCertPath
+	}
+	if keyPath == "" {
+		keyPath = certs.DefaultKeyPath
+	}
+	return certPath, keyPath
+}
+
+func clientAuthMode(cfg *config.Config) string {
+	if cfg.Get("API_TLS_CLIENT_AUTH") == "required" {
+		return "required"
+	}
+	return "optional"
+}
+
 func generateToken() (string, error) {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
@@ -120,7 +437,16 @@ func generateToken() (string, error) {
 func init() {
 	apiTokenCmd.AddCommand(apiTokenShowCmd)
 	apiTokenCmd.AddCommand(apiTokenRegenerateCmd)
+	apiTokenCreateCmd.Flags().StringVar(&tokenScopes, "scopes", tokens.ScopeRead, "Comma-separated scopes ("+strings.Join(tokens.ScopeNames(), ", ")+")")
+	apiTokenCreateCmd.Flags().StringVar(&tokenExpires, "expires", "", "Expiry as duration (12h, 30d) or date (2026-12-31)")
+	apiTokenCmd.AddCommand(apiTokenCreateCmd)
+	apiTokenCmd.AddCommand(apiTokenListCmd)
+	apiTokenCmd.AddCommand(apiTokenRevokeCmd)
 	apiCmd.AddCommand(apiStatusCmd)
 	apiCmd.AddCommand(apiTokenCmd)
+	apiCertRotateCmd.Flags().BoolVar(&certRotateForce, "force", false, "Replace a certificate that is not self-signed")
+	apiCertCmd.AddCommand(apiCertShowCmd)
+	apiCertCmd.AddCommand(apiCertRotateCmd)
+	apiCmd.AddCommand(apiCertCmd)
 	rootCmd.AddCommand(apiCmd)
 }
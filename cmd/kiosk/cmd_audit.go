@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/audit"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditSince  string
+	auditUntil  string
+	auditAction string
+	auditActor  string
+	auditLimit  int
+)
+
+var auditCmd = &cobra.Command{
+	Use:         "audit",
+	Short:       "Show the audit log of mutating actions",
+	Annotations: map[string]string{localOnlyAnnotation: ""},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		now := time.Now()
+		filter := audit.Filter{Action: auditAction, Actor: auditActor, Limit: auditLimit}
+
+		if auditSince != "" {
+			t, err := audit.ParseTime(auditSince, now)
+			if err != nil {
+				return err
+			}
+			filter.Since = t
+		}
+		if auditUntil != "" {
+			t, err := audit.ParseTime(auditUntil, now)
+			if err != nil {
+				return err
+			}
+			filter.Until = t
+		}
+
+		entries, err := audit.New(audit.DefaultPath).Query(filter)
+		if err != nil {
+			return err
+		}
+
+		if entries == nil {
+			entries = []audit.Entry{}
+		}
+
+		return printResult(entries, func() {
+			if len(entries) == 0 {
+				fmt.Println("No audit entries found.")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tACTOR\tSOURCE\tACTION\tTARGET\tCHANGE\tOUTCOME")
+			for _, e := range entries {
+				change := ""
+				if e.Old != nil || e.New != nil {
+					change = fmt.Sprintf("%v -> %v", valueOrDash(e.Old), valueOrDash(e.New))
+				}
+				outcome := e.Outcome
+				if e.Error != "" {
+					outcome += ": " + e.Error
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					e.Time.Local().Format("2006-01-02 15:04:05"), e.Actor, e.Source,
+					e.Action, valueOrDash(e.Target), change, outcome)
+			}
+			w.Flush()
+		})
+	},
+}
+
+// auditAppendCmd records an entry for a user who cannot write the audit
+// log. The CLI and TUI run it through sudo (see audit.SudoAppend).
+var auditAppendCmd = &cobra.Command{
+	Use:    "append",
+	Short:  "Append an audit entry read from stdin (run through sudo)",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	// Replaces the root hook: this runs as root, whose selected target has
+	// nothing to do with the caller, and always records to the local log.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return audit.New(audit.DefaultPath).AppendForCaller(os.Stdin)
+	},
+}
+
+func valueOrDash(v any) any {
+	if v == nil || v == "" {
+		return "-"
+	}
+	return v
+}
+
+// recordAudit logs a mutating CLI action. Failing to write the audit log
+// only prints a warning; the action itself has already happened.
+func recordAudit(action, target string, oldValue, newValue any, actionErr error) {
+	if err := audit.RecordLocal(audit.SourceCLI, action, target, oldValue, newValue, actionErr); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write audit log: %v\n", err)
+	}
+}
+
+func init() {
+	auditCmd.Flags().StringVar(&auditSince, "since", "", "Show entries newer than a time (24h, 7d, 2026-01-31, RFC 3339)")
+	auditCmd.Flags().StringVar(&auditUntil, "until", "", "Show entries older than a time (same formats as --since)")
+	auditCmd.Flags().StringVar(&auditAction, "action", "", "Filter by action or action prefix (e.g. config, extension.enable)")
+	auditCmd.Flags().StringVar(&auditActor, "actor", "", "Filter by actor (token name or local user)")
+	auditCmd.Flags().IntVarP(&auditLimit, "limit", "n", 50, "Show at most N most recent entries (0 = all)")
+	auditCmd.AddCommand(auditAppendCmd)
+	rootCmd.AddCommand(auditCmd)
+}
@@ -17,7 +17,7 @@ var clearCacheCmd = &cobra.Command{
 	Use:   "clear-cache",
 	Short: "Clear browser disk and memory cache",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return clearWithConfirm("cache", "disk and memory cache")
+		return clearWithConfirm(cmd, "cache", "disk and memory cache")
 	},
 }
 
@@ -25,7 +25,7 @@ var clearCookiesCmd = &cobra.Command{
 	Use:   "clear-cookies",
 	Short: "Clear browser cookies",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return clearWithConfirm("cookies", "all cookies")
+		return clearWithConfirm(cmd, "cookies", "all cookies")
 	},
 }
 
@@ -33,31 +33,51 @@ var clearDataCmd = &cobra.Command{
 	Use:   "clear-data",
 	Short: "Clear all browsing data (cache, cookies, storage)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return clearWithConfirm("all", "all browsing data (cache, cookies, storage)")
+		return clearWithConfirm(cmd, "all", "all browsing data (cache, cookies, storage)")
 	},
 }
 
-func clearWithConfirm(scope, description string) error {
+func clearWithConfirm(cmd *cobra.Command, scope, description string) error {
+	target, err := selectedTarget()
+	if err != nil {
+		return err
+	}
+
 	if !skipConfirm {
-		fmt.Printf("Clear %s? [y/N] ", description)
+		if target != nil {
+			fmt.Fprintf(humanOut(), "Clear %s on %s? [y/N] ", description, target.Name)
+		} else {
+			fmt.Fprintf(humanOut(), "Clear %s? [y/N] ", description)
+		}
 		reader := bufio.NewReader(os.Stdin)
 		answer, _ := reader.ReadString('\n')
 		answer = strings.TrimSpace(strings.ToLower(answer))
 		if answer != "y" && answer != "yes" {
-			fmt.Println("Cancelled")
-			return nil
+			return printResult(map[string]any{"cleared": nil}, func() {
+				fmt.Println("Cancelled")
+			})
 		}
 	}
 
-	client, err := dbus.NewClient()
-	if err != nil {
-		return err
+	if target != nil {
+		if err := clientFor(target).Clear(cmd.Context(), scope); err != nil {
+			return err
+		}
+		return printCleared(scope, description)
 	}
-	if err := client.ClearData(scope); err != nil {
+
+	if err := dbus.Default().ClearData(cmd.Context(), scope); err != nil {
+		recordAudit("clear", scope, nil, nil, err)
 		return err
 	}
-	fmt.Printf("Cleared %s\n", description)
-	return nil
+	recordAudit("clear", scope, nil, nil, nil)
+	return printCleared(scope, description)
+}
+
+func printCleared(scope, description string) error {
+	return printResult(map[string]string{"cleared": scope}, func() {
+		fmt.Printf("Cleared %s\n", description)
+	})
 }
 
 func init() {
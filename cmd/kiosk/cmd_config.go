@@ -1,8 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/client"
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
 
@@ -18,17 +25,48 @@ var configShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Display current configuration",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load(config.DefaultPath)
+		values := make(map[string]string)
+		var keys []string
+
+		rc, err := remoteClient()
 		if err != nil {
 			return err
 		}
-		for _, kv := range cfg.KeyValues() {
-			fmt.Printf("%s=%s\n", kv.Key, kv.Value)
+		if rc != nil {
+			if values, _, err = rc.Config(cmd.Context()); err != nil {
+				return err
+			}
+			for _, key := range config.KeyNames() {
+				if _, ok := values[key]; ok {
+					keys = append(keys, key)
+				}
+			}
+		} else {
+			cfg, err := config.Load(config.DefaultPath)
+			if err != nil {
+				return err
+			}
+			for _, kv := range cfg.KeyValues() {
+				values[kv.Key] = kv.Value
+				keys = append(keys, kv.Key)
+			}
 		}
-		return nil
+
+		return printResult(values, func() {
+			for _, key := range keys {
+				fmt.Printf("%s=%s\n", key, values[key])
+			}
+		})
 	},
 }
 
+// configSetResult matches the response of PUT /config.
+type configSetResult struct {
+	Key             string `json:"key"`
+	Value           string `json:"value"`
+	RestartRequired bool   `json:"restart_required"`
+}
+
 var configSetCmd = &cobra.Command{
 	Use:   "set <key> <value>",
 	Short: "Set a configuration value",
@@ -36,8 +74,29 @@ var configSetCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		key, value := args[0], args[1]
 
-		if !config.ValidKeys[key] {
-			return fmt.Errorf("unknown config key: %s (valid: URL, INSPECTOR_PORT, INSPECTOR_HTTP_PORT)", key)
+		if err := config.Validate(key, value); err != nil {
+			return err
+		}
+		result := configSetResult{Key: key, Value: value}
+
+		rc, err := remoteClient()
+		if err != nil {
+			return err
+		}
+		if rc != nil {
+			res, err := rc.SetConfig(cmd.Context(), key, value)
+			if err != nil {
+				return err
+			}
+			result.RestartRequired = res.RestartRequired
+			return printResult(result, func() {
+				fmt.Printf("Set %s=%s\n", key, value)
+				if res.RestartRequired {
+					fmt.Println("Restart required for this change: kiosk restart")
+				} else {
+					fmt.Println("Applied live (no restart needed)")
+				}
+			})
 		}
 
 		cfg, err := config.Load(config.DefaultPath)
@@ -45,37 +104,265 @@ var configSetCmd = &cobra.Command{
 			return err
 		}
 
+		oldValue := cfg.Get(key)
 		cfg.Set(key, value)
 		if err := cfg.Save(); err != nil {
+			recordAudit("config.set", key, config.Redact(key, oldValue), config.Redact(key, value), err)
 			return err
 		}
+		recordAudit("config.set", key, config.Redact(key, oldValue), config.Redact(key, value), nil)
+		result.RestartRequired = config.NeedsRestart(key)
 
-		fmt.Printf("Set %s=%s\n", key, value)
+		humanf("Set %s=%s\n", key, value)
 
 		if config.LiveKeys[key] {
-			client, err := dbus.NewClient()
-			if err != nil {
-				fmt.Println("Config saved. Service not reachable — change will apply on next start.")
-				return nil
-			}
 			switch key {
 			case "URL":
-				if err := client.Open(value); err != nil {
-					fmt.Printf("Config saved but live apply failed: %v\n", err)
-					return nil
+				err := dbus.Default().Open(cmd.Context(), value)
+				if errors.Is(err, dbus.ErrNotRunning) {
+					humanf("Config saved. Service not reachable — change will apply on next start.\n")
+					return printStructured(result)
+				}
+				if err != nil {
+					humanf("Config saved but live apply failed: %v\n", err)
+					return printStructured(result)
 				}
-				fmt.Println("Applied live (no restart needed)")
+				humanf("Applied live (no restart needed)\n")
 			}
 		} else {
-			fmt.Println("Restart required for this change: kiosk restart")
+			humanf("Restart required for this change: kiosk restart\n")
 		}
 
-		return nil
+		return printStructured(result)
 	},
 }
 
+var configHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List saved configuration versions",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var revs []client.Revision
+
+		rc, err := remoteClient()
+		if err != nil {
+			return err
+		}
+		if rc != nil {
+			if revs, err = rc.ConfigHistory(cmd.Context()); err != nil {
+				return err
+			}
+		} else {
+			if revs, err = localConfigHistory(); err != nil {
+				return err
+			}
+		}
+
+		return printResult(revs, func() {
+			if len(revs) == 0 {
+				fmt.Println("No configuration history yet.")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "REV\tTIME\tCHANGED KEYS")
+			for _, r := range revs {
+				rev := strconv.Itoa(r.Rev)
+				if r.Current {
+					rev += " *"
+				}
+				changed := "(oldest kept)"
+				if r.Changes != nil {
+					changed = changedKeys(r.Changes)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", rev, r.Time.Local().Format("2006-01-02 15:04:05"), changed)
+			}
+			w.Flush()
+			fmt.Println("\n* current version")
+		})
+	},
+}
+
+// localConfigHistory lists saved versions newest first, in the shape of
+// GET /config/history.
+func localConfigHistory() ([]client.Revision, error) {
+	h, err := config.LoadHistory(config.DefaultPath)
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := os.ReadFile(config.DefaultPath)
+	current := config.RedactContent(string(raw)) // revisions are stored redacted
+
+	revs := []client.Revision{}
+	for i := len(h.Revisions) - 1; i >= 0; i-- {
+		r := h.Revisions[i]
+		entry := client.Revision{Rev: r.Rev, Time: r.Time, Current: r.Content == current}
+		if prev, ok := h.Previous(r.Rev); ok {
+			entry.Changes = config.Diff(prev.Content, r.Content)
+		}
+		revs = append(revs, entry)
+	}
+	return revs, nil
+}
+
+var configDiffCmd = &cobra.Command{
+	Use:   "diff <rev>",
+	Short: "Show changes since a saved configuration version",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rev, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid revision: %s", args[0])
+		}
+
+		var changes []config.Change
+		rc, err := remoteClient()
+		if err != nil {
+			return err
+		}
+		if rc != nil {
+			if changes, err = rc.ConfigDiff(cmd.Context(), rev); err != nil {
+				return err
+			}
+		} else {
+			h, err := config.LoadHistory(config.DefaultPath)
+			if err != nil {
+				return err
+			}
+			r, ok := h.Get(rev)
+			if !ok {
+				return fmt.Errorf("config revision %d not found", rev)
+			}
+			current, err := os.ReadFile(config.DefaultPath)
+			if err != nil {
+				return fmt.Errorf("cannot read config: %w", err)
+			}
+			changes = config.Diff(r.Content, config.RedactContent(string(current)))
+		}
+		if changes == nil {
+			changes = []config.Change{}
+		}
+
+		result := map[string]any{"rev": rev, "changes": changes}
+		return printResult(result, func() {
+			if len(changes) == 0 {
+				fmt.Printf("No changes since revision %d.\n", rev)
+				return
+			}
+			for _, c := range changes {
+				if c.Kind != config.ChangeAdded {
+					fmt.Printf("- %s=%s\n", c.Key, c.Old)
+				}
+				if c.Kind != config.ChangeRemoved {
+					fmt.Printf("+ %s=%s\n", c.Key, c.New)
+				}
+			}
+		})
+	},
+}
+
+var configRollbackCmd = &cobra.Command{
+	Use:   "rollback <rev>",
+	Short: "Restore a saved configuration version",
+	Long:  "Restore a saved configuration version. API_TOKEN and METRICS_TOKEN keep their current values.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rev, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid revision: %s", args[0])
+		}
+
+		rc, err := remoteClient()
+		if err != nil {
+			return err
+		}
+		if rc != nil {
+			changes, res, err := rc.ConfigRollback(cmd.Context(), rev)
+			if err != nil {
+				return err
+			}
+			if len(changes) == 0 {
+				humanf("Configuration already matches revision %d.\n", rev)
+				return printRolledBack(rev, changes)
+			}
+			humanf("Rolled back to revision %d: %s\n", rev, changedKeys(changes))
+			if res.RestartRequired {
+				humanf("Restart required for this change: kiosk restart\n")
+			}
+			return printRolledBack(rev, changes)
+		}
+
+		changes, err := config.Rollback(config.DefaultPath, rev)
+		target := fmt.Sprintf("rev %d", rev)
+		if err != nil {
+			recordAudit("config.rollback", target, nil, nil, err)
+			return err
+		}
+		if len(changes) == 0 {
+			humanf("Configuration already matches revision %d.\n", rev)
+			return printRolledBack(rev, changes)
+		}
+		recordAudit("config.rollback", target, nil, changedKeys(changes), nil)
+
+		humanf("Rolled back to revision %d: %s\n", rev, changedKeys(changes))
+		applyConfigChanges(cmd.Context(), changes)
+		return printRolledBack(rev, changes)
+	},
+}
+
+// printRolledBack writes the structured rollback result, in the shape of
+// POST /config/history/{rev}/rollback.
+func printRolledBack(rev int, changes []config.Change) error {
+	restart := false
+	for _, c := range changes {
+		if config.NeedsRestart(c.Key) {
+			restart = true
+		}
+	}
+	if changes == nil {
+		changes = []config.Change{}
+	}
+	return printStructured(map[string]any{
+		"rev":              rev,
+		"changes":          changes,
+		"restart_required": restart,
+	})
+}
+
+// applyConfigChanges applies live keys over D-Bus and tells the user
+// whether a restart is still needed.
+func applyConfigChanges(ctx context.Context, changes []config.Change) {
+	restart := false
+	for _, c := range changes {
+		if !config.LiveKeys[c.Key] {
+			restart = true
+			continue
+		}
+		if c.Key == "URL" && c.New != "" {
+			if err := dbus.Default().Open(ctx, c.New); err != nil {
+				humanf("URL saved but live apply failed: %v\n", err)
+			}
+		}
+	}
+	if restart {
+		humanf("Restart required for this change: kiosk restart\n")
+	}
+}
+
+func changedKeys(changes []config.Change) string {
+	if len(changes) == 0 {
+		return "-"
+	}
+	keys := make([]string, len(changes))
+	for i, c := range changes {
+		keys[i] = c.Key
+	}
+	return strings.Join(keys, ", ")
+}
+
 func init() {
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configHistoryCmd)
+	configCmd.AddCommand(configDiffCmd)
+	configCmd.AddCommand(configRollbackCmd)
 	rootCmd.AddCommand(configCmd)
 }
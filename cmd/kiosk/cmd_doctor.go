@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/doctor"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorFix    bool
+	doctorChecks []string
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the kiosk for common misconfigurations",
+	Long: `Check the kiosk for common misconfigurations and explain how to fix them:
+
+  config               the config file is readable and its values valid
+  tty                  the virtual terminal exists and no login prompt runs on it
+  ports                INSPECTOR_PORT, INSPECTOR_HTTP_PORT, API_PORT and VNC_PORT differ
+  dbus-policy          the D-Bus policy lets the kiosk claim com.wpe.Kiosk
+  sudoers              the sudo rules the CLI relies on are installed
+  extensions-dir       EXTENSIONS_DIR exists and is readable
+  extension-manifests  enabled extensions have a valid manifest.json
+  amixer               amixer is installed for volume control
+
+Each check reports pass, warn or fail. With --fix, problems that are safe
+to repair unattended are fixed: a missing extensions directory is created
+and extensions with a broken manifest are disabled.
+
+Exits with status 1 if a check fails.`,
+	Example: `  kiosk doctor
+  sudo kiosk doctor --fix
+  kiosk doctor --check tty,ports
+  kiosk --target store-12 doctor`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := runDoctor(cmd)
+		if err != nil {
+			return err
+		}
+		if err := printResult(report, func() { printReport(report) }); err != nil {
+			return err
+		}
+		if report.Fail > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d of %d checks failed", report.Fail, len(report.Checks))
+		}
+		return nil
+	},
+}
+
+// runDoctor runs the selected checks on the selected target.
+func runDoctor(cmd *cobra.Command) (*doctor.Report, error) {
+	rc, err := remoteClient()
+	if err != nil {
+		return nil, err
+	}
+	if rc != nil {
+		if doctorFix {
+			return nil, requireLocal(cmd)
+		}
+		return rc.Diagnostics(cmd.Context(), doctorChecks)
+	}
+
+	var checks []doctor.Check
+	for _, id := range doctorChecks {
+		c, ok := doctor.Lookup(id)
+		if !ok {
+			return nil, fmt.Errorf("unknown check %q (available: %s)", id, strings.Join(doctor.IDs(), ", "))
+		}
+		checks = append(checks, c)
+	}
+	report := doctor.Run(cmd.Context(), doctor.DefaultEnv(), checks, doctorFix)
+	for _, r := range report.Checks {
+		if r.Fixed {
+			recordAudit("doctor.fix", r.Check, nil, nil, nil)
+		}
+	}
+	return report, nil
+}
+
+func printReport(report *doctor.Report) {
+	fixable := 0
+	for _, r := range report.Checks {
+		msg := r.Message
+		if r.Fixed {
+			msg += " (fixed)"
+		}
+		fmt.Printf("%-4s  %-20s  %s\n", strings.ToUpper(string(r.Status)), r.Title, msg)
+		if r.Status != doctor.Pass && r.Hint != "" {
+			fmt.Printf("      %-20s  Hint: %s\n", "", r.Hint)
+		}
+		if r.Fixable {
+			fixable++
+		}
+	}
+	fmt.Printf("\n%d passed, %d warnings, %d failed\n", report.Pass, report.Warn, report.Fail)
+	if fixable > 0 && !doctorFix {
+		fmt.Printf("%d problems can be fixed automatically: sudo kiosk doctor --fix\n", fixable)
+	}
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Fix the problems that are safe to repair (local only)")
+	doctorCmd.Flags().StringSliceVar(&doctorChecks, "check", nil, "Run only these checks (comma-separated IDs)")
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/client"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+
+	"github.com/spf13/cobra"
+)
+
+var evalTimeout time.Duration
+
+var evalCmd = &cobra.Command{
+	Use:   "eval <script>",
+	Short: "Run JavaScript in the page and print the result",
+	Long: `Run JavaScript in the page and print its result as JSON.
+
+The script runs in the page's main frame, like in the inspector console;
+the value of its last expression is the result. Promises are not awaited.
+Exceptions are reported as errors (exit status 1).
+
+Remote targets need a token with the "eval" scope.`,
+	Example: `  kiosk eval 'localStorage.getItem("session")'
+  kiosk eval 'window.APP_VERSION'
+  kiosk eval '({url: location.href, items: document.querySelectorAll("li").length})'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if evalTimeout < time.Second || evalTimeout > time.Minute {
+			return fmt.Errorf("--timeout must be between 1s and 1m")
+		}
+		script := args[0]
+
+		result, err := evaluate(cmd, script)
+		if err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		return printResult(map[string]json.RawMessage{"result": result}, func() {
+			var buf bytes.Buffer
+			if json.Indent(&buf, result, "", "  ") != nil {
+				buf.Reset()
+				buf.Write(result)
+			}
+			fmt.Println(buf.String())
+		})
+	},
+}
+
+// evaluate runs script on the selected target.
+func evaluate(cmd *cobra.Command, script string) (json.RawMessage, error) {
+	t, err := selectedTarget()
+	if err != nil {
+		return nil, err
+	}
+	if t != nil {
+		// Leave the kiosk time to report its own timeout
+		rc := client.New(t.BaseURL(), t.Token, client.Options{
+			Insecure:    t.Insecure,
+			Fingerprint: t.Fingerprint,
+			Timeout:     evalTimeout + 10*time.Second,
+		})
+		return rc.Evaluate(cmd.Context(), script, evalTimeout)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), evalTimeout)
+	defer cancel()
+	result, err := dbus.Default().Evaluate(ctx, script)
+	if errors.Is(err, dbus.ErrTimeout) {
+		err = fmt.Errorf("script did not finish within %s", evalTimeout)
+	}
+	recordAudit("eval", "", nil, script, err)
+	return result, err
+}
+
+func init() {
+	evalCmd.Flags().DurationVar(&evalTimeout, "timeout", 5*time.Second, "Maximum time to wait for the result")
+	rootCmd.AddCommand(evalCmd)
+}
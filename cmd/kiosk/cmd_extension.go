@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"text/tabwriter"
 
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/client"
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
 
 	"github.com/spf13/cobra"
@@ -18,11 +19,12 @@ type extensionManifest struct {
 	Version string `json:"version"`
 }
 
+// extensionInfo matches the entries of GET /extensions.
 type extensionInfo struct {
-	DirName  string
-	Name     string
-	Version  string
-	Enabled  bool
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Enabled bool   `json:"enabled"`
+	DirName string `json:"dir_name"`
 }
 
 func getExtensionsDir() string {
@@ -84,6 +86,29 @@ func listExtensions() ([]extensionInfo, error) {
 	return exts, nil
 }
 
+// loadExtensions lists extensions on the selected target. The returned
+// client is nil when running locally.
+func loadExtensions(cmd *cobra.Command) ([]extensionInfo, *client.Client, error) {
+	rc, err := remoteClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	if rc == nil {
+		exts, err := listExtensions()
+		return exts, nil, err
+	}
+
+	remoteExts, err := rc.Extensions(cmd.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+	exts := make([]extensionInfo, len(remoteExts))
+	for i, e := range remoteExts {
+		exts[i] = extensionInfo{DirName: e.DirName, Name: e.Name, Version: e.Version, Enabled: e.Enabled}
+	}
+	return exts, rc, nil
+}
+
 func findExtension(name string, exts []extensionInfo) (*extensionInfo, error) {
 	for i := range exts {
 		if exts[i].DirName == name || exts[i].Name == name {
@@ -107,26 +132,31 @@ var extensionListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all extensions",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		exts, err := listExtensions()
+		exts, _, err := loadExtensions(cmd)
 		if err != nil {
 			return err
 		}
 
-		if len(exts) == 0 {
-			fmt.Println("No extensions found.")
-			return nil
+		if exts == nil {
+			exts = []extensionInfo{}
 		}
 
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "NAME\tVERSION\tSTATUS")
-		for _, e := range exts {
-			status := "disabled"
-			if e.Enabled {
-				status = "enabled"
+		return printResult(exts, func() {
+			if len(exts) == 0 {
+				fmt.Println("No extensions found.")
+				return
 			}
-			fmt.Fprintf(w, "%s\t%s\t%s\n", e.DirName, e.Version, status)
-		}
-		return w.Flush()
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tVERSION\tSTATUS")
+			for _, e := range exts {
+				status := "disabled"
+				if e.Enabled {
+					status = "enabled"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", e.DirName, e.Version, status)
+			}
+			w.Flush()
+		})
 	},
 }
 
@@ -135,7 +165,7 @@ var extensionEnableCmd = &cobra.Command{
 	Short: "Enable an extension",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		exts, err := listExtensions()
+		exts, rc, err := loadExtensions(cmd)
 		if err != nil {
 			return err
 		}
@@ -146,8 +176,15 @@ var extensionEnableCmd = &cobra.Command{
 		}
 
 		if ext.Enabled {
-			fmt.Printf("Extension %q is already enabled.\n", ext.DirName)
-			return nil
+			humanf("Extension %q is already enabled.\n", ext.DirName)
+			return printStructured(extensionResult(ext.DirName, true))
+		}
+
+		if rc != nil {
+			if err := rc.SetExtensionEnabled(cmd.Context(), ext.DirName, true); err != nil {
+				return fmt.Errorf("cannot enable extension: %w", err)
+			}
+			return printExtensionToggled(ext.DirName, true)
 		}
 
 		dir := getExtensionsDir()
@@ -155,13 +192,13 @@ var extensionEnableCmd = &cobra.Command{
 		if err := os.Remove(disabledPath); err != nil {
 			// Fallback to sudo rm for permission issues
 			if cmdErr := exec.Command("sudo", "/usr/bin/rm", disabledPath).Run(); cmdErr != nil {
+				recordAudit("extension.enable", ext.DirName, nil, nil, err)
 				return fmt.Errorf("cannot enable extension: %w", err)
 			}
 		}
+		recordAudit("extension.enable", ext.DirName, nil, nil, nil)
 
-		fmt.Printf("Extension %q enabled.\n", ext.DirName)
-		fmt.Println("Restart the kiosk to apply: kiosk restart")
-		return nil
+		return printExtensionToggled(ext.DirName, true)
 	},
 }
 
@@ -170,7 +207,7 @@ var extensionDisableCmd = &cobra.Command{
 	Short: "Disable an extension",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		exts, err := listExtensions()
+		exts, rc, err := loadExtensions(cmd)
 		if err != nil {
 			return err
 		}
@@ -181,8 +218,15 @@ var extensionDisableCmd = &cobra.Command{
 		}
 
 		if !ext.Enabled {
-			fmt.Printf("Extension %q is already disabled.\n", ext.DirName)
-			return nil
+			humanf("Extension %q is already disabled.\n", ext.DirName)
+			return printStructured(extensionResult(ext.DirName, false))
+		}
+
+		if rc != nil {
+			if err := rc.SetExtensionEnabled(cmd.Context(), ext.DirName, false); err != nil {
+				return fmt.Errorf("cannot disable extension: %w", err)
+			}
+			return printExtensionToggled(ext.DirName, false)
 		}
 
 		dir := getExtensionsDir()
@@ -191,18 +235,35 @@ var extensionDisableCmd = &cobra.Command{
 		if err != nil {
 			// Fallback to sudo touch for permission issues
 			if cmdErr := exec.Command("sudo", "/usr/bin/touch", disabledPath).Run(); cmdErr != nil {
+				recordAudit("extension.disable", ext.DirName, nil, nil, err)
 				return fmt.Errorf("cannot disable extension: %w", err)
 			}
 		} else {
 			f.Close()
 		}
+		recordAudit("extension.disable", ext.DirName, nil, nil, nil)
 
-		fmt.Printf("Extension %q disabled.\n", ext.DirName)
-		fmt.Println("Restart the kiosk to apply: kiosk restart")
-		return nil
+		return printExtensionToggled(ext.DirName, false)
 	},
 }
 
+// extensionResult matches the response of POST /extensions/{name}/enable|disable.
+func extensionResult(dirName string, enabled bool) map[string]string {
+	status := "disabled"
+	if enabled {
+		status = "enabled"
+	}
+	return map[string]string{"extension": dirName, "status": status}
+}
+
+func printExtensionToggled(dirName string, enabled bool) error {
+	result := extensionResult(dirName, enabled)
+	return printResult(result, func() {
+		fmt.Printf("Extension %q %s.\n", dirName, result["status"])
+		fmt.Println("Restart the kiosk to apply: kiosk restart")
+	})
+}
+
 func init() {
 	extensionCmd.AddCommand(extensionListCmd)
 	extensionCmd.AddCommand(extensionEnableCmd)
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/client"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/fleet"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	fleetInventory   string
+	fleetTags        []string
+	fleetHosts       []string
+	fleetConcurrency int
+	fleetTimeout     time.Duration
+)
+
+var fleetCmd = &cobra.Command{
+	Use:   "fleet",
+	Short: "Run commands on many kiosks at once",
+	Long: `Run commands on many kiosks at once.
+
+Hosts are read from an inventory file (default ~/.config/wpe-webkit-kiosk/fleet.json):
+
+  {"hosts": [
+    {"name": "store-12", "host": "10.0.0.12", "token": "...", "tags": ["store", "eu"]},
+    {"name": "lobby", "host": "lobby.local", "port": 8443, "tls": true,
+     "fingerprint": "AB:CD:...", "token": "...", "tags": ["hq"]}
+  ]}
+
+--tag selects hosts carrying all given tags, --host selects hosts by name.
+The command exits with an error if any host fails. --target is ignored.`,
+}
+
+var fleetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the selected hosts",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hosts, err := selectFleetHosts()
+		if err != nil {
+			return err
+		}
+		type entry struct {
+			Name string   `json:"name"`
+			URL  string   `json:"url"`
+			Tags []string `json:"tags"`
+		}
+		list := make([]entry, len(hosts))
+		for i, h := range hosts {
+			list[i] = entry{Name: h.Name, URL: h.BaseURL(), Tags: h.Tags}
+			if list[i].Tags == nil {
+				list[i].Tags = []string{}
+			}
+		}
+
+		return printResult(list, func() {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tURL\tTAGS")
+			for _, e := range list {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", e.Name, e.URL, strings.Join(e.Tags, ","))
+			}
+			w.Flush()
+		})
+	},
+}
+
+var fleetStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show service state and URL of each kiosk",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFleet(cmd, func(ctx context.Context, c *client.Client) (string, error) {
+			st, err := c.Status(ctx)
+			if err != nil {
+				return "", err
+			}
+			if st.Service != "active" {
+				return "", fmt.Errorf("service %s", st.Service)
+			}
+			if st.URL == nil {
+				return "active", nil
+			}
+			return "active  " + *st.URL, nil
+		})
+	},
+}
+
+var fleetOpenCmd = &cobra.Command{
+	Use:   "open <url>",
+	Short: "Navigate each kiosk to URL and save to config",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url := args[0]
+		if err := config.Validate("URL", url); err != nil {
+			return err
+		}
+		return runFleet(cmd, func(ctx context.Context, c *client.Client) (string, error) {
+			return "navigated to " + url, c.Navigate(ctx, url)
+		})
+	},
+}
+
+var fleetReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Reload the current page on each kiosk",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFleet(cmd, func(ctx context.Context, c *client.Client) (string, error) {
+			return "reloaded", c.Reload(ctx)
+		})
+	},
+}
+
+var fleetConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage configuration on many kiosks",
+}
+
+var fleetConfigSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a configuration value on each kiosk",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, value := args[0], args[1]
+		if err := config.Validate(key, value); err != nil {
+			return err
+		}
+		return runFleet(cmd, func(ctx context.Context, c *client.Client) (string, error) {
+			res, err := c.SetConfig(ctx, key, value)
+			if err != nil {
+				return "", err
+			}
+			if res.RestartRequired {
+				return "set " + key + " (restart required)", nil
+			}
+			return "set " + key + " (applied live)", nil
+		})
+	},
+}
+
+var fleetExtensionCmd = &cobra.Command{
+	Use:   "extension",
+	Short: "Manage extensions on many kiosks",
+}
+
+var fleetExtensionEnableCmd = &cobra.Command{
+	Use:   "enable <name>",
+	Short: "Enable an extension on each kiosk",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFleet(cmd, func(ctx context.Context, c *client.Client) (string, error) {
+			return "enabled " + args[0], c.SetExtensionEnabled(ctx, args[0], true)
+		})
+	},
+}
+
+var fleetExtensionDisableCmd = &cobra.Command{
+	Use:   "disable <name>",
+	Short: "Disable an extension on each kiosk",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFleet(cmd, func(ctx context.Context, c *client.Client) (string, error) {
+			return "disabled " + args[0], c.SetExtensionEnabled(ctx, args[0], false)
+		})
+	},
+}
+
+var fleetRestartCmd = &cobra.Command{
+	Use:   "restart",
+	Short: "Restart the kiosk service on each kiosk",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFleet(cmd, func(ctx context.Context, c *client.Client) (string, error) {
+			return "restarting", c.Restart(ctx)
+		})
+	},
+}
+
+func selectFleetHosts() ([]fleet.Host, error) {
+	path := fleetInventory
+	if path == "" {
+		var err error
+		if path, err = fleet.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	inv, err := fleet.LoadInventory(path)
+	if err != nil {
+		return nil, err
+	}
+	hosts := inv.Select(fleetTags, fleetHosts)
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no hosts in %s match the selection", path)
+	}
+	return hosts, nil
+}
+
+// runFleet runs op against every selected host and prints the per-host
+// results. It fails if any host failed.
+func runFleet(cmd *cobra.Command, op func(ctx context.Context, c *client.Client) (string, error)) error {
+	hosts, err := selectFleetHosts()
+	if err != nil {
+		return err
+	}
+
+	results := fleet.Run(cmd.Context(), hosts, fleetConcurrency, fleetTimeout, func(ctx context.Context, h *fleet.Host) (string, error) {
+		c := client.New(h.BaseURL(), h.Token, client.Options{
+			Insecure:    h.Insecure,
+			Fingerprint: h.Fingerprint,
+			Timeout:     fleetTimeout,
+		})
+		return op(ctx, c)
+	})
+
+	failed := fleet.Failed(results)
+	summary := map[string]any{
+		"hosts":     len(results),
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+		"results":   results,
+	}
+	err = printResult(summary, func() {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "HOST\tRESULT\tDETAIL")
+		for _, r := range results {
+			result, detail := "ok", r.Detail
+			if !r.OK {
+				result, detail = "FAILED", r.Error
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", r.Host, result, detail)
+		}
+		w.Flush()
+		fmt.Printf("\n%d of %d hosts succeeded\n", len(results)-failed, len(results))
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d of %d hosts failed", failed, len(results))
+	}
+	return nil
+}
+
+func init() {
+	pf := fleetCmd.PersistentFlags()
+	pf.StringVarP(&fleetInventory, "inventory", "i", "", "Inventory file (default ~/.config/wpe-webkit-kiosk/fleet.json)")
+	pf.StringSliceVarP(&fleetTags, "tag", "t", nil, "Only hosts with all of these tags (repeatable or comma-separated)")
+	pf.StringSliceVar(&fleetHosts, "host", nil, "Only these hosts by name (repeatable or comma-separated)")
+	pf.IntVarP(&fleetConcurrency, "concurrency", "c", fleet.DefaultConcurrency, "Maximum hosts contacted at once")
+	pf.DurationVar(&fleetTimeout, "timeout", fleet.DefaultTimeout, "Per-host timeout")
+
+	fleetConfigCmd.AddCommand(fleetConfigSetCmd)
+	fleetExtensionCmd.AddCommand(fleetExtensionEnableCmd)
+	fleetExtensionCmd.AddCommand(fleetExtensionDisableCmd)
+
+	fleetCmd.AddCommand(fleetListCmd)
+	fleetCmd.AddCommand(fleetStatusCmd)
+	fleetCmd.AddCommand(fleetOpenCmd)
+	fleetCmd.AddCommand(fleetReloadCmd)
+	fleetCmd.AddCommand(fleetConfigCmd)
+	fleetCmd.AddCommand(fleetExtensionCmd)
+	fleetCmd.AddCommand(fleetRestartCmd)
+	rootCmd.AddCommand(fleetCmd)
+}
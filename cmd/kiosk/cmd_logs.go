@@ -1,33 +1,119 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
-	"os/exec"
-	"syscall"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/audit"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/logs"
 
 	"github.com/spf13/cobra"
 )
 
-var logsFollow bool
+var (
+	logsFollow   bool
+	logsUnits    []string
+	logsPriority string
+	logsSince    string
+	logsUntil    string
+	logsGrep     string
+	logsLines    int
+)
 
 var logsCmd = &cobra.Command{
 	Use:   "logs",
 	Short: "Show kiosk service logs",
+	Long: `Show the journal of the kiosk services, oldest first.
+
+Units: ` + strings.Join(logs.Units, ", ") + ` (all of them with --unit all).
+--since and --until take a relative age (90m, 24h, 7d), a date
+(2026-01-31) or an RFC 3339 timestamp. --grep is a case-insensitive
+regular expression.
+
+With --output json, entries are printed as JSON objects, one per line.`,
+	Example: `  kiosk logs -f
+  kiosk logs --unit all --priority warning --since 24h
+  kiosk logs --grep 'load failed' -n 20`,
+	Annotations: map[string]string{localOnlyAnnotation: ""},
+	Args:        cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		jargs := []string{"-u", serviceName, "--no-pager", "-n", "100"}
+		if outputFormat == outputYAML {
+			return fmt.Errorf("kiosk logs does not support --output yaml (use json)")
+		}
+
+		f := logs.Filter{Priority: logsPriority, Grep: logsGrep, Limit: logsLines}
+		if !slices.Contains(logsUnits, "all") {
+			f.Units = logsUnits
+		}
+		now := time.Now()
+		if logsSince != "" {
+			t, err := audit.ParseTime(logsSince, now)
+			if err != nil {
+				return err
+			}
+			f.Since = t
+		}
+		if logsUntil != "" {
+			if logsFollow {
+				return fmt.Errorf("--until cannot be combined with --follow")
+			}
+			t, err := audit.ParseTime(logsUntil, now)
+			if err != nil {
+				return err
+			}
+			f.Until = t
+		}
+		if err := f.Validate(); err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+
+		enc := json.NewEncoder(os.Stdout)
+		print := func(e logs.Entry) error {
+			if outputFormat == outputJSON {
+				return enc.Encode(e)
+			}
+			fmt.Println(e)
+			return nil
+		}
+
 		if logsFollow {
-			jargs = append(jargs, "-f")
+			entries, err := logs.Follow(cmd.Context(), f)
+			if err != nil {
+				return err
+			}
+			for e := range entries {
+				if err := print(e); err != nil {
+					return err
+				}
+			}
+			return nil
 		}
 
-		bin, err := exec.LookPath("journalctl")
+		page, err := logs.Query(cmd.Context(), f)
 		if err != nil {
 			return err
 		}
-		return syscall.Exec(bin, append([]string{"journalctl"}, jargs...), os.Environ())
+		for _, e := range slices.Backward(page.Entries) {
+			if err := print(e); err != nil {
+				return err
+			}
+		}
+		return nil
 	},
 }
 
 func init() {
 	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Follow log output")
+	logsCmd.Flags().StringSliceVarP(&logsUnits, "unit", "u", []string{serviceName}, "Units to show, or \"all\"")
+	logsCmd.Flags().StringVarP(&logsPriority, "priority", "p", "", "Only this priority and more severe ones (e.g. warning)")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "Only entries since this time")
+	logsCmd.Flags().StringVar(&logsUntil, "until", "", "Only entries until this time")
+	logsCmd.Flags().StringVarP(&logsGrep, "grep", "g", "", "Only entries whose message matches this expression")
+	logsCmd.Flags().IntVarP(&logsLines, "lines", "n", logs.DefaultLimit, "Number of entries to show")
 	rootCmd.AddCommand(logsCmd)
 }
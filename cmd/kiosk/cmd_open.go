@@ -5,6 +5,7 @@ import (
 
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/policy"
 
 	"github.com/spf13/cobra"
 )
@@ -15,31 +16,63 @@ var openCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		url := args[0]
+		if err := config.Validate("URL", url); err != nil {
+			return err
+		}
 
-		client, err := dbus.NewClient()
+		rc, err := remoteClient()
 		if err != nil {
 			return err
 		}
-		if err := client.Open(url); err != nil {
-			return err
+		if rc != nil {
+			if err := rc.Navigate(cmd.Context(), url); err != nil {
+				return err
+			}
+			return printNavigated(url, nil)
 		}
 
-		cfg, err := config.Load(config.DefaultPath)
-		if err != nil {
-			fmt.Printf("Navigated to %s (could not save to config: %v)\n", url, err)
-			return nil
+		var oldURL string
+		cfg, cfgErr := config.Load(config.DefaultPath)
+		if cfgErr == nil {
+			oldURL = cfg.Get("URL")
+			if p, err := policy.FromValues(cfg.Get); err == nil {
+				if err := p.Check(url).Err(); err != nil {
+					recordAudit("navigate", "", oldURL, url, err)
+					return err
+				}
+			}
 		}
-		cfg.Set("URL", url)
-		if err := cfg.Save(); err != nil {
-			fmt.Printf("Navigated to %s (could not save to config: %v)\n", url, err)
-			return nil
+
+		if err := dbus.Default().Open(cmd.Context(), url); err != nil {
+			recordAudit("navigate", "", oldURL, url, err)
+			return err
 		}
+		recordAudit("navigate", "", oldURL, url, nil)
 
-		fmt.Printf("Navigated to %s (saved to config)\n", url)
-		return nil
+		if cfgErr != nil {
+			return printNavigated(url, cfgErr)
+		}
+		cfg.Set("URL", url)
+		return printNavigated(url, cfg.Save())
 	},
 }
 
+// printNavigated reports a navigation; saveErr is why the URL could not
+// be saved to the config, if it could not.
+func printNavigated(url string, saveErr error) error {
+	result := struct {
+		URL   string `json:"url"`
+		Saved bool   `json:"saved"`
+	}{url, saveErr == nil}
+	return printResult(result, func() {
+		if saveErr != nil {
+			fmt.Printf("Navigated to %s (could not save to config: %v)\n", url, saveErr)
+		} else {
+			fmt.Printf("Navigated to %s (saved to config)\n", url)
+		}
+	})
+}
+
 func init() {
 	rootCmd.AddCommand(openCmd)
 }
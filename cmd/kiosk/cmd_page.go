@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/client"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+
+	"github.com/spf13/cobra"
+)
+
+// pageCommand describes one of the page history commands.
+type pageCommand struct {
+	action string // audit action
+	status string // JSON status
+	done   string // human output
+	remote func(*client.Client, context.Context) error
+	local  func(context.Context, dbus.PageInfo) error
+}
+
+var backCmd = newPageCommand("back", "Go to the previous page", pageCommand{
+	action: "back",
+	status: "went_back",
+	done:   "Went back",
+	remote: (*client.Client).Back,
+	local: func(ctx context.Context, p dbus.PageInfo) error {
+		if !p.CanGoBack {
+			return errors.New("there is no previous page")
+		}
+		return dbus.Default().GoBack(ctx)
+	},
+})
+
+var forwardCmd = newPageCommand("forward", "Go to the next page", pageCommand{
+	action: "forward",
+	status: "went_forward",
+	done:   "Went forward",
+	remote: (*client.Client).Forward,
+	local: func(ctx context.Context, p dbus.PageInfo) error {
+		if !p.CanGoForward {
+			return errors.New("there is no next page")
+		}
+		return dbus.Default().GoForward(ctx)
+	},
+})
+
+var stopCmd = newPageCommand("stop", "Stop loading the current page", pageCommand{
+	action: "stop",
+	status: "stopped",
+	done:   "Loading stopped",
+	remote: (*client.Client).Stop,
+	local: func(ctx context.Context, p dbus.PageInfo) error {
+		return dbus.Default().StopLoading(ctx)
+	},
+})
+
+func newPageCommand(use, short string, pc pageCommand) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rc, err := remoteClient()
+			if err != nil {
+				return err
+			}
+			if rc != nil {
+				if err := pc.remote(rc, cmd.Context()); err != nil {
+					return err
+				}
+				return printPageDone(pc)
+			}
+
+			page, err := dbus.Default().GetPageInfo(cmd.Context())
+			if err == nil {
+				err = pc.local(cmd.Context(), page)
+			}
+			recordAudit(pc.action, "", page.URI, nil, err)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			return printPageDone(pc)
+		},
+	}
+}
+
+func printPageDone(pc pageCommand) error {
+	return printResult(map[string]string{"status": pc.status}, func() {
+		fmt.Println(pc.done)
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(backCmd)
+	rootCmd.AddCommand(forwardCmd)
+	rootCmd.AddCommand(stopCmd)
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/playlist"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	playlistDwell    time.Duration
+	playlistWindow   string
+	playlistPosition int
+)
+
+var playlistCmd = &cobra.Command{
+	Use:   "playlist",
+	Short: "Rotate the kiosk through several pages",
+	Long: `Rotate the kiosk through several pages.
+
+The playlist is an ordered list of URLs, each shown for its dwell time. An
+entry with a time window (e.g. 08:00-18:00, local time) is skipped outside
+that window. While the playlist is running, the kiosk-api service opens the
+entries in turn; the configured URL is left unchanged. The playlist is stored
+in /etc/wpe-webkit-kiosk/playlist.json.`,
+}
+
+var playlistListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List playlist entries and the current and next page",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rc, err := remoteClient()
+		if err != nil {
+			return err
+		}
+		var st *playlist.Status
+		if rc != nil {
+			if st, err = rc.Playlist(cmd.Context()); err != nil {
+				return err
+			}
+		} else {
+			p, err := playlist.Load(playlist.DefaultPath)
+			if err != nil {
+				return err
+			}
+			st = localPlaylistStatus(p)
+		}
+		return printPlaylist(st)
+	},
+}
+
+var playlistAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Add a page to the playlist",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entry := playlist.Entry{URL: args[0], Dwell: int(playlistDwell / time.Second), Window: playlistWindow}
+		if err := entry.Validate(); err != nil {
+			return err
+		}
+
+		rc, err := remoteClient()
+		if err != nil {
+			return err
+		}
+		if rc != nil {
+			st, err := rc.AddPlaylistEntry(cmd.Context(), entry, playlistPosition)
+			if err != nil {
+				return err
+			}
+			return printPlaylist(st)
+		}
+
+		p, err := editLocalPlaylist("playlist.add", entry.URL, func(p *playlist.Playlist) (any, any, error) {
+			return nil, entry, p.Add(entry, playlistPosition)
+		})
+		if err != nil {
+			return err
+		}
+		return printPlaylist(localPlaylistStatus(p))
+	},
+}
+
+var playlistRemoveCmd = &cobra.Command{
+	Use:   "remove <position>",
+	Short: "Remove the entry at a position (see playlist list)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pos, err := strconv.Atoi(args[0])
+		if err != nil || pos < 1 {
+			return fmt.Errorf("position must be a positive number")
+		}
+
+		rc, err := remoteClient()
+		if err != nil {
+			return err
+		}
+		if rc != nil {
+			st, err := rc.RemovePlaylistEntry(cmd.Context(), pos)
+			if err != nil {
+				return err
+			}
+			return printPlaylist(st)
+		}
+
+		p, err := playlist.Load(playlist.DefaultPath)
+		if err != nil {
+			return err
+		}
+		if pos > len(p.Entries) {
+			return fmt.Errorf("no playlist entry at position %d", pos)
+		}
+		target := p.Entries[pos-1].URL
+		p, err = editLocalPlaylist("playlist.remove", target, func(p *playlist.Playlist) (any, any, error) {
+			e, err := p.Remove(pos)
+			return e, nil, err
+		})
+		if err != nil {
+			return err
+		}
+		return printPlaylist(localPlaylistStatus(p))
+	},
+}
+
+var playlistStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start rotating through the playlist",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPlaylistRunning(cmd, true)
+	},
+}
+
+var playlistStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the rotation and stay on the current page",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPlaylistRunning(cmd, false)
+	},
+}
+
+func setPlaylistRunning(cmd *cobra.Command, running bool) error {
+	rc, err := remoteClient()
+	if err != nil {
+		return err
+	}
+	if rc != nil {
+		st, err := rc.SetPlaylistRunning(cmd.Context(), running)
+		if err != nil {
+			return err
+		}
+		return printPlaylist(st)
+	}
+
+	action := "playlist.stop"
+	if running {
+		action = "playlist.start"
+	}
+	p, err := editLocalPlaylist(action, "", func(p *playlist.Playlist) (any, any, error) {
+		if running && len(p.Entries) == 0 {
+			return nil, nil, fmt.Errorf("the playlist has no entries (add one with: kiosk playlist add <url>)")
+		}
+		old := p.Running
+		p.Running = running
+		return old, running, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if running {
+		if state := service.State(context.Background(), service.Default(), apiServiceName); state != "active" {
+			fmt.Fprintf(os.Stderr, "Warning: the rotation is driven by %s, which is not running\n", apiServiceName)
+		}
+	}
+	return printPlaylist(localPlaylistStatus(p))
+}
+
+// editLocalPlaylist applies edit to the local playlist and saves it. The
+// old and new values returned by edit are recorded in the audit log.
+func editLocalPlaylist(action, target string, edit func(p *playlist.Playlist) (any, any, error)) (*playlist.Playlist, error) {
+	p, err := playlist.Load(playlist.DefaultPath)
+	if err != nil {
+		return nil, err
+	}
+	oldValue, newValue, err := edit(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Save(); err != nil {
+		recordAudit(action, target, oldValue, newValue, err)
+		return nil, err
+	}
+	recordAudit(action, target, oldValue, newValue, nil)
+	return p, nil
+}
+
+func localPlaylistStatus(p *playlist.Playlist) *playlist.Status {
+	url, _ := dbus.Default().GetUrl(context.Background())
+	st := p.Status(url, time.Now())
+	return &st
+}
+
+func printPlaylist(st *playlist.Status) error {
+	return printResult(st, func() {
+		state := "stopped"
+		if st.Running {
+			state = "running"
+		}
+		fmt.Printf("Rotation: %s\n", state)
+		if len(st.Entries) == 0 {
+			fmt.Println("\nThe playlist is empty. Add a page with: kiosk playlist add <url>")
+			return
+		}
+
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "POS\tURL\tDWELL\tWINDOW\t")
+		for i, e := range st.Entries {
+			window := e.Window
+			if window == "" {
+				window = "-"
+			}
+			var mark string
+			switch {
+			case st.Current != nil && *st.Current == i+1:
+				mark = "current"
+			case st.Next != nil && *st.Next == i+1:
+				mark = "next"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%ds\t%s\t%s\n", i+1, e.URL, e.Dwell, window, mark)
+		}
+		w.Flush()
+	})
+}
+
+func init() {
+	playlistAddCmd.Flags().DurationVar(&playlistDwell, "dwell", playlist.DefaultDwell*time.Second, "How long the page stays on screen")
+	playlistAddCmd.Flags().StringVar(&playlistWindow, "window", "", "Only show the page during HH:MM-HH:MM (local time)")
+	playlistAddCmd.Flags().IntVar(&playlistPosition, "position", 0, "Insert at this position instead of appending")
+
+	playlistCmd.AddCommand(playlistListCmd)
+	playlistCmd.AddCommand(playlistAddCmd)
+	playlistCmd.AddCommand(playlistRemoveCmd)
+	playlistCmd.AddCommand(playlistStartCmd)
+	playlistCmd.AddCommand(playlistStopCmd)
+	rootCmd.AddCommand(playlistCmd)
+}
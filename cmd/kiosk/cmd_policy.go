@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/client"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/policy"
+
+	"github.com/spf13/cobra"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Show and test the navigation policy",
+	Long: `Show and test the navigation policy.
+
+The policy is kept in the config as space-separated URL patterns:
+
+  NAV_ALLOW  pages the kiosk may open (empty = any page)
+  NAV_DENY   pages the kiosk may never open, checked first
+
+Patterns use * and ? as wildcards:
+
+  example.com *.example.com     host, any scheme and path
+  example.com/shop/*            host and path, any scheme
+  https://example.com           scheme and host, any path
+  file:///opt/app/*             local files
+
+The kiosk refuses blocked navigations, including links, redirects and
+frames, and the API and "kiosk open" reject them. Changes apply without
+a restart:
+
+  kiosk config set NAV_ALLOW "example.com *.example.com"`,
+}
+
+var policyShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "List the allow and deny rules",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := loadPolicy(cmd, "")
+		if err != nil {
+			return err
+		}
+		return printResult(p.Policy, func() {
+			if len(p.Allow) == 0 && len(p.Deny) == 0 {
+				fmt.Println("No navigation policy: every page is allowed.")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "LIST\tPATTERN")
+			for _, r := range p.Deny {
+				fmt.Fprintf(w, "deny\t%s\n", r.Pattern)
+			}
+			for _, r := range p.Allow {
+				fmt.Fprintf(w, "allow\t%s\n", r.Pattern)
+			}
+			w.Flush()
+			if len(p.Allow) == 0 {
+				fmt.Println("\nNo allowlist: pages not denied are allowed.")
+			}
+		})
+	},
+}
+
+var policyTestCmd = &cobra.Command{
+	Use:   "test <url>",
+	Short: "Explain whether the kiosk may open a URL",
+	Long: `Explain whether the kiosk may open a URL and which rule decided.
+
+Exits with status 1 if the URL is blocked.`,
+	Example: `  kiosk policy test https://example.com/shop
+  kiosk -o json policy test https://other.org/`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := loadPolicy(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		d := p.Decision
+		if err := printResult(d, func() {
+			if d.Allowed {
+				fmt.Printf("Allowed: %s\n", d.Reason)
+			}
+		}); err != nil {
+			return err
+		}
+		if !d.Allowed {
+			cmd.SilenceUsage = true
+		}
+		return d.Err()
+	},
+}
+
+// loadPolicy returns the navigation policy of the selected target, with
+// the decision for rawURL if it is not empty.
+func loadPolicy(cmd *cobra.Command, rawURL string) (*client.Policy, error) {
+	rc, err := remoteClient()
+	if err != nil {
+		return nil, err
+	}
+	if rc != nil {
+		return rc.Policy(cmd.Context(), rawURL)
+	}
+
+	cfg, err := config.Load(config.DefaultPath)
+	if err != nil {
+		return nil, err
+	}
+	p, err := policy.FromValues(cfg.Get)
+	if err != nil {
+		return nil, err
+	}
+	result := &client.Policy{Policy: *p}
+	if rawURL != "" {
+		d := p.Check(rawURL)
+		result.Decision = &d
+	}
+	return result, nil
+}
+
+func init() {
+	policyCmd.AddCommand(policyShowCmd)
+	policyCmd.AddCommand(policyTestCmd)
+	rootCmd.AddCommand(policyCmd)
+}
@@ -12,18 +12,32 @@ var reloadCmd = &cobra.Command{
 	Use:   "reload",
 	Short: "Reload current page",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := dbus.NewClient()
+		rc, err := remoteClient()
 		if err != nil {
 			return err
 		}
-		if err := client.Reload(); err != nil {
+		if rc != nil {
+			if err := rc.Reload(cmd.Context()); err != nil {
+				return err
+			}
+			return printReloaded()
+		}
+
+		if err := dbus.Default().Reload(cmd.Context()); err != nil {
+			recordAudit("reload", "", nil, nil, err)
 			return err
 		}
-		fmt.Println("Page reloaded")
-		return nil
+		recordAudit("reload", "", nil, nil, nil)
+		return printReloaded()
 	},
 }
 
+func printReloaded() error {
+	return printResult(map[string]string{"status": "reloaded"}, func() {
+		fmt.Println("Page reloaded")
+	})
+}
+
 func init() {
 	rootCmd.AddCommand(reloadCmd)
 }
@@ -2,8 +2,8 @@ package main
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
 
 	"github.com/spf13/cobra"
 )
@@ -12,17 +12,32 @@ var restartCmd = &cobra.Command{
 	Use:   "restart",
 	Short: "Restart kiosk service",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		c := exec.Command("sudo", "systemctl", "restart", serviceName)
-		c.Stdout = os.Stdout
-		c.Stderr = os.Stderr
-		if err := c.Run(); err != nil {
+		rc, err := remoteClient()
+		if err != nil {
+			return err
+		}
+		if rc != nil {
+			if err := rc.Restart(cmd.Context()); err != nil {
+				return fmt.Errorf("failed to restart service: %w", err)
+			}
+			return printRestarted()
+		}
+
+		if err := service.Default().Restart(cmd.Context(), serviceName); err != nil {
+			recordAudit("restart", serviceName, nil, nil, err)
 			return fmt.Errorf("failed to restart service: %w", err)
 		}
-		fmt.Println("Service restarted")
-		return nil
+		recordAudit("restart", serviceName, nil, nil, nil)
+		return printRestarted()
 	},
 }
 
+func printRestarted() error {
+	return printResult(map[string]string{"status": "restarted"}, func() {
+		fmt.Println("Service restarted")
+	})
+}
+
 func init() {
 	rootCmd.AddCommand(restartCmd)
 }
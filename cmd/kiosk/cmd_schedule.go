@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/schedule"
+
+	"github.com/spf13/cobra"
+)
+
+var scheduleCount int
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Run actions at set times (cron-like)",
+	Long: `Run actions at set times (cron-like).
+
+Entries are kept in /etc/wpe-webkit-kiosk/schedule, one per line:
+
+  # min hour day-of-month month day-of-week  action  [argument]
+  0 22 * * *     stop
+  0 7 * * 1-5    start
+  30 7 * * 1-5   navigate https://example.com/opening
+  @daily         clear cache
+
+Actions: navigate <url>, restart, stop, start, mute, unmute and
+clear [cache|cookies|all]. Times are local. The schedule is run by the
+kiosk-api service, which re-reads the file every minute.`,
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List scheduled actions",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		l, err := loadSchedule(cmd, 0)
+		if err != nil {
+			return err
+		}
+		return printResult(l.Entries, func() {
+			if len(l.Entries) == 0 {
+				fmt.Println("No scheduled actions. Add one with: kiosk schedule add \"0 22 * * *\" stop")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "POS\tSCHEDULE\tACTION")
+			for _, e := range l.Entries {
+				fmt.Fprintf(w, "%d\t%s\t%s\n", e.Position, e.Spec, actionString(e.Entry))
+			}
+			w.Flush()
+		})
+	},
+}
+
+var scheduleNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Show the next scheduled runs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if scheduleCount < 1 || scheduleCount > 100 {
+			return fmt.Errorf("--count must be between 1 and 100")
+		}
+		l, err := loadSchedule(cmd, scheduleCount)
+		if err != nil {
+			return err
+		}
+		return printResult(l.Upcoming, func() {
+			if len(l.Upcoming) == 0 {
+				fmt.Println("Nothing scheduled")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tIN\tACTION\tPOS")
+			now := time.Now()
+			for _, o := range l.Upcoming {
+				t := o.Time.Local()
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", t.Format("Mon 2006-01-02 15:04"),
+					t.Sub(now).Round(time.Minute), actionString(o.Entry), o.Position)
+			}
+			w.Flush()
+		})
+	},
+}
+
+var scheduleAddCmd = &cobra.Command{
+	Use:   "add <schedule> <action> [argument]",
+	Short: "Schedule an action",
+	Example: `  kiosk schedule add "0 22 * * *" stop
+  kiosk schedule add "0 7 * * 1-5" start
+  kiosk schedule add "30 7 * * 1-5" navigate https://example.com/opening
+  kiosk schedule add @daily clear cache`,
+	Args:        cobra.RangeArgs(2, 3),
+	Annotations: map[string]string{localOnlyAnnotation: ""},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		e := schedule.Entry{Spec: args[0], Action: args[1]}
+		if len(args) == 3 {
+			e.Arg = args[2]
+		}
+
+		s, err := schedule.Load(schedule.DefaultPath)
+		if err != nil {
+			return err
+		}
+		if err := s.Add(e); err != nil {
+			return err
+		}
+		err = s.Save()
+		recordAudit("schedule.add", e.Spec, nil, e.String(), err)
+		if err != nil {
+			return err
+		}
+
+		spec, _ := schedule.ParseSpec(e.Spec)
+		next := spec.Next(time.Now())
+		return printResult(schedule.Listed{Position: len(s.Entries), Entry: e}, func() {
+			fmt.Printf("Scheduled %s at position %d\n", actionString(e), len(s.Entries))
+			if !next.IsZero() {
+				fmt.Printf("Next run: %s\n", next.Format("Mon 2006-01-02 15:04"))
+			}
+		})
+	},
+}
+
+var scheduleRemoveCmd = &cobra.Command{
+	Use:         "remove <position>",
+	Short:       "Remove a scheduled action (see schedule list)",
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{localOnlyAnnotation: ""},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pos, err := strconv.Atoi(args[0])
+		if err != nil || pos < 1 {
+			return fmt.Errorf("position must be a positive number")
+		}
+
+		s, err := schedule.Load(schedule.DefaultPath)
+		if err != nil {
+			return err
+		}
+		e, err := s.Remove(pos)
+		if err != nil {
+			return err
+		}
+		err = s.Save()
+		recordAudit("schedule.remove", e.Spec, e.String(), nil, err)
+		if err != nil {
+			return err
+		}
+		return printResult(schedule.Listed{Position: pos, Entry: e}, func() {
+			fmt.Printf("Removed %s (%s)\n", actionString(e), e.Spec)
+		})
+	},
+}
+
+// loadSchedule returns the schedule of the selected target with its next
+// count runs.
+func loadSchedule(cmd *cobra.Command, count int) (*schedule.Listing, error) {
+	rc, err := remoteClient()
+	if err != nil {
+		return nil, err
+	}
+	if rc != nil {
+		return rc.Schedule(cmd.Context(), count)
+	}
+	s, err := schedule.Load(schedule.DefaultPath)
+	if err != nil {
+		return nil, err
+	}
+	l := s.Listing(time.Now(), count)
+	return &l, nil
+}
+
+func actionString(e schedule.Entry) string {
+	if e.Arg == "" {
+		return e.Action
+	}
+	return e.Action + " " + e.Arg
+}
+
+func init() {
+	scheduleNextCmd.Flags().IntVarP(&scheduleCount, "count", "n", 10, "Number of runs to show")
+
+	scheduleCmd.AddCommand(scheduleListCmd)
+	scheduleCmd.AddCommand(scheduleNextCmd)
+	scheduleCmd.AddCommand(scheduleAddCmd)
+	scheduleCmd.AddCommand(scheduleRemoveCmd)
+	rootCmd.AddCommand(scheduleCmd)
+}
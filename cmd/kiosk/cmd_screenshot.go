@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/screenshot"
+
+	"github.com/spf13/cobra"
+)
+
+var screenshotOpts screenshot.Options
+
+var screenshotCmd = &cobra.Command{
+	Use:   "screenshot [file]",
+	Short: "Save a screenshot of the kiosk screen",
+	Long: `Save a screenshot of what the kiosk shows.
+
+The image is written to file, or to screenshot-<date>-<time>.png (.jpg
+with --quality) in the current directory. Use "-" to write it to stdout.`,
+	Example: `  kiosk screenshot
+  kiosk screenshot lobby.png
+  kiosk screenshot --scale 0.5 --quality 80 lobby.jpg
+  kiosk --target store-12 screenshot - > store-12.png`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := screenshotOpts.Validate(); err != nil {
+			return err
+		}
+		data, err := takeScreenshot(cmd)
+		if err != nil {
+			return err
+		}
+
+		file := "screenshot-" + time.Now().Format("20060102-150405") + screenshotOpts.Ext()
+		if len(args) == 1 {
+			file = args[0]
+		}
+		if file == "-" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			return err
+		}
+
+		result := struct {
+			File        string `json:"file"`
+			ContentType string `json:"content_type"`
+			Bytes       int    `json:"bytes"`
+		}{file, screenshotOpts.ContentType(), len(data)}
+		return printResult(result, func() {
+			fmt.Printf("Saved %s (%d KiB)\n", file, (len(data)+1023)/1024)
+		})
+	},
+}
+
+// takeScreenshot captures the screen of the selected target.
+func takeScreenshot(cmd *cobra.Command) ([]byte, error) {
+	rc, err := remoteClient()
+	if err != nil {
+		return nil, err
+	}
+	if rc != nil {
+		return rc.Screenshot(cmd.Context(), screenshotOpts)
+	}
+
+	png, err := dbus.Default().Screenshot(cmd.Context())
+	if err != nil {
+		return nil, err
+	}
+	return screenshot.Encode(png, screenshotOpts)
+}
+
+func init() {
+	screenshotCmd.Flags().Float64Var(&screenshotOpts.Scale, "scale", 0, "Scale factor between 0.1 and 1 (default full size)")
+	screenshotCmd.Flags().IntVar(&screenshotOpts.Quality, "quality", 0, "Save as JPEG with this quality (1-100)")
+	rootCmd.AddCommand(screenshotCmd)
+}
@@ -2,10 +2,11 @@ package main
 
 import (
 	"fmt"
-	"os/exec"
-	"strings"
+	"time"
 
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/client"
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
 
 	"github.com/spf13/cobra"
 )
@@ -13,51 +14,113 @@ import (
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show kiosk service status",
+	Long: `Show kiosk service status.
+
+Exits with status 3 if the kiosk service is not active.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		state, err := systemctlProperty("ActiveState")
+		st, err := kioskStatus(cmd)
 		if err != nil {
-			state = "unknown"
+			return err
 		}
 
-		uptime := ""
-		if state == "active" {
-			if out, err := exec.Command("systemctl", "show", serviceName,
-				"--property=ActiveEnterTimestamp", "--value").Output(); err == nil {
-				uptime = strings.TrimSpace(string(out))
+		err = printResult(st, func() {
+			fmt.Printf("Service:  %s\n", st.Service)
+			if st.Uptime != nil {
+				fmt.Printf("Since:    %s\n", *st.Uptime)
+			}
+			if st.URL != nil {
+				fmt.Printf("URL:      %s\n", *st.URL)
+			} else if st.Service == "active" {
+				fmt.Printf("URL:      (service not reachable)\n")
+			}
+			if st.Page != nil {
+				if st.Page.Title != "" {
+					fmt.Printf("Title:    %s\n", st.Page.Title)
+				}
+				fmt.Printf("Page:     %s\n", pageString(st.Page))
 			}
+			if st.Idle != nil {
+				fmt.Printf("Idle:     %s\n", idleString(st.Idle))
+			}
+		})
+		if err != nil {
+			return err
 		}
 
-		url := ""
-		client, dbusErr := dbus.NewClient()
-		if dbusErr == nil {
-			url, _ = client.GetUrl()
+		if st.Service != "active" {
+			cmd.SilenceUsage = true
+			return &serviceDownError{fmt.Errorf("kiosk service is %s", st.Service)}
 		}
+		return nil
+	},
+}
+
+// kioskStatus returns the service state of the selected target, in the
+// shape of GET /status.
+func kioskStatus(cmd *cobra.Command) (*client.Status, error) {
+	rc, err := remoteClient()
+	if err != nil {
+		return nil, err
+	}
+	if rc != nil {
+		return rc.Status(cmd.Context())
+	}
 
-		fmt.Printf("Service:  %s\n", state)
-		if uptime != "" {
-			fmt.Printf("Since:    %s\n", uptime)
+	st := &client.Status{Service: "unknown"}
+	if s, err := service.Default().Status(cmd.Context(), serviceName); err == nil {
+		st.Service = s.State
+		if uptime := s.ActiveSince(); uptime != "" {
+			st.Uptime = &uptime
 		}
+	}
+	k := dbus.Default()
+	if url, err := k.GetUrl(cmd.Context()); err == nil {
 		if url != "" {
-			fmt.Printf("URL:      %s\n", url)
-		} else if dbusErr != nil {
-			fmt.Printf("URL:      (service not reachable)\n")
+			st.URL = &url
 		}
-
-		return nil
-	},
+		if p, err := k.GetPageInfo(cmd.Context()); err == nil {
+			page := client.Page(p)
+			st.Page = &page
+		}
+		if idle, err := k.GetIdle(cmd.Context()); err == nil {
+			st.Idle = &client.Idle{Timeout: int(idle.Timeout), Action: idle.Action, Seconds: int(idle.Seconds)}
+		}
+	}
+	return st, nil
 }
 
-const serviceName = "wpe-webkit-kiosk"
+// pageString describes the load state and history of the page, e.g.
+// "loading 40%, can go back".
+func pageString(p *client.Page) string {
+	s := "loaded"
+	switch {
+	case p.Loading:
+		s = fmt.Sprintf("loading %d%%", int(p.Progress*100))
+	case p.LastError != "":
+		s = "failed: " + p.LastError
+	}
+	switch {
+	case p.CanGoBack && p.CanGoForward:
+		s += ", can go back and forward"
+	case p.CanGoBack:
+		s += ", can go back"
+	case p.CanGoForward:
+		s += ", can go forward"
+	}
+	return s
+}
 
-func systemctlProperty(prop string) (string, error) {
-	out, err := exec.Command("systemctl", "show", serviceName,
-		"--property="+prop, "--value").Output()
-	if err != nil {
-		return "", err
+// idleString describes the idle watchdog, e.g. "42s (reset after 5m0s)".
+func idleString(idle *client.Idle) string {
+	seconds := time.Duration(idle.Seconds) * time.Second
+	if idle.Timeout == 0 {
+		return fmt.Sprintf("%s (timeout off)", seconds)
 	}
-	return strings.TrimSpace(string(out)), nil
+	return fmt.Sprintf("%s (%s after %s)", seconds, idle.Action, time.Duration(idle.Timeout)*time.Second)
 }
 
+const serviceName = service.KioskUnit
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
 }
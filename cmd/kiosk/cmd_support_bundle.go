@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/bundle"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/client"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+// supportBundleTimeout bounds fetching a bundle from a remote target. It
+// exceeds bundle.CollectTimeout to leave time to transfer the archive.
+const supportBundleTimeout = 2 * time.Minute
+
+var supportBundleScreenshot bool
+
+var supportBundleCmd = &cobra.Command{
+	Use:   "support-bundle [file]",
+	Short: "Collect diagnostics into a tar.gz for a support request",
+	Long: `Collect what is needed to diagnose the kiosk into a tar.gz archive:
+the config (with API_TOKEN and other secrets redacted), service states,
+the last ` + fmt.Sprint(bundle.LogLines) + ` journal entries of each kiosk unit, system telemetry,
+installed extensions with their manifests, the current page, package
+versions and, with --screenshot, a screenshot.
+
+manifest.json in the archive lists what was collected and what could not
+be. The archive is written to file, or to kiosk-support-<host>-<date>-<time>.tar.gz
+in the current directory. Use "-" to write it to stdout.`,
+	Example: `  kiosk support-bundle
+  kiosk support-bundle --screenshot lobby.tar.gz
+  kiosk --target store-12 support-bundle`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, name, err := collectSupportBundle(cmd)
+		if err != nil {
+			return err
+		}
+		manifest, err := bundle.ReadManifest(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+
+		file := name
+		if len(args) == 1 {
+			file = args[0]
+		}
+		if file == "-" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(file, data, 0600); err != nil {
+			return err
+		}
+
+		failed := manifest.Failed()
+		result := struct {
+			File   string        `json:"file"`
+			Bytes  int           `json:"bytes"`
+			Failed []bundle.Item `json:"failed"`
+		}{file, len(data), failed}
+		if result.Failed == nil {
+			result.Failed = []bundle.Item{}
+		}
+		return printResult(result, func() {
+			fmt.Printf("Saved %s (%d KiB)\n", file, (len(data)+1023)/1024)
+			if len(failed) > 0 {
+				fmt.Println("Not collected:")
+				for _, it := range failed {
+					fmt.Printf("  %s: %s\n", it.Name, it.Error)
+				}
+			}
+		})
+	},
+}
+
+// collectSupportBundle returns the support bundle of the selected target
+// and its default file name.
+func collectSupportBundle(cmd *cobra.Command) ([]byte, string, error) {
+	t, err := selectedTarget()
+	if err != nil {
+		return nil, "", err
+	}
+	if t != nil {
+		rc := client.New(t.BaseURL(), t.Token, client.Options{
+			Insecure:    t.Insecure,
+			Fingerprint: t.Fingerprint,
+			Timeout:     supportBundleTimeout,
+		})
+		data, name, err := rc.SupportBundle(cmd.Context(), supportBundleScreenshot)
+		if err == nil && name == "" {
+			name = "kiosk-support-" + t.Name + "-" + time.Now().Format("20060102-150405") + ".tar.gz"
+		}
+		return data, name, err
+	}
+
+	b := bundle.Collect(cmd.Context(), bundle.Options{
+		ConfigPath:    config.DefaultPath,
+		ExtensionsDir: getExtensionsDir(),
+		Kiosk:         dbus.Default(),
+		Services:      service.Default(),
+		Screenshot:    supportBundleScreenshot,
+	})
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), b.Name + ".tar.gz", nil
+}
+
+func init() {
+	supportBundleCmd.Flags().BoolVar(&supportBundleScreenshot, "screenshot", false, "Include a screenshot of the kiosk screen")
+	rootCmd.AddCommand(supportBundleCmd)
+}
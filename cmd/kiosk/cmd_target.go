@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/client"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/targets"
+
+	"github.com/spf13/cobra"
+)
+
+// localOnlyAnnotation marks commands (and their subcommands) that need
+// direct access to the machine and cannot run against a remote target.
+const localOnlyAnnotation = "kiosk/local-only"
+
+var targetFlag string
+
+var (
+	targetHost        string
+	targetPort        int
+	targetToken       string
+	targetTLS         bool
+	targetFingerprint string
+	targetInsecure    bool
+)
+
+func loadTargets() (*targets.Store, error) {
+	path, err := targets.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return targets.Load(path)
+}
+
+// selectedTarget returns the target chosen with --target or "kiosk target use",
+// or nil when commands should run on the local machine.
+func selectedTarget() (*targets.Target, error) {
+	if targetFlag == targets.Local {
+		return nil, nil
+	}
+	store, err := loadTargets()
+	if err != nil {
+		return nil, err
+	}
+	return store.Resolve(targetFlag)
+}
+
+// remoteClient returns an API client for the selected target, or nil when
+// running locally. Remote actions are audited by the target's API server.
+func remoteClient() (*client.Client, error) {
+	t, err := selectedTarget()
+	if err != nil || t == nil {
+		return nil, err
+	}
+	return clientFor(t), nil
+}
+
+func clientFor(t *targets.Target) *client.Client {
+	return client.New(t.BaseURL(), t.Token, client.Options{
+		Insecure:    t.Insecure,
+		Fingerprint: t.Fingerprint,
+	})
+}
+
+// requireLocal fails if a remote target is selected.
+func requireLocal(cmd *cobra.Command) error {
+	t, err := selectedTarget()
+	if err != nil {
+		return err
+	}
+	if t != nil {
+		return fmt.Errorf("%s only works on the local machine (target %q is selected; use --target local)", cmd.CommandPath(), t.Name)
+	}
+	return nil
+}
+
+func isLocalOnly(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if _, ok := c.Annotations[localOnlyAnnotation]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+var targetCmd = &cobra.Command{
+	Use:   "target",
+	Short: "Manage remote kiosks controlled over the REST API",
+	Long: `Manage remote kiosks controlled over the REST API.
+
+Once a target is selected with "kiosk target use" (or --target on any command),
+status, url, open, reload, config, extension, playlist, schedule list/next,
+clear-*, restart and volume act on that kiosk instead of the local machine.
+Targets are stored in ~/.config/wpe-webkit-kiosk/targets.json.`,
+}
+
+var targetAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add or update a remote target",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if targetHost == "" {
+			return fmt.Errorf("--host is required")
+		}
+		token := targetToken
+		if token == "" {
+			fmt.Fprint(humanOut(), "API token: ")
+			line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			token = strings.TrimSpace(line)
+		}
+		if token == "" {
+			return fmt.Errorf("an API token is required")
+		}
+
+		store, err := loadTargets()
+		if err != nil {
+			return err
+		}
+		replaced, err := store.Set(targets.Target{
+			Name:        args[0],
+			Host:        targetHost,
+			Port:        targetPort,
+			Token:       token,
+			TLS:         targetTLS || targetFingerprint != "" || targetInsecure,
+			Fingerprint: targetFingerprint,
+			Insecure:    targetInsecure,
+		})
+		if err != nil {
+			return err
+		}
+		if err := store.Save(); err != nil {
+			return err
+		}
+
+		t, _ := store.Get(args[0])
+		return printResult(map[string]string{"name": t.Name, "url": t.BaseURL()}, func() {
+			if replaced {
+				fmt.Printf("Target %q updated (%s)\n", t.Name, t.BaseURL())
+			} else {
+				fmt.Printf("Target %q added (%s)\n", t.Name, t.BaseURL())
+			}
+			fmt.Printf("Select it with: kiosk target use %s\n", t.Name)
+		})
+	},
+}
+
+var targetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List remote targets",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadTargets()
+		if err != nil {
+			return err
+		}
+		type entry struct {
+			Name    string `json:"name"`
+			URL     string `json:"url"`
+			TLS     string `json:"tls"` // none, verified, pinned or insecure
+			Current bool   `json:"current"`
+		}
+		list := make([]entry, len(store.Targets))
+		for i, t := range store.Targets {
+			tls := "none"
+			switch {
+			case t.Fingerprint != "":
+				tls = "pinned"
+			case t.Insecure:
+				tls = "insecure"
+			case t.TLS:
+				tls = "verified"
+			}
+			list[i] = entry{Name: t.Name, URL: t.BaseURL(), TLS: tls, Current: t.Name == store.Current}
+		}
+
+		return printResult(list, func() {
+			if len(list) == 0 {
+				fmt.Println("No targets. Add one with: kiosk target add <name> --host <host>")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tURL\tTLS")
+			for _, e := range list {
+				name, tls := e.Name, e.TLS
+				if e.Current {
+					name += " *"
+				}
+				if tls == "none" {
+					tls = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", name, e.URL, tls)
+			}
+			w.Flush()
+			if store.Current == "" {
+				fmt.Println("\nCommands run on the local machine.")
+			} else {
+				fmt.Println("\n* current target")
+			}
+		})
+	},
+}
+
+var targetUseCmd = &cobra.Command{
+	Use:   "use <name|local>",
+	Short: "Select the target commands run against",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadTargets()
+		if err != nil {
+			return err
+		}
+		if err := store.Use(args[0]); err != nil {
+			return err
+		}
+		if err := store.Save(); err != nil {
+			return err
+		}
+		current := store.Current
+		if current == "" {
+			current = targets.Local
+		}
+		return printResult(map[string]string{"current": current}, func() {
+			if store.Current == "" {
+				fmt.Println("Commands now run on the local machine")
+			} else {
+				fmt.Printf("Commands now run on target %q\n", store.Current)
+			}
+		})
+	},
+}
+
+var targetRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a remote target",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadTargets()
+		if err != nil {
+			return err
+		}
+		if err := store.Remove(args[0]); err != nil {
+			return err
+		}
+		if err := store.Save(); err != nil {
+			return err
+		}
+		return printResult(map[string]string{"name": args[0], "status": "removed"}, func() {
+			fmt.Printf("Target %q removed\n", args[0])
+		})
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&targetFlag, "target", "", `Remote target to run the command against ("local" for this machine)`)
+
+	targetAddCmd.Flags().StringVar(&targetHost, "host", "", "Kiosk host name or IP address")
+	targetAddCmd.Flags().IntVar(&targetPort, "port", targets.DefaultPort, "Kiosk API port")
+	targetAddCmd.Flags().StringVar(&targetToken, "token", "", "API token (prompted if omitted)")
+	targetAddCmd.Flags().BoolVar(&targetTLS, "tls", false, "Connect over HTTPS")
+	targetAddCmd.Flags().StringVar(&targetFingerprint, "fingerprint", "", "Pin the server certificate by SHA-256 fingerprint (implies --tls)")
+	targetAddCmd.Flags().BoolVar(&targetInsecure, "insecure", false, "Skip TLS certificate verification (implies --tls)")
+
+	targetCmd.AddCommand(targetAddCmd)
+	targetCmd.AddCommand(targetListCmd)
+	targetCmd.AddCommand(targetUseCmd)
+	targetCmd.AddCommand(targetRemoveCmd)
+	rootCmd.AddCommand(targetCmd)
+}
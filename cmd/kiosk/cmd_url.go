@@ -12,17 +12,33 @@ var urlCmd = &cobra.Command{
 	Use:   "url",
 	Short: "Print current URL",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := dbus.NewClient()
+		url, err := currentURL(cmd)
 		if err != nil {
 			return err
 		}
-		url, err := client.GetUrl()
+		return printResult(map[string]string{"url": url}, func() {
+			fmt.Println(url)
+		})
+	},
+}
+
+func currentURL(cmd *cobra.Command) (string, error) {
+	rc, err := remoteClient()
+	if err != nil {
+		return "", err
+	}
+	if rc != nil {
+		st, err := rc.Status(cmd.Context())
 		if err != nil {
-			return err
+			return "", err
 		}
-		fmt.Println(url)
-		return nil
-	},
+		if st.URL == nil {
+			return "", &serviceDownError{fmt.Errorf("kiosk service is not reachable on the target")}
+		}
+		return *st.URL, nil
+	}
+
+	return dbus.Default().GetUrl(cmd.Context())
 }
 
 func init() {
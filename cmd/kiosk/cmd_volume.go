@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/audio"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/client"
 
 	"github.com/spf13/cobra"
 )
@@ -15,16 +16,17 @@ var volumeCmd = &cobra.Command{
 	Use:   "volume",
 	Short: "Show or adjust audio volume",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		level, muted, err := audio.GetVolume()
+		level, muted, err := readVolume(cmd)
 		if err != nil {
 			return fmt.Errorf("cannot read volume: %w", err)
 		}
-		if muted {
-			fmt.Printf("Volume: %d%% (muted)\n", level)
-		} else {
-			fmt.Printf("Volume: %d%%\n", level)
-		}
-		return nil
+		return printResult(client.Volume{Level: level, Muted: muted}, func() {
+			if muted {
+				fmt.Printf("Volume: %d%% (muted)\n", level)
+			} else {
+				fmt.Printf("Volume: %d%%\n", level)
+			}
+		})
 	},
 }
 
@@ -37,11 +39,10 @@ var volumeSetCmd = &cobra.Command{
 		if err != nil || level < 0 || level > 100 {
 			return fmt.Errorf("volume must be a number between 0 and 100")
 		}
-		if err := audio.SetVolume(level); err != nil {
+		if err := writeVolume(cmd, nil, level); err != nil {
 			return fmt.Errorf("cannot set volume: %w", err)
 		}
-		fmt.Printf("Volume set to %d%%\n", level)
-		return nil
+		return printVolumeChange(cmd, "Volume set to %d%%\n", level)
 	},
 }
 
@@ -49,7 +50,7 @@ var volumeUpCmd = &cobra.Command{
 	Use:   "up",
 	Short: "Increase volume by 5%",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		level, _, err := audio.GetVolume()
+		level, _, err := readVolume(cmd)
 		if err != nil {
 			return fmt.Errorf("cannot read volume: %w", err)
 		}
@@ -57,11 +58,10 @@ var volumeUpCmd = &cobra.Command{
 		if newLevel > 100 {
 			newLevel = 100
 		}
-		if err := audio.SetVolume(newLevel); err != nil {
+		if err := writeVolume(cmd, level, newLevel); err != nil {
 			return fmt.Errorf("cannot set volume: %w", err)
 		}
-		fmt.Printf("Volume: %d%%\n", newLevel)
-		return nil
+		return printVolumeChange(cmd, "Volume: %d%%\n", newLevel)
 	},
 }
 
@@ -69,7 +69,7 @@ var volumeDownCmd = &cobra.Command{
 	Use:   "down",
 	Short: "Decrease volume by 5%",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		level, _, err := audio.GetVolume()
+		level, _, err := readVolume(cmd)
 		if err != nil {
 			return fmt.Errorf("cannot read volume: %w", err)
 		}
@@ -77,11 +77,10 @@ var volumeDownCmd = &cobra.Command{
 		if newLevel < 0 {
 			newLevel = 0
 		}
-		if err := audio.SetVolume(newLevel); err != nil {
+		if err := writeVolume(cmd, level, newLevel); err != nil {
 			return fmt.Errorf("cannot set volume: %w", err)
 		}
-		fmt.Printf("Volume: %d%%\n", newLevel)
-		return nil
+		return printVolumeChange(cmd, "Volume: %d%%\n", newLevel)
 	},
 }
 
@@ -89,11 +88,10 @@ var volumeMuteCmd = &cobra.Command{
 	Use:   "mute",
 	Short: "Mute audio",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if err := audio.Mute(); err != nil {
+		if err := writeMute(cmd, true); err != nil {
 			return fmt.Errorf("cannot mute: %w", err)
 		}
-		fmt.Println("Audio muted")
-		return nil
+		return printVolumeChange(cmd, "Audio muted\n")
 	},
 }
 
@@ -101,14 +99,76 @@ var volumeUnmuteCmd = &cobra.Command{
 	Use:   "unmute",
 	Short: "Unmute audio",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if err := audio.Unmute(); err != nil {
+		if err := writeMute(cmd, false); err != nil {
 			return fmt.Errorf("cannot unmute: %w", err)
 		}
-		fmt.Println("Audio unmuted")
-		return nil
+		return printVolumeChange(cmd, "Audio unmuted\n")
 	},
 }
 
+// printVolumeChange prints the table message, or the volume state after
+// the change in the shape of GET /volume.
+func printVolumeChange(cmd *cobra.Command, format string, a ...any) error {
+	if !structuredOutput() {
+		fmt.Printf(format, a...)
+		return nil
+	}
+	level, muted, err := readVolume(cmd)
+	if err != nil {
+		return fmt.Errorf("cannot read volume: %w", err)
+	}
+	return printResult(client.Volume{Level: level, Muted: muted}, nil)
+}
+
+// readVolume returns the volume of the selected target or the local machine.
+func readVolume(cmd *cobra.Command) (int, bool, error) {
+	rc, err := remoteClient()
+	if err != nil {
+		return 0, false, err
+	}
+	if rc != nil {
+		v, err := rc.Volume(cmd.Context())
+		if err != nil {
+			return 0, false, err
+		}
+		return v.Level, v.Muted, nil
+	}
+	return audio.GetVolume()
+}
+
+// writeVolume sets the volume level; oldLevel is only used for the audit log.
+func writeVolume(cmd *cobra.Command, oldLevel any, level int) error {
+	rc, err := remoteClient()
+	if err != nil {
+		return err
+	}
+	if rc != nil {
+		_, err := rc.SetVolume(cmd.Context(), &level, nil)
+		return err
+	}
+	err = audio.SetVolume(level)
+	recordAudit("volume.set", "", oldLevel, level, err)
+	return err
+}
+
+func writeMute(cmd *cobra.Command, muted bool) error {
+	rc, err := remoteClient()
+	if err != nil {
+		return err
+	}
+	if rc != nil {
+		_, err := rc.SetVolume(cmd.Context(), nil, &muted)
+		return err
+	}
+	action, set := "volume.unmute", audio.Unmute
+	if muted {
+		action, set = "volume.mute", audio.Mute
+	}
+	err = set()
+	recordAudit(action, "", nil, nil, err)
+	return err
+}
+
 func init() {
 	volumeCmd.AddCommand(volumeSetCmd, volumeUpCmd, volumeDownCmd, volumeMuteCmd, volumeUnmuteCmd)
 	rootCmd.AddCommand(volumeCmd)
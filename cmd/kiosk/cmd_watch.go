@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+
+	"github.com/spf13/cobra"
+)
+
+var watchSignals = []string{
+	dbus.SignalLoadStarted,
+	dbus.SignalLoadFinished,
+	dbus.SignalLoadFailed,
+	dbus.SignalWebProcessTerminated,
+	dbus.SignalUrlChanged,
+	dbus.SignalTitleChanged,
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [signal...]",
+	Short: "Print page events as the kiosk emits them",
+	Long: `Print page lifecycle events as the kiosk emits them, until interrupted.
+
+Signals: ` + strings.Join(watchSignals, ", ") + `.
+Without arguments all of them are printed. Events keep coming across
+kiosk restarts.
+
+With --output json, events are printed as JSON objects, one per line.`,
+	Example: `  kiosk watch
+  kiosk watch LoadFailed WebProcessTerminated
+  kiosk -o json watch | jq .url`,
+	Annotations: map[string]string{localOnlyAnnotation: ""},
+	ValidArgs:   watchSignals,
+	Args:        cobra.OnlyValidArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if outputFormat == outputYAML {
+			return fmt.Errorf("kiosk watch does not support --output yaml (use json)")
+		}
+		var filter map[string]bool
+		if len(args) > 0 {
+			filter = make(map[string]bool)
+			for _, a := range args {
+				filter[a] = true
+			}
+		}
+
+		events, err := dbus.Default().Subscribe(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		for ev := range events {
+			if filter != nil && !filter[ev.Signal] {
+				continue
+			}
+			if outputFormat == outputJSON {
+				if err := enc.Encode(ev); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Printf("%s  %s\n", ev.Time.Format("15:04:05"), ev)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+}
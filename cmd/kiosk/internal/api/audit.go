@@ -0,0 +1,84 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/audit"
+)
+
+const defaultAuditLimit = 100
+
+// auditLog receives every mutating API action.
+var auditLog = audit.New(audit.DefaultPath)
+
+// recordAudit logs an API action performed by the request's token.
+// Failures to write the audit log are logged but never fail the request.
+func recordAudit(r *http.Request, action, target string, oldValue, newValue any, actionErr error) {
+	actor := "unknown"
+	if tok := requestToken(r); tok != nil {
+		actor = tok.Name
+	}
+
+	e := audit.Entry{
+		Actor:  actor,
+		Source: audit.SourceAPI,
+		Remote: r.RemoteAddr,
+		Action: action,
+		Target: target,
+		Old:    oldValue,
+		New:    newValue,
+	}
+	if actionErr != nil {
+		e.Outcome = audit.OutcomeFailure
+		e.Error = actionErr.Error()
+	}
+	if err := auditLog.Record(e); err != nil {
+		log.Printf("Audit: %v", err)
+	}
+}
+
+// GET /audit
+func handleAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	now := time.Now()
+	filter := audit.Filter{
+		Action: q.Get("action"),
+		Actor:  q.Get("actor"),
+		Limit:  defaultAuditLimit,
+	}
+
+	if v := q.Get("since"); v != "" {
+		t, err := audit.ParseTime(v, now)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_query", err.Error())
+			return
+		}
+		filter.Since = t
+	}
+	if v := q.Get("until"); v != "" {
+		t, err := audit.ParseTime(v, now)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_query", err.Error())
+			return
+		}
+		filter.Until = t
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid_query", "Parameter 'limit' must be a non-negative integer")
+			return
+		}
+		filter.Limit = n
+	}
+
+	entries, err := auditLog.Query(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "audit_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
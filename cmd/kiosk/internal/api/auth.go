@@ -1,23 +1,138 @@
 package api
 
 import (
+	"context"
 	"crypto/subtle"
+	"log"
 	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/tokens"
 )
 
-// authMiddleware validates the X-Api-Key header against the configured token.
-func authMiddleware(token string, next http.Handler) http.Handler {
-	tokenBytes := []byte(token)
+// legacyTokenName identifies requests authenticated with the API_TOKEN config key.
+const legacyTokenName = "api_token"
+
+type ctxKey int
+
+const tokenCtxKey ctxKey = iota
+
+// authenticator resolves X-Api-Key values to tokens. It accepts the legacy
+// API_TOKEN (with admin scope) and any named token from the token store,
+// reloading the store whenever the file changes on disk.
+type authenticator struct {
+	legacy       []byte
+	storePath    string
+	metricsToken []byte // bearer token for /metrics, empty for public access
+
+	mu      sync.Mutex
+	store   *tokens.Store
+	modTime time.Time
+}
+
+func newAuthenticator(legacyToken, storePath string) *authenticator {
+	return &authenticator{legacy: []byte(legacyToken), storePath: storePath}
+}
+
+func (a *authenticator) tokenStore() *tokens.Store {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.storePath == "" {
+		return nil
+	}
+	info, err := os.Stat(a.storePath)
+	if err != nil {
+		a.store, a.modTime = nil, time.Time{}
+		return nil
+	}
+	if a.store == nil || !info.ModTime().Equal(a.modTime) {
+		store, err := tokens.Load(a.storePath)
+		if err != nil {
+			log.Printf("Token store: %v", err)
+			return a.store
+		}
+		a.store, a.modTime = store, info.ModTime()
+	}
+	return a.store
+}
+
+// lookup returns the token for key, or a message explaining why it was rejected.
+func (a *authenticator) lookup(key string) (*tokens.Token, string) {
+	if len(a.legacy) > 0 && subtle.ConstantTimeCompare([]byte(key), a.legacy) == 1 {
+		return &tokens.Token{Name: legacyTokenName, Scopes: []string{tokens.ScopeAdmin}}, ""
+	}
+	if store := a.tokenStore(); store != nil {
+		if tok, ok := store.Lookup(key); ok {
+			if tok.Expired(time.Now()) {
+				return nil, "API key expired"
+			}
+			return tok, ""
+		}
+	}
+	return nil, "Invalid API key"
+}
+
+// lookupClientCert maps a verified TLS client certificate to the named token
+// whose name equals the certificate's common name.
+func (a *authenticator) lookupClientCert(r *http.Request) *tokens.Token {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	cn := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	store := a.tokenStore()
+	if cn == "" || store == nil {
+		return nil
+	}
+	tok, ok := store.Get(cn)
+	if !ok || tok.Expired(time.Now()) {
+		return nil
+	}
+	return tok
+}
+
+// authMiddleware authenticates the request by verified client certificate or
+// X-Api-Key header and attaches the matching token to the request context
+// for per-route scope checks.
+func authMiddleware(auth *authenticator, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tok := auth.lookupClientCert(r); tok != nil {
+			ctx := context.WithValue(r.Context(), tokenCtxKey, tok)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
 		key := r.Header.Get("X-Api-Key")
 		if key == "" {
 			writeError(w, http.StatusUnauthorized, "unauthorized", "Missing X-Api-Key header")
 			return
 		}
-		if subtle.ConstantTimeCompare([]byte(key), tokenBytes) != 1 {
-			writeError(w, http.StatusUnauthorized, "unauthorized", "Invalid API key")
+		tok, reason := auth.lookup(key)
+		if tok == nil {
+			writeError(w, http.StatusUnauthorized, "unauthorized", reason)
+			return
+		}
+		ctx := context.WithValue(r.Context(), tokenCtxKey, tok)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requireScope rejects requests whose token does not grant scope.
+func requireScope(scope string, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tok := requestToken(r)
+		if tok == nil || !tok.HasScope(scope) {
+			writeError(w, http.StatusForbidden, "forbidden", "API key lacks required scope: "+scope)
 			return
 		}
-		next.ServeHTTP(w, r)
+		next(w, r)
 	})
 }
+
+// requestToken returns the authenticated token for r, if any.
+func requestToken(r *http.Request) *tokens.Token {
+	tok, _ := r.Context().Value(tokenCtxKey).(*tokens.Token)
+	return tok
+}
@@ -1,14 +1,22 @@
 package api
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/tokens"
 )
 
 func TestAuthMiddleware_ValidKey(t *testing.T) {
 	token := "test-secret-token"
-	handler := authMiddleware(token, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := authMiddleware(newAuthenticator(token, ""), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -24,7 +32,7 @@ func TestAuthMiddleware_ValidKey(t *testing.T) {
 }
 
 func TestAuthMiddleware_MissingKey(t *testing.T) {
-	handler := authMiddleware("secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := authMiddleware(newAuthenticator("secret", ""), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Error("handler should not be called")
 	}))
 
@@ -39,7 +47,7 @@ func TestAuthMiddleware_MissingKey(t *testing.T) {
 }
 
 func TestAuthMiddleware_InvalidKey(t *testing.T) {
-	handler := authMiddleware("correct-token", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := authMiddleware(newAuthenticator("correct-token", ""), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Error("handler should not be called")
 	}))
 
@@ -56,7 +64,7 @@ func TestAuthMiddleware_InvalidKey(t *testing.T) {
 
 func TestAuthMiddleware_ConstantTimeComparison(t *testing.T) {
 	// Verify that different-length tokens still return 401 (not panic)
-	handler := authMiddleware("short", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := authMiddleware(newAuthenticator("short", ""), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Error("handler should not be called")
 	}))
 
@@ -70,3 +78,126 @@ func TestAuthMiddleware_ConstantTimeComparison(t *testing.T) {
 		t.Errorf("expected 401, got %d", rec.Code)
 	}
 }
+
+func writeTokenStore(t *testing.T, name string, scopes []string, expires *time.Time) (path, secret string) {
+	t.Helper()
+	path = filepath.Join(t.TempDir(), "tokens.json")
+	store, err := tokens.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err = store.Create(name, scopes, expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return path, secret
+}
+
+func TestAuthMiddleware_NamedToken(t *testing.T) {
+	path, secret := writeTokenStore(t, "support", []string{tokens.ScopeRead}, nil)
+
+	var got *tokens.Token
+	handler := authMiddleware(newAuthenticator("", path), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = requestToken(r)
+	}))
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Api-Key", secret)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	if got == nil || got.Name != "support" {
+		t.Errorf("expected token 'support' in context, got %+v", got)
+	}
+}
+
+func TestAuthMiddleware_ExpiredToken(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	path, secret := writeTokenStore(t, "old", []string{tokens.ScopeRead}, &past)
+
+	handler := authMiddleware(newAuthenticator("", path), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not be called")
+	}))
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Api-Key", secret)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestRoutes_MissingScopeReturns403(t *testing.T) {
+	path, secret := writeTokenStore(t, "support", []string{tokens.ScopeRead}, nil)
+	mux := http.NewServeMux()
+	registerRoutes(mux, newAuthenticator("", path))
+
+	rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/restart", secret, "")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", rec.Code)
+	}
+	rec = doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/support-bundle", secret, `{"screenshot": true}`)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a support bundle, got %d", rec.Code)
+	}
+
+	var env envelope
+	json.Unmarshal(rec.Body.Bytes(), &env)
+	if env.Error == nil || env.Error.Code != "forbidden" {
+		t.Errorf("expected error code 'forbidden', got %+v", env.Error)
+	}
+}
+
+func TestAuthMiddleware_ClientCertMapsToToken(t *testing.T) {
+	path, _ := writeTokenStore(t, "store-12", []string{tokens.ScopeNavigate}, nil)
+
+	var got *tokens.Token
+	handler := authMiddleware(newAuthenticator("", path), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = requestToken(r)
+	}))
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "store-12"}}}},
+	}
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	if got == nil || got.Name != "store-12" {
+		t.Errorf("expected token 'store-12' in context, got %+v", got)
+	}
+}
+
+func TestAuthMiddleware_UnmappedClientCertNeedsKey(t *testing.T) {
+	path, _ := writeTokenStore(t, "store-12", []string{tokens.ScopeNavigate}, nil)
+
+	handler := authMiddleware(newAuthenticator("", path), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not be called")
+	}))
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "unknown"}}}},
+	}
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
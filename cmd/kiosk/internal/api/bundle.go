@@ -0,0 +1,34 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/bundle"
+)
+
+// POST /support-bundle
+func handleSupportBundle(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Screenshot bool `json:"screenshot"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid_body", "Invalid JSON body")
+		return
+	}
+
+	b := bundle.Collect(r.Context(), bundle.Options{
+		ConfigPath:    configPath,
+		ExtensionsDir: getExtensionsDir(),
+		Kiosk:         kiosk,
+		Services:      services,
+		Screenshot:    body.Screenshot,
+	})
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+b.Name+`.tar.gz"`)
+	w.Header().Set("Cache-Control", "no-store")
+	b.Write(w)
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service/servicetest"
+)
+
+func TestSupportBundle(t *testing.T) {
+	useTempConfig(t, "URL=\"https://example.com\"\nAPI_TOKEN=\"secret\"\n")
+	mux := setupTestServer("secret")
+	useKiosk(t, &stubKiosk{page: dbus.PageInfo{URI: "https://example.com/"}})
+	useServices(t, servicetest.NewManager(kioskService))
+
+	rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/support-bundle", "secret", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/gzip" {
+		t.Fatalf("expected a gzip archive, got %d %s: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, `filename="kiosk-support-`) {
+		t.Errorf("unexpected Content-Disposition: %s", cd)
+	}
+
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		files[hdr.Name[strings.Index(hdr.Name, "/")+1:]] = string(data)
+	}
+	for _, name := range []string{"manifest.json", "config", "services/wpe-webkit-kiosk.json", "system.json", "page.json"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("expected %s in the bundle", name)
+		}
+	}
+	if strings.Contains(files["config"], `"secret"`) {
+		t.Errorf("expected API_TOKEN redacted, got:\n%s", files["config"])
+	}
+	if _, ok := files["screenshot.png"]; ok {
+		t.Error("expected no screenshot unless requested")
+	}
+}
+
+func TestSupportBundle_InvalidBody(t *testing.T) {
+	mux := setupTestServer("secret")
+	rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/support-bundle", "secret", `{"screenshot":`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/doctor"
+)
+
+// GET /diagnostics
+func handleDiagnostics(w http.ResponseWriter, r *http.Request) {
+	var checks []doctor.Check
+	if q := r.URL.Query().Get("check"); q != "" {
+		for _, id := range strings.Split(q, ",") {
+			c, ok := doctor.Lookup(strings.TrimSpace(id))
+			if !ok {
+				writeError(w, http.StatusBadRequest, "invalid_query",
+					fmt.Sprintf("Unknown check %q; available: %s", id, strings.Join(doctor.IDs(), ", ")))
+				return
+			}
+			checks = append(checks, c)
+		}
+	}
+
+	env := doctor.DefaultEnv()
+	env.ConfigPath = configPath
+	env.Services = services
+	writeJSON(w, http.StatusOK, doctor.Run(r.Context(), env, checks, false))
+}
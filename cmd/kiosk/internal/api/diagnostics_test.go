@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/doctor"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service/servicetest"
+)
+
+func TestDiagnostics(t *testing.T) {
+	useTempConfig(t, "API_TOKEN=\"secret\"\nAPI_PORT=\"8090\"\n")
+	mux := setupTestServer("secret")
+	useServices(t, servicetest.NewManager(kioskService))
+
+	rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/diagnostics?check=config,ports", "secret", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Data doctor.Report `json:"data"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Data.Checks) != 2 || resp.Data.Checks[0].Check != "config" {
+		t.Fatalf("expected the config and ports checks, got %+v", resp.Data.Checks)
+	}
+	if ports := resp.Data.Checks[1]; ports.Status != doctor.Fail || ports.Hint == "" {
+		t.Errorf("expected API_PORT to conflict with INSPECTOR_HTTP_PORT, got %+v", ports)
+	}
+	if resp.Data.Status != doctor.Fail {
+		t.Errorf("expected the report to fail, got %s", resp.Data.Status)
+	}
+}
+
+func TestDiagnostics_UnknownCheck(t *testing.T) {
+	mux := setupTestServer("secret")
+	rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/diagnostics?check=nope", "secret", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
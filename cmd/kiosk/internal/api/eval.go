@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+)
+
+const (
+	defaultEvalTimeout = 5  // seconds
+	maxEvalTimeout     = 60 // seconds
+)
+
+// POST /eval
+func handleEval(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Script  string `json:"script"`
+		Timeout int    `json:"timeout"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_body", "Invalid JSON body")
+		return
+	}
+	if body.Script == "" {
+		writeError(w, http.StatusBadRequest, "invalid_body", "Field 'script' is required")
+		return
+	}
+	if body.Timeout == 0 {
+		body.Timeout = defaultEvalTimeout
+	}
+	if body.Timeout < 1 || body.Timeout > maxEvalTimeout {
+		writeError(w, http.StatusBadRequest, "invalid_body", fmt.Sprintf("Field 'timeout' must be between 1 and %d seconds", maxEvalTimeout))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(body.Timeout)*time.Second)
+	defer cancel()
+	result, err := kiosk.Evaluate(ctx, body.Script)
+	recordAudit(r, "eval", "", nil, body.Script, err)
+
+	switch {
+	case errors.Is(err, dbus.ErrTimeout):
+		writeError(w, http.StatusGatewayTimeout, "timeout", fmt.Sprintf("Script did not finish within %d seconds", body.Timeout))
+	case err != nil:
+		writeKioskError(w, err)
+	default:
+		writeJSON(w, http.StatusOK, map[string]json.RawMessage{"result": result})
+	}
+}
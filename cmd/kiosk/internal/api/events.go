@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/audio"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
+)
+
+// Event types published on the /events stream.
+const (
+	eventNavigation = "navigation"
+	eventReload     = "reload"
+	eventConfig     = "config"
+	eventService    = "service"
+	eventExtension  = "extension"
+	eventVolume     = "volume"
+	eventClear      = "clear"
+	eventPlaylist   = "playlist"
+	eventBlocked    = "blocked"
+	eventLoad       = "load"
+	eventTitle      = "title"
+	eventCrash      = "crash"
+)
+
+const (
+	eventBufferSize   = 32
+	keepaliveInterval = 15 * time.Second
+	watchInterval     = 2 * time.Second
+)
+
+// event is a single kiosk state change delivered to /events subscribers.
+type event struct {
+	ID   uint64    `json:"id"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data"`
+}
+
+// broker fans out published events to all active subscribers.
+// Slow subscribers drop events instead of blocking publishers.
+type broker struct {
+	mu     sync.Mutex
+	nextID uint64
+	subs   map[chan event]struct{}
+}
+
+func newBroker() *broker {
+	return &broker{subs: make(map[chan event]struct{})}
+}
+
+// events is the process-wide broker shared by all handlers.
+var events = newBroker()
+
+func (b *broker) subscribe() chan event {
+	ch := make(chan event, eventBufferSize)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *broker) unsubscribe(ch chan event) {
+	b.mu.Lock()
+	if _, ok := b.subs[ch]; ok {
+		delete(b.subs, ch)
+		close(ch)
+	}
+	b.mu.Unlock()
+}
+
+// closeAll disconnects every subscriber, e.g. on server shutdown.
+func (b *broker) closeAll() {
+	b.mu.Lock()
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+	b.mu.Unlock()
+}
+
+func (b *broker) subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+func (b *broker) publish(eventType string, data any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	ev := event{ID: b.nextID, Type: eventType, Time: time.Now().UTC(), Data: data}
+	for ch := range b.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// GET /events
+func handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "stream_unsupported", "Streaming is not supported")
+		return
+	}
+
+	var filter map[string]bool
+	if types := r.URL.Query().Get("types"); types != "" {
+		filter = make(map[string]bool)
+		for _, t := range strings.Split(types, ",") {
+			filter[strings.TrimSpace(t)] = true
+		}
+	}
+
+	ch := events.subscribe()
+	defer events.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			if filter != nil && !filter[ev.Type] {
+				continue
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// watchState polls service, volume and blocked navigation state and publishes transitions.
+// Polling only happens while someone is subscribed, so an idle API does not
+// query systemd and shell out to amixer every few seconds.
+func watchState(ctx context.Context) {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	var lastState string
+	lastVolume, lastMuted := -1, false
+	lastBlocked := int64(-1)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if events.subscribers() == 0 {
+			lastState, lastVolume, lastBlocked = "", -1, -1
+			continue
+		}
+
+		state := service.State(ctx, services, kioskService)
+		if lastState != "" && state != lastState {
+			events.publish(eventService, map[string]string{
+				"service": kioskService,
+				"from":    lastState,
+				"to":      state,
+			})
+		}
+		lastState = state
+
+		if level, muted, err := audio.GetVolume(); err == nil {
+			if lastVolume >= 0 && (level != lastVolume || muted != lastMuted) {
+				events.publish(eventVolume, map[string]any{
+					"level": level,
+					"muted": muted,
+				})
+			}
+			lastVolume, lastMuted = level, muted
+		}
+
+		lastBlocked = publishKioskBlocked(ctx, lastBlocked)
+	}
+}
+
+// watchKiosk relays the kiosk's D-Bus signals to /events. Subscribe
+// renews the subscription itself; this only retries the first one.
+func watchKiosk(ctx context.Context) {
+	for {
+		if sub, err := kiosk.Subscribe(ctx); err == nil {
+			for ev := range sub {
+				publishKioskEvent(ev)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchInterval):
+		}
+	}
+}
+
+// publishKioskEvent publishes a kiosk signal as the matching event type.
+func publishKioskEvent(ev dbus.Event) {
+	switch ev.Signal {
+	case dbus.SignalUrlChanged:
+		events.publish(eventNavigation, map[string]string{"url": ev.URL, "source": "kiosk"})
+	case dbus.SignalLoadStarted:
+		events.publish(eventLoad, map[string]string{"state": "started", "url": ev.URL})
+	case dbus.SignalLoadFinished:
+		events.publish(eventLoad, map[string]string{"state": "finished", "url": ev.URL})
+	case dbus.SignalLoadFailed:
+		events.publish(eventLoad, map[string]string{"state": "failed", "url": ev.URL, "error": ev.Error})
+	case dbus.SignalTitleChanged:
+		events.publish(eventTitle, map[string]string{"title": ev.Title})
+	case dbus.SignalWebProcessTerminated:
+		events.publish(eventCrash, map[string]string{"reason": ev.Reason})
+	}
+}
+
+// publishKioskBlocked publishes navigations blocked by the kiosk since the
+// last poll, when last (the previous total) is known, and returns the new
+// total.
+func publishKioskBlocked(ctx context.Context, last int64) int64 {
+	total, recent, err := kiosk.GetBlocked(ctx)
+	if err != nil {
+		return -1 // kiosk restarted or not running; counter starts over
+	}
+	if last >= 0 && int64(total) > last {
+		n := int(int64(total) - last)
+		if n < len(recent) {
+			recent = recent[len(recent)-n:]
+		}
+		for _, b := range recent {
+			publishBlocked(b.URL, b.Reason, "kiosk")
+		}
+	}
+	return int64(total)
+}
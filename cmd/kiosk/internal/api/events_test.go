@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+)
+
+func TestBroker_PublishFansOut(t *testing.T) {
+	b := newBroker()
+	a := b.subscribe()
+	c := b.subscribe()
+
+	b.publish(eventReload, nil)
+
+	for _, ch := range []chan event{a, c} {
+		select {
+		case ev := <-ch:
+			if ev.Type != eventReload || ev.ID != 1 {
+				t.Errorf("unexpected event: %+v", ev)
+			}
+		default:
+			t.Error("expected event to be delivered")
+		}
+	}
+}
+
+func TestBroker_SlowSubscriberDoesNotBlock(t *testing.T) {
+	b := newBroker()
+	ch := b.subscribe()
+
+	for i := 0; i < eventBufferSize+10; i++ {
+		b.publish(eventReload, nil)
+	}
+
+	if len(ch) != eventBufferSize {
+		t.Errorf("expected %d buffered events, got %d", eventBufferSize, len(ch))
+	}
+}
+
+func TestBroker_UnsubscribeClosesChannel(t *testing.T) {
+	b := newBroker()
+	ch := b.subscribe()
+	b.unsubscribe(ch)
+
+	if _, ok := <-ch; ok {
+		t.Error("expected channel to be closed")
+	}
+	if b.subscribers() != 0 {
+		t.Errorf("expected 0 subscribers, got %d", b.subscribers())
+	}
+}
+
+func TestEvents_StreamsFilteredEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/events?types=navigation", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		handleEvents(rec, req)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for events.subscribers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("handler did not subscribe")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	events.publish(eventReload, nil)
+	events.publish(eventNavigation, map[string]string{"url": "https://example.com"})
+
+	// Give the handler a moment to write the event before disconnecting.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %s", ct)
+	}
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "event: navigation\n") {
+		t.Errorf("expected navigation event in stream, got %q", body)
+	}
+	if !strings.Contains(body, `"url":"https://example.com"`) {
+		t.Errorf("expected event payload in stream, got %q", body)
+	}
+	if strings.Contains(body, "event: reload") {
+		t.Errorf("expected reload event to be filtered out, got %q", body)
+	}
+}
+
+func TestPublishKioskEvent_MapsSignals(t *testing.T) {
+	ch := events.subscribe()
+	defer events.unsubscribe(ch)
+
+	publishKioskEvent(dbus.Event{Signal: dbus.SignalLoadFailed, URL: "https://example.com/", Error: "timed out"})
+	publishKioskEvent(dbus.Event{Signal: dbus.SignalUrlChanged, URL: "https://example.com/menu"})
+	publishKioskEvent(dbus.Event{Signal: dbus.SignalWebProcessTerminated, Reason: "crashed"})
+
+	want := []struct {
+		typ  string
+		data map[string]string
+	}{
+		{eventLoad, map[string]string{"state": "failed", "url": "https://example.com/", "error": "timed out"}},
+		{eventNavigation, map[string]string{"url": "https://example.com/menu", "source": "kiosk"}},
+		{eventCrash, map[string]string{"reason": "crashed"}},
+	}
+	for _, w := range want {
+		ev := <-ch
+		if ev.Type != w.typ || !reflect.DeepEqual(ev.Data, w.data) {
+			t.Errorf("got %s %v, want %s %v", ev.Type, ev.Data, w.typ, w.data)
+		}
+	}
+}
@@ -1,37 +1,62 @@
 package api
 
 import (
-	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
 
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/audio"
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/sysinfo"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/tokens"
 )
 
-const kioskService = "wpe-webkit-kiosk"
+const kioskService = service.KioskUnit
+
+// configPath is the kiosk config file read and written by the handlers.
+var configPath = config.DefaultPath
+
+// configMu serializes config writes so If-Match checks are not raced.
+var configMu sync.Mutex
+
+// secretKeyHints name the local command that manages each secret config key.
+// Secret keys are never returned or changed over the API.
+var secretKeyHints = map[string]string{
+	"API_TOKEN":     "kiosk api token regenerate",
+	"METRICS_TOKEN": "kiosk config set METRICS_TOKEN <token>",
+}
 
 // GET /status
 func handleStatus(w http.ResponseWriter, r *http.Request) {
-	state := systemctlProperty("ActiveState")
-
+	state := "unknown"
 	var uptime *string
-	if state == "active" {
-		if v := systemctlProperty("ActiveEnterTimestamp"); v != "" {
+	if st, err := services.Status(r.Context(), kioskService); err == nil {
+		state = st.State
+		if v := st.ActiveSince(); v != "" {
 			uptime = &v
 		}
 	}
 
 	var url *string
-	if client, err := dbus.NewClient(); err == nil {
-		if u, err := client.GetUrl(); err == nil {
-			url = &u
+	var page *dbus.PageInfo
+	var idle *dbus.Idle
+	if u, err := kiosk.GetUrl(r.Context()); err == nil {
+		url = &u
+		if p, err := kiosk.GetPageInfo(r.Context()); err == nil {
+			page = &p
+		}
+		if i, err := kiosk.GetIdle(r.Context()); err == nil {
+			idle = &i
 		}
 	}
 
@@ -39,6 +64,8 @@ func handleStatus(w http.ResponseWriter, r *http.Request) {
 		"service": state,
 		"uptime":  uptime,
 		"url":     url,
+		"page":    page,
+		"idle":    idle,
 	})
 }
 
@@ -55,42 +82,70 @@ func handleNavigate(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid_body", "Field 'url' is required")
 		return
 	}
-
-	client, err := dbus.NewClient()
-	if err != nil {
-		writeError(w, http.StatusServiceUnavailable, "service_unavailable", "Kiosk service is not running")
+	if err := config.Validate("URL", body.URL); err != nil {
+		writeValidationError(w, err)
 		return
 	}
-	if err := client.Open(body.URL); err != nil {
-		writeError(w, http.StatusInternalServerError, "dbus_error", err.Error())
+
+	var oldURL string
+	cfg, cfgErr := config.Load(configPath)
+	if cfgErr == nil {
+		oldURL = cfg.Get("URL")
+	}
+
+	if d := checkPolicy(cfg, body.URL); !d.Allowed {
+		recordAudit(r, "navigate", "", oldURL, body.URL, d.Err())
+		writeError(w, http.StatusForbidden, "navigation_blocked", d.Err().Error())
 		return
 	}
 
-	if cfg, err := config.Load(config.DefaultPath); err == nil {
-		cfg.Set("URL", body.URL)
-		cfg.Save()
+	if err := kiosk.Open(r.Context(), body.URL); err != nil {
+		recordAudit(r, "navigate", "", oldURL, body.URL, err)
+		writeKioskError(w, err)
+		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]string{"url": body.URL})
+	saved := saveNavigatedURL(body.URL)
+
+	recordAudit(r, "navigate", "", oldURL, body.URL, nil)
+	events.publish(eventNavigation, map[string]string{"url": body.URL, "source": "api"})
+	writeJSON(w, http.StatusOK, map[string]any{"url": body.URL, "saved": saved})
 }
 
-// POST /reload
-func handleReload(w http.ResponseWriter, r *http.Request) {
-	client, err := dbus.NewClient()
+// saveNavigatedURL persists url as the kiosk's URL. It reloads the config
+// under configMu so a concurrent config change is not overwritten, and
+// reports whether the URL was saved.
+func saveNavigatedURL(url string) bool {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	cfg, err := config.Load(configPath)
+	if err == nil {
+		cfg.Set("URL", url)
+		err = cfg.Save()
+	}
 	if err != nil {
-		writeError(w, http.StatusServiceUnavailable, "service_unavailable", "Kiosk service is not running")
-		return
+		log.Printf("Navigate: cannot save URL to config: %v", err)
+		return false
 	}
-	if err := client.Reload(); err != nil {
-		writeError(w, http.StatusInternalServerError, "dbus_error", err.Error())
+	return true
+}
+
+// POST /reload
+func handleReload(w http.ResponseWriter, r *http.Request) {
+	if err := kiosk.Reload(r.Context()); err != nil {
+		recordAudit(r, "reload", "", nil, nil, err)
+		writeKioskError(w, err)
 		return
 	}
+	recordAudit(r, "reload", "", nil, nil, nil)
+	events.publish(eventReload, nil)
 	writeJSON(w, http.StatusOK, map[string]string{"status": "reloaded"})
 }
 
 // GET /config
 func handleConfigGet(w http.ResponseWriter, r *http.Request) {
-	cfg, err := config.Load(config.DefaultPath)
+	cfg, err := config.Load(configPath)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "config_error", err.Error())
 		return
@@ -98,11 +153,12 @@ func handleConfigGet(w http.ResponseWriter, r *http.Request) {
 
 	result := make(map[string]string)
 	for _, kv := range cfg.KeyValues() {
-		if kv.Key == "API_TOKEN" {
+		if isSecretKey(kv.Key) {
 			continue
 		}
 		result[kv.Key] = kv.Value
 	}
+	w.Header().Set("ETag", configETag(cfg))
 	writeJSON(w, http.StatusOK, result)
 }
 
@@ -120,35 +176,49 @@ func handleConfigSet(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid_body", "Field 'key' is required")
 		return
 	}
-	if body.Key == "API_TOKEN" {
-		writeError(w, http.StatusBadRequest, "forbidden_key", "API_TOKEN cannot be changed via this endpoint. Use kiosk api token regenerate")
+	if isSecretKey(body.Key) {
+		writeError(w, http.StatusBadRequest, "forbidden_key", fmt.Sprintf("%s cannot be changed via this endpoint. Use %s", body.Key, secretKeyHints[body.Key]))
 		return
 	}
-	if !config.ValidKeys[body.Key] {
-		writeError(w, http.StatusBadRequest, "unknown_key", fmt.Sprintf("Unknown config key: %s", body.Key))
+	if err := config.Validate(body.Key, body.Value); err != nil {
+		writeValidationError(w, err)
 		return
 	}
 
-	cfg, err := config.Load(config.DefaultPath)
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	cfg, err := config.Load(configPath)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "config_error", err.Error())
 		return
 	}
+	if !checkIfMatch(w, r, cfg) {
+		return
+	}
 
+	oldValue := cfg.Get(body.Key)
 	cfg.Set(body.Key, body.Value)
 	if err := cfg.Save(); err != nil {
+		recordAudit(r, "config.set", body.Key, config.Redact(body.Key, oldValue), config.Redact(body.Key, body.Value), err)
 		writeError(w, http.StatusInternalServerError, "config_error", err.Error())
 		return
 	}
+	recordAudit(r, "config.set", body.Key, config.Redact(body.Key, oldValue), config.Redact(body.Key, body.Value), nil)
+	w.Header().Set("ETag", configETag(cfg))
 
 	restartRequired := config.NeedsRestart(body.Key)
 
 	if body.Key == "URL" {
-		if client, err := dbus.NewClient(); err == nil {
-			client.Open(body.Value)
-		}
+		kiosk.Open(r.Context(), body.Value)
 	}
 
+	events.publish(eventConfig, map[string]any{
+		"key":              body.Key,
+		"value":            body.Value,
+		"restart_required": restartRequired,
+	})
+
 	writeJSON(w, http.StatusOK, map[string]any{
 		"key":              body.Key,
 		"value":            body.Value,
@@ -156,6 +226,117 @@ func handleConfigSet(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// PATCH /config
+func handleConfigPatch(w http.ResponseWriter, r *http.Request) {
+	var body map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_body", "Body must be a JSON object of string values")
+		return
+	}
+	if len(body) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_body", "At least one key is required")
+		return
+	}
+	restart := r.URL.Query().Get("restart") == "true"
+	if restart {
+		if tok := requestToken(r); tok == nil || !tok.HasScope(tokens.ScopeAdmin) {
+			writeError(w, http.StatusForbidden, "forbidden", "API key lacks required scope: "+tokens.ScopeAdmin)
+			return
+		}
+	}
+
+	keys := make([]string, 0, len(body))
+	for key := range body {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Validate everything before touching the file.
+	var problems []string
+	code := ""
+	for _, key := range keys {
+		var msg, c string
+		if isSecretKey(key) {
+			msg, c = fmt.Sprintf("%s cannot be changed via this endpoint. Use %s", key, secretKeyHints[key]), "forbidden_key"
+		} else if err := config.Validate(key, body[key]); err != nil {
+			var unknown *config.UnknownKeyError
+			if errors.As(err, &unknown) {
+				msg, c = fmt.Sprintf("Unknown config key: %s", key), "unknown_key"
+			} else {
+				msg, c = err.Error(), "invalid_value"
+			}
+		}
+		if msg != "" {
+			problems = append(problems, msg)
+			if code == "" {
+				code = c
+			}
+		}
+	}
+	if len(problems) > 0 {
+		writeError(w, http.StatusBadRequest, code, strings.Join(problems, "; "))
+		return
+	}
+
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "config_error", err.Error())
+		return
+	}
+	if !checkIfMatch(w, r, cfg) {
+		return
+	}
+
+	oldValues := map[string]string{}
+	restartRequired := false
+	for _, key := range keys {
+		oldValues[key] = cfg.Get(key)
+		cfg.Set(key, body[key])
+		if config.NeedsRestart(key) {
+			restartRequired = true
+		}
+	}
+	target := strings.Join(keys, ",")
+	if err := cfg.Save(); err != nil {
+		recordAudit(r, "config.patch", target, config.RedactValues(oldValues), config.RedactValues(body), err)
+		writeError(w, http.StatusInternalServerError, "config_error", err.Error())
+		return
+	}
+	recordAudit(r, "config.patch", target, config.RedactValues(oldValues), config.RedactValues(body), nil)
+
+	if url, ok := body["URL"]; ok {
+		kiosk.Open(r.Context(), url)
+	}
+	for _, key := range keys {
+		events.publish(eventConfig, map[string]any{
+			"key":              key,
+			"value":            body[key],
+			"restart_required": config.NeedsRestart(key),
+		})
+	}
+
+	restarted := false
+	if restart && restartRequired {
+		err := services.Restart(r.Context(), kioskService)
+		recordAudit(r, "restart", kioskService, nil, nil, err)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "restart_error", "Config saved but restart failed: "+err.Error())
+			return
+		}
+		restarted = true
+	}
+
+	w.Header().Set("ETag", configETag(cfg))
+	writeJSON(w, http.StatusOK, map[string]any{
+		"values":           body,
+		"restart_required": restartRequired && !restarted,
+		"restarted":        restarted,
+	})
+}
+
 // POST /clear
 func handleClear(w http.ResponseWriter, r *http.Request) {
 	var body struct {
@@ -172,15 +353,13 @@ func handleClear(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client, err := dbus.NewClient()
-	if err != nil {
-		writeError(w, http.StatusServiceUnavailable, "service_unavailable", "Kiosk service is not running")
-		return
-	}
-	if err := client.ClearData(body.Scope); err != nil {
-		writeError(w, http.StatusInternalServerError, "dbus_error", err.Error())
+	if err := kiosk.ClearData(r.Context(), body.Scope); err != nil {
+		recordAudit(r, "clear", body.Scope, nil, nil, err)
+		writeKioskError(w, err)
 		return
 	}
+	recordAudit(r, "clear", body.Scope, nil, nil, nil)
+	events.publish(eventClear, map[string]string{"scope": body.Scope})
 	writeJSON(w, http.StatusOK, map[string]string{"cleared": body.Scope})
 }
 
@@ -210,9 +389,17 @@ func handleExtensionEnable(w http.ResponseWriter, r *http.Request) {
 	}
 
 	disabledPath := filepath.Join(extDir, ".disabled")
-	os.Remove(disabledPath)
-	exec.Command("sudo", "/usr/bin/rm", disabledPath).Run()
+	if err := os.Remove(disabledPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		// Fallback to sudo rm for permission issues
+		if cmdErr := exec.Command("sudo", "/usr/bin/rm", disabledPath).Run(); cmdErr != nil {
+			recordAudit(r, "extension.enable", name, nil, nil, err)
+			writeError(w, http.StatusInternalServerError, "extensions_error", fmt.Sprintf("Cannot enable extension: %v", err))
+			return
+		}
+	}
 
+	recordAudit(r, "extension.enable", name, nil, nil, nil)
+	events.publish(eventExtension, map[string]any{"extension": name, "enabled": true})
 	writeJSON(w, http.StatusOK, map[string]string{"extension": name, "status": "enabled"})
 }
 
@@ -234,71 +421,135 @@ func handleExtensionDisable(w http.ResponseWriter, r *http.Request) {
 	disabledPath := filepath.Join(extDir, ".disabled")
 	f, err := os.Create(disabledPath)
 	if err != nil {
-		exec.Command("sudo", "/usr/bin/touch", disabledPath).Run()
+		// Fallback to sudo touch for permission issues
+		if cmdErr := exec.Command("sudo", "/usr/bin/touch", disabledPath).Run(); cmdErr != nil {
+			recordAudit(r, "extension.disable", name, nil, nil, err)
+			writeError(w, http.StatusInternalServerError, "extensions_error", fmt.Sprintf("Cannot disable extension: %v", err))
+			return
+		}
 	} else {
 		f.Close()
 	}
 
+	recordAudit(r, "extension.disable", name, nil, nil, nil)
+	events.publish(eventExtension, map[string]any{"extension": name, "enabled": false})
 	writeJSON(w, http.StatusOK, map[string]string{"extension": name, "status": "disabled"})
 }
 
 // POST /restart
 func handleRestart(w http.ResponseWriter, r *http.Request) {
-	if err := exec.Command("sudo", "systemctl", "restart", kioskService).Run(); err != nil {
+	if err := services.Restart(r.Context(), kioskService); err != nil {
+		recordAudit(r, "restart", kioskService, nil, nil, err)
 		writeError(w, http.StatusInternalServerError, "restart_error", err.Error())
 		return
 	}
+	recordAudit(r, "restart", kioskService, nil, nil, nil)
 	writeJSON(w, http.StatusOK, map[string]string{"status": "restarting"})
 }
 
-// GET /system
-func handleSystem(w http.ResponseWriter, r *http.Request) {
-	info := map[string]any{}
+// GET /volume
+func handleVolumeGet(w http.ResponseWriter, r *http.Request) {
+	level, muted, err := audio.GetVolume()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "volume_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"level": level, "muted": muted})
+}
 
-	if data, err := os.ReadFile("/proc/uptime"); err == nil {
-		parts := strings.Fields(string(data))
-		if len(parts) > 0 {
-			if secs, err := strconv.ParseFloat(parts[0], 64); err == nil {
-				info["uptime_seconds"] = secs
-			}
-		}
+// PUT /volume
+func handleVolumeSet(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Level *int  `json:"level"`
+		Muted *bool `json:"muted"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_body", "Invalid JSON body")
+		return
+	}
+	if body.Level == nil && body.Muted == nil {
+		writeError(w, http.StatusBadRequest, "invalid_body", "Field 'level' or 'muted' is required")
+		return
+	}
+	if body.Level != nil && (*body.Level < 0 || *body.Level > 100) {
+		writeError(w, http.StatusBadRequest, "invalid_level", "Volume level must be between 0 and 100")
+		return
 	}
 
-	if data, err := os.ReadFile("/proc/loadavg"); err == nil {
-		parts := strings.Fields(string(data))
-		if len(parts) >= 3 {
-			info["load_average"] = map[string]string{
-				"1m": parts[0], "5m": parts[1], "15m": parts[2],
-			}
+	oldLevel, _, _ := audio.GetVolume()
+	if body.Level != nil {
+		err := audio.SetVolume(*body.Level)
+		recordAudit(r, "volume.set", "", oldLevel, *body.Level, err)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "volume_error", err.Error())
+			return
 		}
 	}
-
-	if data, err := os.ReadFile("/proc/meminfo"); err == nil {
-		mem := parseMemInfo(string(data))
-		info["memory"] = mem
+	if body.Muted != nil {
+		action, set := "volume.unmute", audio.Unmute
+		if *body.Muted {
+			action, set = "volume.mute", audio.Mute
+		}
+		err := set()
+		recordAudit(r, action, "", nil, nil, err)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "volume_error", err.Error())
+			return
+		}
 	}
 
-	if data, err := os.ReadFile("/proc/stat"); err == nil {
-		cpu := parseCPUStat(string(data))
-		info["cpu"] = cpu
-	}
+	handleVolumeGet(w, r)
+}
 
-	info["disk"] = parseDiskUsage()
-	info["network"] = parseNetworkInterfaces()
-	info["temperature"] = parseTemperature()
+// GET /system
+func handleSystem(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, sysinfo.Collect())
+}
 
-	writeJSON(w, http.StatusOK, info)
+// GET /config/schema
+func handleConfigSchema(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, config.Schema)
 }
 
 // -- Helpers --
 
-func systemctlProperty(prop string) string {
-	out, err := exec.Command("systemctl", "show", kioskService,
-		"--property="+prop, "--value").Output()
-	if err != nil {
-		return "unknown"
+// configETag is the entity tag for the current config content.
+func configETag(cfg *config.Config) string {
+	return `"` + cfg.Version() + `"`
+}
+
+// checkIfMatch enforces an optional If-Match header against the config
+// version, writing 412 and returning false on mismatch.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, cfg *config.Config) bool {
+	match := r.Header.Get("If-Match")
+	if match == "" || match == "*" {
+		return true
+	}
+	current := configETag(cfg)
+	for _, tag := range strings.Split(match, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == current {
+			return true
+		}
 	}
-	return strings.TrimSpace(string(out))
+	w.Header().Set("ETag", current)
+	writeError(w, http.StatusPreconditionFailed, "precondition_failed",
+		"Config was changed by someone else (current ETag "+current+"). Reload and retry")
+	return false
+}
+
+func isSecretKey(name string) bool {
+	k, ok := config.LookupKey(name)
+	return ok && k.Secret
+}
+
+// writeValidationError maps config schema errors to 400 responses.
+func writeValidationError(w http.ResponseWriter, err error) {
+	var unknown *config.UnknownKeyError
+	if errors.As(err, &unknown) {
+		writeError(w, http.StatusBadRequest, "unknown_key", fmt.Sprintf("Unknown config key: %s", unknown.Key))
+		return
+	}
+	writeError(w, http.StatusBadRequest, "invalid_value", err.Error())
 }
 
 type extensionEntry struct {
@@ -309,7 +560,7 @@ type extensionEntry struct {
 }
 
 func getExtensionsDir() string {
-	cfg, err := config.Load(config.DefaultPath)
+	cfg, err := config.Load(configPath)
 	if err == nil {
 		if dir := cfg.Get("EXTENSIONS_DIR"); dir != "" {
 			return dir
@@ -358,130 +609,3 @@ func listExtensions() ([]extensionEntry, error) {
 	}
 	return exts, nil
 }
-
-func parseMemInfo(data string) map[string]int64 {
-	result := map[string]int64{}
-	scanner := bufio.NewScanner(strings.NewReader(data))
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
-			continue
-		}
-		key := strings.TrimSuffix(parts[0], ":")
-		switch key {
-		case "MemTotal", "MemFree", "MemAvailable", "SwapTotal", "SwapFree":
-			if val, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
-				result[key+"_kB"] = val
-			}
-		}
-	}
-	return result
-}
-
-func parseCPUStat(data string) map[string]string {
-	scanner := bufio.NewScanner(strings.NewReader(data))
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "cpu ") {
-			fields := strings.Fields(line)
-			if len(fields) >= 5 {
-				return map[string]string{
-					"user":   fields[1],
-					"nice":   fields[2],
-					"system": fields[3],
-					"idle":   fields[4],
-				}
-			}
-		}
-	}
-	return nil
-}
-
-func parseDiskUsage() []map[string]string {
-	out, err := exec.Command("df", "-h", "--output=target,size,used,avail,pcent", "/").Output()
-	if err != nil {
-		return nil
-	}
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-	if len(lines) < 2 {
-		return nil
-	}
-	var disks []map[string]string
-	for _, line := range lines[1:] {
-		fields := strings.Fields(line)
-		if len(fields) >= 5 {
-			disks = append(disks, map[string]string{
-				"mount": fields[0],
-				"size":  fields[1],
-				"used":  fields[2],
-				"avail": fields[3],
-				"use%":  fields[4],
-			})
-		}
-	}
-	return disks
-}
-
-func parseNetworkInterfaces() []map[string]string {
-	data, err := os.ReadFile("/proc/net/dev")
-	if err != nil {
-		return nil
-	}
-	lines := strings.Split(string(data), "\n")
-	var ifaces []map[string]string
-	for _, line := range lines[2:] {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		name := strings.TrimSpace(parts[0])
-		if name == "lo" {
-			continue
-		}
-		fields := strings.Fields(parts[1])
-		if len(fields) >= 9 {
-			ifaces = append(ifaces, map[string]string{
-				"name":     name,
-				"rx_bytes": fields[0],
-				"tx_bytes": fields[8],
-			})
-		}
-	}
-	return ifaces
-}
-
-func parseTemperature() []map[string]string {
-	entries, err := os.ReadDir("/sys/class/thermal")
-	if err != nil {
-		return nil
-	}
-	var temps []map[string]string
-	for _, entry := range entries {
-		if !strings.HasPrefix(entry.Name(), "thermal_zone") {
-			continue
-		}
-		base := filepath.Join("/sys/class/thermal", entry.Name())
-		typeData, err := os.ReadFile(filepath.Join(base, "type"))
-		if err != nil {
-			continue
-		}
-		tempData, err := os.ReadFile(filepath.Join(base, "temp"))
-		if err != nil {
-			continue
-		}
-		millideg, err := strconv.ParseInt(strings.TrimSpace(string(tempData)), 10, 64)
-		if err != nil {
-			continue
-		}
-		temps = append(temps, map[string]string{
-			"zone": strings.TrimSpace(string(typeData)),
-			"temp": fmt.Sprintf("%.1f", float64(millideg)/1000.0),
-		})
-	}
-	return temps
-}
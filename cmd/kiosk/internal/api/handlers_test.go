@@ -4,13 +4,19 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/audit"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/tokens"
 )
 
 func setupTestServer(token string) *http.ServeMux {
 	mux := http.NewServeMux()
-	registerRoutes(mux, token)
+	registerRoutes(mux, newAuthenticator(token, ""))
 	return mux
 }
 
@@ -102,3 +108,497 @@ func TestConfigSet_UnknownKey(t *testing.T) {
 		t.Errorf("expected error code 'unknown_key', got %+v", env.Error)
 	}
 }
+
+func TestConfigSet_InvalidValue(t *testing.T) {
+	mux := setupTestServer("secret")
+	rec := doRequest(mux, "PUT", "/wpe-webkit-kiosk/api/v1/config", "secret", `{"key": "API_PORT", "value": "99999"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+
+	var env envelope
+	json.Unmarshal(rec.Body.Bytes(), &env)
+	if env.Error == nil || env.Error.Code != "invalid_value" || !strings.Contains(env.Error.Message, "API_PORT") {
+		t.Errorf("expected error code 'invalid_value' naming API_PORT, got %+v", env.Error)
+	}
+}
+
+func TestNavigate_InvalidURL(t *testing.T) {
+	mux := setupTestServer("secret")
+	rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/navigate", "secret", `{"url": "example.com"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestNavigate_KeepsConcurrentConfigChanges(t *testing.T) {
+	useTempConfig(t, "URL=\"https://old.example.com\"\nTTY=\"1\"\n")
+	mux := setupTestServer("secret")
+	// A config change lands while the kiosk is navigating.
+	useKiosk(t, &stubKiosk{onOpen: func() {
+		if cfg, err := config.Load(configPath); err == nil {
+			cfg.Set("TTY", "7")
+			cfg.Save()
+		}
+	}})
+
+	rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/navigate", "secret", `{"url": "https://new.example.com"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"saved":true`) {
+		t.Fatalf("expected a saved navigation, got %d: %s", rec.Code, rec.Body)
+	}
+	cfg, _ := config.Load(configPath)
+	if cfg.Get("URL") != "https://new.example.com" || cfg.Get("TTY") != "7" {
+		t.Errorf("expected the new URL and the concurrent TTY change, got URL=%s TTY=%s", cfg.Get("URL"), cfg.Get("TTY"))
+	}
+
+	configPath = filepath.Join(t.TempDir(), "missing", "config")
+	rec = doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/navigate", "secret", `{"url": "https://new.example.com"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"saved":false`) {
+		t.Errorf("expected the failed save reported, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestConfigSchema_ListsKeys(t *testing.T) {
+	mux := setupTestServer("secret")
+	rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/config/schema", "secret", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var env struct {
+		Data []config.Key `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &env)
+	if len(env.Data) != len(config.Schema) {
+		t.Fatalf("expected %d keys, got %d", len(config.Schema), len(env.Data))
+	}
+	if env.Data[0].Name != "URL" || env.Data[0].Type != config.TypeURL || !env.Data[0].Live {
+		t.Errorf("unexpected first key: %+v", env.Data[0])
+	}
+}
+
+func TestAudit_QueryReturnsEntries(t *testing.T) {
+	orig := auditLog
+	auditLog = audit.New(filepath.Join(t.TempDir(), "audit.log"))
+	defer func() { auditLog = orig }()
+
+	auditLog.Record(audit.Entry{Actor: "support", Source: audit.SourceAPI, Action: "navigate"})
+	auditLog.Record(audit.Entry{Actor: "support", Source: audit.SourceAPI, Action: "restart"})
+
+	mux := setupTestServer("secret")
+	rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/audit?action=restart", "secret", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var env struct {
+		Data []audit.Entry `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &env)
+	if len(env.Data) != 1 || env.Data[0].Action != "restart" {
+		t.Errorf("expected one restart entry, got %+v", env.Data)
+	}
+}
+
+func TestAudit_InvalidSince(t *testing.T) {
+	mux := setupTestServer("secret")
+	rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/audit?since=yesterday", "secret", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestConfigHistory_DiffAndRollback(t *testing.T) {
+	useTempConfig(t, "TTY=\"1\"\nMETRICS_TOKEN=\"a\"\n")
+	cfg, _ := config.Load(configPath)
+	cfg.Set("TTY", "2")
+	cfg.Set("METRICS_TOKEN", "b")
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := setupTestServer("secret")
+
+	rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/config/history", "secret", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), `"b"`) {
+		t.Errorf("expected secret values to be redacted: %s", rec.Body.String())
+	}
+
+	rec = doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/config/history/1/diff", "secret", "")
+	var diff struct {
+		Data struct {
+			Changes []config.Change `json:"changes"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &diff)
+	// The history holds secrets redacted, so METRICS_TOKEN shows no change.
+	if len(diff.Data.Changes) != 1 || diff.Data.Changes[0].Key != "TTY" {
+		t.Errorf("unexpected diff: %+v", diff.Data.Changes)
+	}
+
+	rec = doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/config/history/1/rollback", "secret", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	restored, _ := config.Load(configPath)
+	if restored.Get("TTY") != "1" || restored.Get("METRICS_TOKEN") != "b" {
+		t.Errorf("unexpected config after rollback: TTY=%s METRICS_TOKEN=%s", restored.Get("TTY"), restored.Get("METRICS_TOKEN"))
+	}
+
+	rec = doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/config/history/42/rollback", "secret", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown revision, got %d", rec.Code)
+	}
+}
+
+// useTempConfig points the handlers at a temporary config file and audit log.
+func useTempConfig(t *testing.T, content string) {
+	t.Helper()
+	origConfig, origAudit := configPath, auditLog
+	configPath = filepath.Join(t.TempDir(), "config")
+	auditLog = audit.New(filepath.Join(t.TempDir(), "audit.log"))
+	t.Cleanup(func() { configPath, auditLog = origConfig, origAudit })
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigWrites_KeepSecretsOutOfAudit(t *testing.T) {
+	useTempConfig(t, "API_TOKEN=\"old-s3cret\"\nMETRICS_TOKEN=\"old-m3trics\"\nTTY=\"1\"\n")
+	mux := setupTestServer("old-s3cret")
+
+	doRequest(mux, "PUT", "/wpe-webkit-kiosk/api/v1/config", "old-s3cret", `{"key": "API_TOKEN", "value": "new-s3cret"}`)
+	doRequest(mux, "PATCH", "/wpe-webkit-kiosk/api/v1/config", "old-s3cret", `{"METRICS_TOKEN": "new-m3trics", "TTY": "2"}`)
+	rec := doRequest(mux, "PATCH", "/wpe-webkit-kiosk/api/v1/config", "old-s3cret", `{"TTY": "2"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	entries, err := auditLog.Query(audit.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(entries)
+	for _, secret := range []string{"old-s3cret", "new-s3cret", "old-m3trics", "new-m3trics"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("audit log contains %s: %s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), `"TTY":"2"`) {
+		t.Errorf("expected the TTY change audited, got %s", data)
+	}
+}
+
+func TestConfigPatch_ValidatesAllKeysFirst(t *testing.T) {
+	useTempConfig(t, "VNC_ENABLED=\"false\"\nVNC_PORT=\"5900\"\n")
+	mux := setupTestServer("secret")
+
+	rec := doRequest(mux, "PATCH", "/wpe-webkit-kiosk/api/v1/config", "secret",
+		`{"VNC_ENABLED": "true", "VNC_PORT": "99999", "TTY": "x"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	var env envelope
+	json.Unmarshal(rec.Body.Bytes(), &env)
+	if env.Error == nil || !strings.Contains(env.Error.Message, "VNC_PORT") || !strings.Contains(env.Error.Message, "TTY") {
+		t.Errorf("expected errors for VNC_PORT and TTY, got %+v", env.Error)
+	}
+
+	cfg, _ := config.Load(configPath)
+	if cfg.Get("VNC_ENABLED") != "false" {
+		t.Error("expected no key to be written when validation fails")
+	}
+}
+
+func TestConfigPatch_WritesOnce(t *testing.T) {
+	useTempConfig(t, "VNC_ENABLED=\"false\"\nVNC_PORT=\"5900\"\n")
+	mux := setupTestServer("secret")
+
+	rec := doRequest(mux, "PATCH", "/wpe-webkit-kiosk/api/v1/config", "secret",
+		`{"VNC_ENABLED": "true", "VNC_PORT": "5901"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("ETag") == "" {
+		t.Error("expected ETag header")
+	}
+
+	var env struct {
+		Data struct {
+			RestartRequired bool `json:"restart_required"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &env)
+	if !env.Data.RestartRequired {
+		t.Error("expected restart_required")
+	}
+
+	h, _ := config.LoadHistory(configPath)
+	if len(h.Revisions) != 2 {
+		t.Errorf("expected original + 1 revision, got %d", len(h.Revisions))
+	}
+	cfg, _ := config.Load(configPath)
+	if cfg.Get("VNC_ENABLED") != "true" || cfg.Get("VNC_PORT") != "5901" {
+		t.Errorf("unexpected config: %+v", cfg.KeyValues())
+	}
+}
+
+func TestConfigPatch_IfMatch(t *testing.T) {
+	useTempConfig(t, "TTY=\"1\"\n")
+	mux := setupTestServer("secret")
+
+	rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/config", "secret", "")
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag on GET /config")
+	}
+
+	patch := func(ifMatch, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", "/wpe-webkit-kiosk/api/v1/config", strings.NewReader(body))
+		req.Header.Set("X-Api-Key", "secret")
+		req.Header.Set("If-Match", ifMatch)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := patch(etag, `{"TTY": "2"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with current ETag, got %d", rec.Code)
+	}
+	rec = patch(etag, `{"TTY": "3"}`)
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 with stale ETag, got %d", rec.Code)
+	}
+	cfg, _ := config.Load(configPath)
+	if cfg.Get("TTY") != "2" {
+		t.Errorf("expected stale write to be rejected, TTY=%s", cfg.Get("TTY"))
+	}
+}
+
+func TestConfigPatch_RestartNeedsAdmin(t *testing.T) {
+	useTempConfig(t, "TTY=\"1\"\n")
+	storePath, secret := writeTokenStore(t, "pipeline", []string{tokens.ScopeConfig}, nil)
+	mux := http.NewServeMux()
+	registerRoutes(mux, newAuthenticator("", storePath))
+
+	rec := doRequest(mux, "PATCH", "/wpe-webkit-kiosk/api/v1/config?restart=true", secret, `{"TTY": "2"}`)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", rec.Code)
+	}
+}
+
+func TestVolumeSet_ValidatesBody(t *testing.T) {
+	mux := setupTestServer("test-token")
+
+	for _, body := range []string{`{}`, `{"level": 101}`, `{"level": -1}`, `not json`} {
+		rec := doRequest(mux, "PUT", "/wpe-webkit-kiosk/api/v1/volume", "test-token", body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("body %s: expected 400, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestPlaylist_AddStartRemove(t *testing.T) {
+	useTempConfig(t, "")
+	origPlaylist := playlistPath
+	playlistPath = filepath.Join(t.TempDir(), "playlist.json")
+	t.Cleanup(func() { playlistPath = origPlaylist })
+
+	mux := setupTestServer("secret")
+	base := "/wpe-webkit-kiosk/api/v1/playlist"
+
+	if rec := doRequest(mux, "POST", base+"/start", "secret", ""); rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 starting an empty playlist, got %d", rec.Code)
+	}
+	if rec := doRequest(mux, "POST", base+"/entries", "secret", `{"url": "https://a.example", "dwell": 1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a too short dwell time, got %d", rec.Code)
+	}
+
+	doRequest(mux, "POST", base+"/entries", "secret", `{"url": "https://b.example", "dwell": 30, "window": "08:00-18:00"}`)
+	rec := doRequest(mux, "POST", base+"/entries", "secret", `{"url": "https://a.example", "position": 1}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doRequest(mux, "POST", base+"/start", "secret", "")
+	var resp struct {
+		Data struct {
+			Running bool `json:"running"`
+			Entries []struct {
+				URL   string `json:"url"`
+				Dwell int    `json:"dwell"`
+			} `json:"entries"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if !resp.Data.Running || len(resp.Data.Entries) != 2 {
+		t.Fatalf("unexpected playlist after start: %s", rec.Body.String())
+	}
+	if e := resp.Data.Entries[0]; e.URL != "https://a.example" || e.Dwell != 60 {
+		t.Errorf("expected a.example first with default dwell, got %+v", e)
+	}
+
+	if rec := doRequest(mux, "DELETE", base+"/entries/3", "secret", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing position, got %d", rec.Code)
+	}
+	rec = doRequest(mux, "DELETE", base+"/entries/1", "secret", "")
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.Data.Entries) != 1 || resp.Data.Entries[0].URL != "https://b.example" {
+		t.Errorf("unexpected playlist after remove: %s", rec.Body.String())
+	}
+}
+
+func TestSchedule_ListsEntriesAndUpcoming(t *testing.T) {
+	origSchedule := schedulePath
+	schedulePath = filepath.Join(t.TempDir(), "schedule")
+	t.Cleanup(func() { schedulePath = origSchedule })
+	os.WriteFile(schedulePath, []byte("# Store hours\n0 22 * * * stop\n0 6 * * * start\n"), 0644)
+
+	mux := setupTestServer("secret")
+
+	rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/schedule?count=3", "secret", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data struct {
+			Entries []struct {
+				Position int    `json:"position"`
+				Spec     string `json:"spec"`
+				Action   string `json:"action"`
+			} `json:"entries"`
+			Upcoming []struct {
+				Position int    `json:"position"`
+				Action   string `json:"action"`
+			} `json:"upcoming"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.Data.Entries) != 2 || resp.Data.Entries[1].Position != 2 || resp.Data.Entries[1].Action != "start" {
+		t.Errorf("unexpected entries: %s", rec.Body.String())
+	}
+	if len(resp.Data.Upcoming) != 3 {
+		t.Errorf("expected 3 upcoming runs: %s", rec.Body.String())
+	}
+
+	if rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/schedule?count=-1", "secret", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid count, got %d", rec.Code)
+	}
+}
+
+func TestNavigate_BlockedByPolicy(t *testing.T) {
+	useTempConfig(t, "URL=\"https://example.com\"\nNAV_ALLOW=\"example.com\"\n")
+	mux := setupTestServer("secret")
+
+	rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/navigate", "secret", `{"url": "https://other.org/"}`)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var env envelope
+	json.Unmarshal(rec.Body.Bytes(), &env)
+	if env.Error == nil || env.Error.Code != "navigation_blocked" {
+		t.Errorf("expected navigation_blocked, got %s", rec.Body.String())
+	}
+}
+
+func TestPolicy_ExplainsDecision(t *testing.T) {
+	useTempConfig(t, "NAV_ALLOW=\"*.example.com\"\nNAV_DENY=\"http://*\"\n")
+	mux := setupTestServer("secret")
+
+	rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/policy?url=https%3A%2F%2Fshop.example.com%2F", "secret", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data struct {
+			Allow    []struct{ Pattern string } `json:"allow"`
+			Deny     []struct{ Pattern string } `json:"deny"`
+			Decision struct {
+				Allowed bool `json:"allowed"`
+				Rule    struct {
+					Pattern string `json:"pattern"`
+					List    string `json:"list"`
+				} `json:"rule"`
+			} `json:"decision"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.Data.Allow) != 1 || len(resp.Data.Deny) != 1 {
+		t.Errorf("unexpected rules: %s", rec.Body.String())
+	}
+	if d := resp.Data.Decision; !d.Allowed || d.Rule.Pattern != "*.example.com" || d.Rule.List != "allow" {
+		t.Errorf("unexpected decision: %s", rec.Body.String())
+	}
+}
+
+func TestScreenshot_ValidatesQuery(t *testing.T) {
+	mux := setupTestServer("secret")
+	for _, q := range []string{"scale=abc", "scale=2", "scale=0.01", "quality=0", "quality=101"} {
+		rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/screenshot?"+q, "secret", "")
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", q, rec.Code)
+		}
+	}
+}
+
+func TestEval_ValidatesBody(t *testing.T) {
+	mux := setupTestServer("secret")
+	for _, body := range []string{`{}`, `{"script": "1+1", "timeout": 61}`, `not json`} {
+		rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/eval", "secret", body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestEval_RequiresEvalScope(t *testing.T) {
+	path, secret := writeTokenStore(t, "ops", []string{tokens.ScopeRead, tokens.ScopeNavigate, tokens.ScopeConfig}, nil)
+	mux := http.NewServeMux()
+	registerRoutes(mux, newAuthenticator("", path))
+
+	rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/eval", secret, `{"script": "1+1"}`)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", rec.Code)
+	}
+}
+
+func TestPageActions_RequireNavigateScope(t *testing.T) {
+	path, secret := writeTokenStore(t, "viewer", []string{tokens.ScopeRead}, nil)
+	mux := http.NewServeMux()
+	registerRoutes(mux, newAuthenticator("", path))
+
+	for _, action := range []string{"back", "forward", "stop"} {
+		rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/"+action, secret, "")
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403, got %d", action, rec.Code)
+		}
+	}
+}
+
+func TestExtensionEnable_ReportsFailure(t *testing.T) {
+	extDir := t.TempDir()
+	useTempConfig(t, "EXTENSIONS_DIR=\""+extDir+"\"\n")
+	mux := setupTestServer("secret")
+	os.MkdirAll(filepath.Join(extDir, "idle"), 0755)
+	os.WriteFile(filepath.Join(extDir, "idle", "manifest.json"), []byte(`{"name": "idle", "version": "1.0"}`), 0644)
+	// A marker that can be neither removed nor replaced.
+	os.MkdirAll(filepath.Join(extDir, "idle", ".disabled", "keep"), 0755)
+
+	rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/extensions/idle/enable", "secret", "")
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d: %s", rec.Code, rec.Body)
+	}
+	entries, _ := auditLog.Query(audit.Filter{Action: "extension"})
+	if len(entries) != 1 || entries[0].Outcome != audit.OutcomeFailure || entries[0].Error == "" {
+		t.Errorf("expected a failed extension.enable entry, got %+v", entries)
+	}
+
+	os.RemoveAll(filepath.Join(extDir, "idle", ".disabled"))
+	rec = doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/extensions/idle/enable", "secret", "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected enabling an enabled extension to succeed, got %d", rec.Code)
+	}
+}
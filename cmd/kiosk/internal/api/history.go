@@ -0,0 +1,163 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+)
+
+const redactedValue = config.Redacted
+
+type revisionEntry struct {
+	Rev     int             `json:"rev"`
+	Time    time.Time       `json:"time"`
+	Current bool            `json:"current"`
+	Changes []config.Change `json:"changes"` // relative to the previous revision, nil for the oldest kept
+}
+
+// GET /config/history
+func handleConfigHistory(w http.ResponseWriter, r *http.Request) {
+	h, err := config.LoadHistory(configPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "config_error", err.Error())
+		return
+	}
+	raw, _ := os.ReadFile(configPath)
+	current := config.RedactContent(string(raw)) // revisions are stored redacted
+
+	result := []revisionEntry{}
+	for i := len(h.Revisions) - 1; i >= 0; i-- {
+		rev := h.Revisions[i]
+		entry := revisionEntry{
+			Rev:     rev.Rev,
+			Time:    rev.Time,
+			Current: rev.Content == current,
+		}
+		if prev, ok := h.Previous(rev.Rev); ok {
+			entry.Changes = redactChanges(config.Diff(prev.Content, rev.Content))
+		}
+		result = append(result, entry)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// GET /config/history/{rev}/diff
+func handleConfigDiff(w http.ResponseWriter, r *http.Request) {
+	rev, ok := revisionParam(w, r)
+	if !ok {
+		return
+	}
+	h, err := config.LoadHistory(configPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "config_error", err.Error())
+		return
+	}
+	saved, found := h.Get(rev)
+	if !found {
+		writeError(w, http.StatusNotFound, "not_found", "Config revision not found: "+strconv.Itoa(rev))
+		return
+	}
+	current, err := os.ReadFile(configPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "config_error", err.Error())
+		return
+	}
+
+	changes := redactChanges(config.Diff(saved.Content, config.RedactContent(string(current))))
+	if changes == nil {
+		changes = []config.Change{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"rev":     rev,
+		"changes": changes,
+	})
+}
+
+// POST /config/history/{rev}/rollback
+func handleConfigRollback(w http.ResponseWriter, r *http.Request) {
+	rev, ok := revisionParam(w, r)
+	if !ok {
+		return
+	}
+	target := "rev " + strconv.Itoa(rev)
+
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	h, err := config.LoadHistory(configPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "config_error", err.Error())
+		return
+	}
+	if _, found := h.Get(rev); !found {
+		writeError(w, http.StatusNotFound, "not_found", "Config revision not found: "+strconv.Itoa(rev))
+		return
+	}
+
+	changes, err := config.Rollback(configPath, rev)
+	if err != nil {
+		recordAudit(r, "config.rollback", target, nil, nil, err)
+		writeError(w, http.StatusInternalServerError, "config_error", err.Error())
+		return
+	}
+
+	restartRequired := false
+	keys := []string{}
+	for _, c := range changes {
+		keys = append(keys, c.Key)
+		if config.NeedsRestart(c.Key) {
+			restartRequired = true
+		}
+		if c.Key == "URL" && c.New != "" {
+			kiosk.Open(r.Context(), c.New)
+		}
+		if !isSecretKey(c.Key) {
+			events.publish(eventConfig, map[string]any{
+				"key":              c.Key,
+				"value":            c.New,
+				"restart_required": config.NeedsRestart(c.Key),
+			})
+		}
+	}
+	if len(changes) > 0 {
+		recordAudit(r, "config.rollback", target, nil, keys, nil)
+	}
+
+	redacted := redactChanges(changes)
+	if redacted == nil {
+		redacted = []config.Change{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"rev":              rev,
+		"changes":          redacted,
+		"restart_required": restartRequired,
+	})
+}
+
+func revisionParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	rev, err := strconv.Atoi(r.PathValue("rev"))
+	if err != nil || rev < 1 {
+		writeError(w, http.StatusBadRequest, "invalid_revision", "Revision must be a positive integer")
+		return 0, false
+	}
+	return rev, true
+}
+
+// redactChanges hides the values of secret keys.
+func redactChanges(changes []config.Change) []config.Change {
+	for i, c := range changes {
+		if !isSecretKey(c.Key) {
+			continue
+		}
+		if c.Old != "" {
+			changes[i].Old = redactedValue
+		}
+		if c.New != "" {
+			changes[i].New = redactedValue
+		}
+	}
+	return changes
+}
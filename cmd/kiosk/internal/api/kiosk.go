@@ -0,0 +1,33 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
+)
+
+// kiosk is the launcher's D-Bus interface, shared by all handlers.
+var kiosk dbus.Kiosk = dbus.Default()
+
+// services manages the kiosk's systemd units.
+var services service.Manager = service.Default()
+
+// writeKioskError responds to a failed kiosk call with the status code
+// matching its error, the same for every endpoint.
+func writeKioskError(w http.ResponseWriter, err error) {
+	var scriptErr *dbus.ScriptError
+	switch {
+	case errors.Is(err, dbus.ErrNotRunning):
+		writeError(w, http.StatusServiceUnavailable, "service_unavailable", "Kiosk service is not running")
+	case errors.Is(err, dbus.ErrTimeout):
+		writeError(w, http.StatusGatewayTimeout, "timeout", "Kiosk did not respond in time")
+	case errors.Is(err, dbus.ErrInvalidScope):
+		writeError(w, http.StatusBadRequest, "invalid_scope", "Valid scopes: cache, cookies, all")
+	case errors.As(err, &scriptErr):
+		writeError(w, http.StatusUnprocessableEntity, "script_error", scriptErr.Message)
+	default:
+		writeError(w, http.StatusInternalServerError, "dbus_error", err.Error())
+	}
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus/dbustest"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/schedule"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service/servicetest"
+)
+
+// stubKiosk answers page info and fails every other call with err.
+type stubKiosk struct {
+	dbus.Kiosk
+	page   dbus.PageInfo
+	err    error
+	onOpen func() // runs while the kiosk navigates
+}
+
+func (k *stubKiosk) Open(ctx context.Context, url string) error {
+	if k.onOpen != nil {
+		k.onOpen()
+	}
+	return k.err
+}
+
+func (k *stubKiosk) GetUrl(ctx context.Context) (string, error)             { return k.page.URI, k.err }
+func (k *stubKiosk) GetPageInfo(ctx context.Context) (dbus.PageInfo, error) { return k.page, nil }
+func (k *stubKiosk) GoBack(ctx context.Context) error                       { return k.err }
+func (k *stubKiosk) Reload(ctx context.Context) error                       { return k.err }
+func (k *stubKiosk) ClearData(ctx context.Context, scope string) error      { return k.err }
+
+func useKiosk(t *testing.T, k dbus.Kiosk) {
+	t.Helper()
+	old := kiosk
+	kiosk = k
+	t.Cleanup(func() { kiosk = old })
+}
+
+func useServices(t *testing.T, m service.Manager) {
+	t.Helper()
+	old := services
+	services = m
+	t.Cleanup(func() { services = old })
+}
+
+func TestKioskErrors_MapToStatusCodes(t *testing.T) {
+	useTempConfig(t, "")
+	mux := setupTestServer("secret")
+
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{dbus.ErrNotRunning, http.StatusServiceUnavailable},
+		{dbus.ErrTimeout, http.StatusGatewayTimeout},
+		{dbus.ErrInvalidScope, http.StatusBadRequest},
+		{context.Canceled, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		useKiosk(t, &stubKiosk{err: tt.err})
+		rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/reload", "secret", "")
+		if rec.Code != tt.want {
+			t.Errorf("%v: expected %d, got %d", tt.err, tt.want, rec.Code)
+		}
+	}
+}
+
+func TestBack_NoHistory(t *testing.T) {
+	useTempConfig(t, "")
+	mux := setupTestServer("secret")
+
+	useKiosk(t, &stubKiosk{page: dbus.PageInfo{URI: "https://example.com/"}})
+	rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/back", "secret", "")
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", rec.Code)
+	}
+
+	useKiosk(t, &stubKiosk{page: dbus.PageInfo{CanGoBack: true}})
+	rec = doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/back", "secret", "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+// useFakeKiosk points the API at a fake kiosk on a private bus.
+func useFakeKiosk(t *testing.T) *dbustest.Kiosk {
+	t.Helper()
+	addr := dbustest.StartBus(t)
+	fake := dbustest.NewKiosk(t, addr)
+	client := dbus.NewClientAt(addr)
+	t.Cleanup(func() { client.Close() })
+	useKiosk(t, client)
+	return fake
+}
+
+func TestNavigateAndBack_OverDBus(t *testing.T) {
+	useTempConfig(t, "")
+	mux := setupTestServer("secret")
+	fake := useFakeKiosk(t)
+
+	for _, url := range []string{"https://a.example/", "https://b.example/"} {
+		rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/navigate", "secret", `{"url":"`+url+`"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("navigate %s: expected 200, got %d: %s", url, rec.Code, rec.Body)
+		}
+	}
+	rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/back", "secret", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("back: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if got := fake.State().URL(); got != "https://a.example/" {
+		t.Errorf("expected kiosk on https://a.example/, got %q", got)
+	}
+	want := []string{"Open", "Open", "GetPageInfo", "GoBack"}
+	if got := fake.Methods(); !slices.Equal(got, want) {
+		t.Errorf("expected calls %v, got %v", want, got)
+	}
+}
+
+func TestEval_OverDBus(t *testing.T) {
+	useTempConfig(t, "")
+	mux := setupTestServer("secret")
+	fake := useFakeKiosk(t)
+
+	fake.Update(func(s *dbustest.State) { s.EvalResult = `"Welcome"` })
+	rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/eval", "secret", `{"script":"document.title"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"result":"Welcome"`) {
+		t.Errorf("expected 200 with result, got %d: %s", rec.Code, rec.Body)
+	}
+
+	fake.Fail("EvaluateScript", dbustest.ScriptException("boom"))
+	rec = doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/eval", "secret", `{"script":"throw 'boom'"}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d: %s", rec.Code, rec.Body)
+	}
+
+	fake.Stop()
+	rec = doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/eval", "secret", `{"script":"1"}`)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestStatus_ReportsServiceState(t *testing.T) {
+	useTempConfig(t, "")
+	mux := setupTestServer("secret")
+	useKiosk(t, &stubKiosk{err: dbus.ErrNotRunning})
+
+	m := servicetest.NewManager()
+	since := time.Date(2026, 2, 20, 23, 29, 26, 0, time.Local)
+	m.Set(kioskService, service.Status{State: "active", Since: since})
+	useServices(t, m)
+
+	rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/status", "secret", "")
+	want := `"uptime":"` + since.Format(service.TimeLayout) + `"`
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"service":"active"`) ||
+		!strings.Contains(rec.Body.String(), want) {
+		t.Errorf("expected active service since %s, got %d: %s", since, rec.Code, rec.Body)
+	}
+
+	m.Fail(kioskService, errors.New("no system bus"))
+	rec = doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/status", "secret", "")
+	if !strings.Contains(rec.Body.String(), `"service":"unknown"`) || !strings.Contains(rec.Body.String(), `"uptime":null`) {
+		t.Errorf("expected unknown service, got %s", rec.Body)
+	}
+}
+
+func TestRestart_UsesServiceManager(t *testing.T) {
+	useTempConfig(t, "")
+	mux := setupTestServer("secret")
+	m := servicetest.NewManager(kioskService)
+	useServices(t, m)
+
+	rec := doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/restart", "secret", "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	want := []servicetest.Call{{Verb: "restart", Unit: kioskService}}
+	if got := m.Calls(); !slices.Equal(got, want) {
+		t.Errorf("expected calls %v, got %v", want, got)
+	}
+
+	m.Fail(kioskService, errors.New("access denied"))
+	rec = doRequest(mux, "POST", "/wpe-webkit-kiosk/api/v1/restart", "secret", "")
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestRunScheduled_ServiceActions(t *testing.T) {
+	useTempConfig(t, "")
+	m := servicetest.NewManager(kioskService)
+	useServices(t, m)
+
+	for _, tt := range []struct{ action, want string }{
+		{schedule.ActionStop, "inactive"},
+		{schedule.ActionStart, "active"},
+		{schedule.ActionRestart, "active"},
+	} {
+		if err := runScheduled(schedule.Entry{Action: tt.action}); err != nil {
+			t.Fatalf("%s: %v", tt.action, err)
+		}
+		if got := service.State(context.Background(), m, kioskService); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.action, tt.want, got)
+		}
+	}
+	if n := len(m.Calls()); n != 3 {
+		t.Errorf("expected 3 jobs, got %d", n)
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/audit"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/logs"
+)
+
+// logFilter builds a journal filter from the query parameters shared by
+// /logs and /logs/stream.
+func logFilter(q url.Values) (logs.Filter, error) {
+	now := time.Now()
+	f := logs.Filter{
+		Priority: q.Get("priority"),
+		Grep:     q.Get("grep"),
+	}
+	for _, v := range q["unit"] {
+		for _, u := range strings.Split(v, ",") {
+			f.Units = append(f.Units, strings.TrimSpace(u))
+		}
+	}
+	if v := q.Get("since"); v != "" {
+		t, err := audit.ParseTime(v, now)
+		if err != nil {
+			return f, err
+		}
+		f.Since = t
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return f, errors.New("Parameter 'limit' must be a non-negative integer")
+		}
+		f.Limit = n
+	}
+	return f, nil
+}
+
+// GET /logs
+func handleLogs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f, err := logFilter(q)
+	if err == nil && q.Get("until") != "" {
+		f.Until, err = audit.ParseTime(q.Get("until"), time.Now())
+	}
+	if err == nil {
+		f.Before = q.Get("before")
+		err = f.Validate()
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+
+	page, err := logs.Query(r.Context(), f)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "journal_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// GET /logs/stream
+func handleLogsStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "stream_unsupported", "Streaming is not supported")
+		return
+	}
+
+	f, err := logFilter(r.URL.Query())
+	if err == nil {
+		// A reconnecting EventSource resumes after the last entry it got.
+		f.After = r.Header.Get("Last-Event-ID")
+		err = f.Validate()
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+
+	entries, err := logs.Follow(r.Context(), f)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "journal_error", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case e, ok := <-entries:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: log\ndata: %s\n\n", e.Cursor, data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestLogs_ValidatesQuery(t *testing.T) {
+	mux := setupTestServer("secret")
+
+	for _, query := range []string{
+		"unit=ssh",
+		"priority=loud",
+		"grep=(",
+		"limit=-1",
+		"limit=5000",
+		"since=yesterday",
+		"until=soon",
+	} {
+		rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/logs?"+query, "secret", "")
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+
+	rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/logs/stream?before=c1&unit=ssh", "secret", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("stream: expected 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,367 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/sysinfo"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the request duration histogram.
+var latencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type requestKey struct {
+	route, method, code string
+}
+
+type latencyKey struct {
+	route, method string
+}
+
+type histogram struct {
+	buckets []uint64 // cumulative counts per latencyBuckets entry
+	count   uint64
+	sum     float64
+}
+
+// requestMetrics counts API requests and their latencies by route.
+type requestMetrics struct {
+	mu       sync.Mutex
+	requests map[requestKey]uint64
+	latency  map[latencyKey]*histogram
+}
+
+func newRequestMetrics() *requestMetrics {
+	return &requestMetrics{
+		requests: map[requestKey]uint64{},
+		latency:  map[latencyKey]*histogram{},
+	}
+}
+
+// apiMetrics is the process-wide request metrics collector.
+var apiMetrics = newRequestMetrics()
+
+func (m *requestMetrics) observe(route, method string, status int, d time.Duration) {
+	secs := d.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{route, method, strconv.Itoa(status)}]++
+
+	h := m.latency[latencyKey{route, method}]
+	if h == nil {
+		h = &histogram{buckets: make([]uint64, len(latencyBuckets))}
+		m.latency[latencyKey{route, method}] = h
+	}
+	for i, le := range latencyBuckets {
+		if secs <= le {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += secs
+}
+
+// statusRecorder captures the response status while passing writes and
+// flushes through, so streaming handlers keep working.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(code int) {
+	if rec.status == 0 {
+		rec.status = code
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *statusRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// instrument records every request in apiMetrics, labeled with the path of
+// the routes pattern it matches ("unmatched" otherwise).
+func instrument(routes *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unmatched"
+		if _, pattern := routes.Handler(r); pattern != "" {
+			if i := strings.IndexByte(pattern, ' '); i >= 0 {
+				pattern = pattern[i+1:]
+			}
+			route = pattern
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		apiMetrics.observe(route, r.Method, rec.status, time.Since(start))
+	})
+}
+
+// metricsAuth guards /metrics with the METRICS_TOKEN bearer token.
+// Without a configured token the endpoint is public.
+func metricsAuth(auth *authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(auth.metricsToken) > 0 {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), auth.metricsToken) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="kiosk-metrics"`)
+				writeError(w, http.StatusUnauthorized, "unauthorized", "Invalid metrics token")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GET /metrics
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var mw metricsWriter
+	writeKioskMetrics(r.Context(), &mw)
+	writeRequestMetrics(&mw, apiMetrics)
+	writeHostMetrics(&mw)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(mw.buf.Bytes())
+}
+
+func writeKioskMetrics(ctx context.Context, mw *metricsWriter) {
+	state := service.State(ctx, services, kioskService)
+	active := 0.0
+	if state == "active" {
+		active = 1
+	}
+	mw.family("kiosk_service_active", "gauge", "Whether the kiosk service is active.")
+	mw.sample("kiosk_service_active", nil, active)
+	mw.family("kiosk_service_state", "gauge", "Current systemd ActiveState of the kiosk service.")
+	mw.sample("kiosk_service_state", []string{"state", state}, 1)
+
+	var stats *dbus.Stats
+	if st, err := kiosk.GetStats(ctx); err == nil {
+		stats = &st
+	}
+	up := 0.0
+	if stats != nil {
+		up = 1
+	}
+	mw.family("kiosk_browser_up", "gauge", "Whether the browser answered on D-Bus.")
+	mw.sample("kiosk_browser_up", nil, up)
+	if stats != nil {
+		mw.family("kiosk_page_loads_total", "counter", "Page loads finished since the browser started.")
+		mw.sample("kiosk_page_loads_total", nil, float64(stats.PageLoads))
+		mw.family("kiosk_web_process_crashes_total", "counter", "Web process crashes since the browser started.")
+		mw.sample("kiosk_web_process_crashes_total", nil, float64(stats.WebProcessCrashes))
+	}
+
+	if exts, err := listExtensions(); err == nil {
+		enabled := 0
+		for _, e := range exts {
+			if e.Enabled {
+				enabled++
+			}
+		}
+		mw.family("kiosk_extensions", "gauge", "Installed extensions by state.")
+		mw.sample("kiosk_extensions", []string{"state", "enabled"}, float64(enabled))
+		mw.sample("kiosk_extensions", []string{"state", "disabled"}, float64(len(exts)-enabled))
+	}
+
+	mw.family("kiosk_api_event_subscribers", "gauge", "Open /events streams.")
+	mw.sample("kiosk_api_event_subscribers", nil, float64(events.subscribers()))
+}
+
+func writeRequestMetrics(mw *metricsWriter, m *requestMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reqKeys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		reqKeys = append(reqKeys, k)
+	}
+	sort.Slice(reqKeys, func(i, j int) bool {
+		a, b := reqKeys[i], reqKeys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.code < b.code
+	})
+	mw.family("kiosk_api_requests_total", "counter", "API requests by route, method and status code.")
+	for _, k := range reqKeys {
+		mw.sample("kiosk_api_requests_total",
+			[]string{"route", k.route, "method", k.method, "code", k.code}, float64(m.requests[k]))
+	}
+
+	latKeys := make([]latencyKey, 0, len(m.latency))
+	for k := range m.latency {
+		latKeys = append(latKeys, k)
+	}
+	sort.Slice(latKeys, func(i, j int) bool {
+		a, b := latKeys[i], latKeys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		return a.method < b.method
+	})
+	mw.family("kiosk_api_request_duration_seconds", "histogram", "API request latency by route and method.")
+	for _, k := range latKeys {
+		h := m.latency[k]
+		for i, le := range latencyBuckets {
+			mw.sample("kiosk_api_request_duration_seconds_bucket",
+				[]string{"route", k.route, "method", k.method, "le", formatFloat(le)}, float64(h.buckets[i]))
+		}
+		mw.sample("kiosk_api_request_duration_seconds_bucket",
+			[]string{"route", k.route, "method", k.method, "le", "+Inf"}, float64(h.count))
+		mw.sample("kiosk_api_request_duration_seconds_sum",
+			[]string{"route", k.route, "method", k.method}, h.sum)
+		mw.sample("kiosk_api_request_duration_seconds_count",
+			[]string{"route", k.route, "method", k.method}, float64(h.count))
+	}
+}
+
+// userHZ is the kernel clock tick rate used by /proc/stat.
+const userHZ = 100
+
+func writeHostMetrics(mw *metricsWriter) {
+	if data, err := os.ReadFile("/proc/uptime"); err == nil {
+		if parts := strings.Fields(string(data)); len(parts) > 0 {
+			if secs, err := strconv.ParseFloat(parts[0], 64); err == nil {
+				mw.family("kiosk_host_uptime_seconds", "gauge", "Host uptime.")
+				mw.sample("kiosk_host_uptime_seconds", nil, secs)
+			}
+		}
+	}
+
+	if data, err := os.ReadFile("/proc/loadavg"); err == nil {
+		if parts := strings.Fields(string(data)); len(parts) >= 3 {
+			mw.family("kiosk_host_load_average", "gauge", "Host load average.")
+			for i, period := range []string{"1m", "5m", "15m"} {
+				if v, err := strconv.ParseFloat(parts[i], 64); err == nil {
+					mw.sample("kiosk_host_load_average", []string{"period", period}, v)
+				}
+			}
+		}
+	}
+
+	if data, err := os.ReadFile("/proc/meminfo"); err == nil {
+		mem := sysinfo.ParseMemInfo(string(data))
+		mw.family("kiosk_host_memory_bytes", "gauge", "Host memory from /proc/meminfo.")
+		for _, field := range []string{"MemTotal", "MemFree", "MemAvailable", "SwapTotal", "SwapFree"} {
+			if kb, ok := mem[field+"_kB"]; ok {
+				mw.sample("kiosk_host_memory_bytes", []string{"type", field}, float64(kb*1024))
+			}
+		}
+	}
+
+	if data, err := os.ReadFile("/proc/stat"); err == nil {
+		if cpu := sysinfo.ParseCPUStat(string(data)); cpu != nil {
+			mw.family("kiosk_host_cpu_seconds_total", "counter", "Host CPU time by mode.")
+			for _, mode := range []string{"user", "nice", "system", "idle"} {
+				if ticks, err := strconv.ParseFloat(cpu[mode], 64); err == nil {
+					mw.sample("kiosk_host_cpu_seconds_total", []string{"mode", mode}, ticks/userHZ)
+				}
+			}
+		}
+	}
+
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs("/", &fs); err == nil {
+		mw.family("kiosk_host_filesystem_size_bytes", "gauge", "Filesystem size.")
+		mw.sample("kiosk_host_filesystem_size_bytes", []string{"mount", "/"}, float64(fs.Blocks)*float64(fs.Bsize))
+		mw.family("kiosk_host_filesystem_avail_bytes", "gauge", "Filesystem space available to unprivileged users.")
+		mw.sample("kiosk_host_filesystem_avail_bytes", []string{"mount", "/"}, float64(fs.Bavail)*float64(fs.Bsize))
+	}
+
+	if ifaces := sysinfo.NetworkInterfaces(); len(ifaces) > 0 {
+		mw.family("kiosk_host_network_receive_bytes_total", "counter", "Bytes received by network interface.")
+		for _, iface := range ifaces {
+			if v, err := strconv.ParseFloat(iface["rx_bytes"], 64); err == nil {
+				mw.sample("kiosk_host_network_receive_bytes_total", []string{"interface", iface["name"]}, v)
+			}
+		}
+		mw.family("kiosk_host_network_transmit_bytes_total", "counter", "Bytes transmitted by network interface.")
+		for _, iface := range ifaces {
+			if v, err := strconv.ParseFloat(iface["tx_bytes"], 64); err == nil {
+				mw.sample("kiosk_host_network_transmit_bytes_total", []string{"interface", iface["name"]}, v)
+			}
+		}
+	}
+
+	if temps := sysinfo.Temperature(); len(temps) > 0 {
+		mw.family("kiosk_host_temperature_celsius", "gauge", "Thermal zone temperature.")
+		for _, t := range temps {
+			if v, err := strconv.ParseFloat(t["temp"], 64); err == nil {
+				mw.sample("kiosk_host_temperature_celsius", []string{"sensor", t["sensor"], "zone", t["zone"]}, v)
+			}
+		}
+	}
+}
+
+// metricsWriter renders the Prometheus text exposition format.
+type metricsWriter struct {
+	buf bytes.Buffer
+}
+
+func (mw *metricsWriter) family(name, typ, help string) {
+	fmt.Fprintf(&mw.buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes one series; labels are name/value pairs.
+func (mw *metricsWriter) sample(name string, labels []string, value float64) {
+	mw.buf.WriteString(name)
+	if len(labels) > 0 {
+		mw.buf.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				mw.buf.WriteByte(',')
+			}
+			fmt.Fprintf(&mw.buf, "%s=\"%s\"", labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		mw.buf.WriteByte('}')
+	}
+	mw.buf.WriteByte(' ')
+	mw.buf.WriteString(formatFloat(value))
+	mw.buf.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
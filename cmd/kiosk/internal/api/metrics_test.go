@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics_PublicWithoutToken(t *testing.T) {
+	mux := setupTestServer("secret")
+	rec := doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/metrics", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "# TYPE kiosk_service_active gauge") {
+		t.Errorf("expected kiosk_service_active family, got:\n%s", rec.Body.String())
+	}
+}
+
+func TestMetrics_BearerToken(t *testing.T) {
+	auth := newAuthenticator("secret", "")
+	auth.metricsToken = []byte("scrape")
+	mux := http.NewServeMux()
+	registerRoutes(mux, auth)
+
+	for _, tc := range []struct {
+		header string
+		want   int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Bearer scrape", http.StatusOK},
+	} {
+		req := httptest.NewRequest("GET", "/wpe-webkit-kiosk/api/v1/metrics", nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("Authorization %q: expected %d, got %d", tc.header, tc.want, rec.Code)
+		}
+	}
+}
+
+func TestInstrument_CountsByRoute(t *testing.T) {
+	orig := apiMetrics
+	apiMetrics = newRequestMetrics()
+	defer func() { apiMetrics = orig }()
+
+	mux := setupTestServer("secret")
+	doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/status", "", "")
+	doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/status", "", "")
+	doRequest(mux, "GET", "/wpe-webkit-kiosk/api/v1/nope", "secret", "")
+
+	if n := apiMetrics.requests[requestKey{"/status", "GET", "401"}]; n != 2 {
+		t.Errorf("expected 2 unauthorized /status requests, got %d", n)
+	}
+	if n := apiMetrics.requests[requestKey{"unmatched", "GET", "404"}]; n != 1 {
+		t.Errorf("expected 1 unmatched request, got %d", n)
+	}
+	if h := apiMetrics.latency[latencyKey{"/status", "GET"}]; h == nil || h.count != 2 {
+		t.Errorf("expected 2 latency observations, got %+v", h)
+	}
+}
+
+func TestRequestMetrics_HistogramBuckets(t *testing.T) {
+	m := newRequestMetrics()
+	m.observe("/status", "GET", 200, 20*time.Millisecond)
+	m.observe("/status", "GET", 200, 3*time.Second)
+
+	var mw metricsWriter
+	writeRequestMetrics(&mw, m)
+	out := mw.buf.String()
+
+	for _, want := range []string{
+		`kiosk_api_requests_total{route="/status",method="GET",code="200"} 2`,
+		`kiosk_api_request_duration_seconds_bucket{route="/status",method="GET",le="0.01"} 0`,
+		`kiosk_api_request_duration_seconds_bucket{route="/status",method="GET",le="0.025"} 1`,
+		`kiosk_api_request_duration_seconds_bucket{route="/status",method="GET",le="5"} 2`,
+		`kiosk_api_request_duration_seconds_bucket{route="/status",method="GET",le="+Inf"} 2`,
+		`kiosk_api_request_duration_seconds_count{route="/status",method="GET"} 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestMetricsWriter_EscapesLabels(t *testing.T) {
+	var mw metricsWriter
+	mw.sample("m", []string{"l", "a\"b\\c\nd"}, 1.5)
+	if got, want := mw.buf.String(), `m{l="a\"b\\c\nd"} 1.5`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+)
+
+// errNoHistory is returned by page actions with nothing to go back or
+// forward to.
+var errNoHistory = errors.New("no page to go to in the history")
+
+// POST /back
+func handleBack(w http.ResponseWriter, r *http.Request) {
+	pageAction(w, r, "back", "went_back", func(p dbus.PageInfo) error {
+		if !p.CanGoBack {
+			return errNoHistory
+		}
+		return kiosk.GoBack(r.Context())
+	})
+}
+
+// POST /forward
+func handleForward(w http.ResponseWriter, r *http.Request) {
+	pageAction(w, r, "forward", "went_forward", func(p dbus.PageInfo) error {
+		if !p.CanGoForward {
+			return errNoHistory
+		}
+		return kiosk.GoForward(r.Context())
+	})
+}
+
+// POST /stop
+func handleStop(w http.ResponseWriter, r *http.Request) {
+	pageAction(w, r, "stop", "stopped", func(p dbus.PageInfo) error {
+		return kiosk.StopLoading(r.Context())
+	})
+}
+
+// pageAction runs an action on the current page, given its page info, and
+// records it in the audit log.
+func pageAction(w http.ResponseWriter, r *http.Request, action, status string, run func(dbus.PageInfo) error) {
+	page, err := kiosk.GetPageInfo(r.Context())
+	if err == nil {
+		err = run(page)
+	}
+	recordAudit(r, action, "", page.URI, nil, err)
+
+	switch {
+	case errors.Is(err, errNoHistory):
+		writeError(w, http.StatusConflict, "no_history", "There is no page to go "+action+" to")
+	case err != nil:
+		writeKioskError(w, err)
+	default:
+		writeJSON(w, http.StatusOK, map[string]string{"status": status})
+	}
+}
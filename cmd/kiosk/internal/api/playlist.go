@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/playlist"
+)
+
+// playlistPath is the playlist file managed by the handlers and followed
+// by the rotation driver.
+var playlistPath = playlist.DefaultPath
+
+// playlistMu serializes playlist read-modify-write cycles.
+var playlistMu sync.Mutex
+
+// GET /playlist
+func handlePlaylistGet(w http.ResponseWriter, r *http.Request) {
+	p, err := playlist.Load(playlistPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "playlist_error", err.Error())
+		return
+	}
+	writePlaylist(w, r, http.StatusOK, p)
+}
+
+// POST /playlist/entries
+func handlePlaylistAdd(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		URL      string `json:"url"`
+		Dwell    *int   `json:"dwell"`
+		Window   string `json:"window"`
+		Position int    `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_body", "Invalid JSON body")
+		return
+	}
+	if body.URL == "" {
+		writeError(w, http.StatusBadRequest, "invalid_body", "Field 'url' is required")
+		return
+	}
+	entry := playlist.Entry{URL: body.URL, Dwell: playlist.DefaultDwell, Window: body.Window}
+	if body.Dwell != nil {
+		entry.Dwell = *body.Dwell
+	}
+
+	playlistMu.Lock()
+	defer playlistMu.Unlock()
+
+	p, err := playlist.Load(playlistPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "playlist_error", err.Error())
+		return
+	}
+	if err := p.Add(entry, body.Position); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_value", err.Error())
+		return
+	}
+	if err := p.Save(); err != nil {
+		recordAudit(r, "playlist.add", entry.URL, nil, entry, err)
+		writeError(w, http.StatusInternalServerError, "playlist_error", err.Error())
+		return
+	}
+
+	recordAudit(r, "playlist.add", entry.URL, nil, entry, nil)
+	events.publish(eventPlaylist, map[string]any{"running": p.Running, "entries": len(p.Entries)})
+	writePlaylist(w, r, http.StatusCreated, p)
+}
+
+// DELETE /playlist/entries/{position}
+func handlePlaylistRemove(w http.ResponseWriter, r *http.Request) {
+	pos, err := strconv.Atoi(r.PathValue("position"))
+	if err != nil || pos < 1 {
+		writeError(w, http.StatusBadRequest, "invalid_path", "Position must be a positive integer")
+		return
+	}
+
+	playlistMu.Lock()
+	defer playlistMu.Unlock()
+
+	p, err := playlist.Load(playlistPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "playlist_error", err.Error())
+		return
+	}
+	entry, err := p.Remove(pos)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not_found", err.Error())
+		return
+	}
+	if err := p.Save(); err != nil {
+		recordAudit(r, "playlist.remove", entry.URL, entry, nil, err)
+		writeError(w, http.StatusInternalServerError, "playlist_error", err.Error())
+		return
+	}
+
+	recordAudit(r, "playlist.remove", entry.URL, entry, nil, nil)
+	events.publish(eventPlaylist, map[string]any{"running": p.Running, "entries": len(p.Entries)})
+	writePlaylist(w, r, http.StatusOK, p)
+}
+
+// POST /playlist/start
+func handlePlaylistStart(w http.ResponseWriter, r *http.Request) {
+	setPlaylistRunning(w, r, true)
+}
+
+// POST /playlist/stop
+func handlePlaylistStop(w http.ResponseWriter, r *http.Request) {
+	setPlaylistRunning(w, r, false)
+}
+
+func setPlaylistRunning(w http.ResponseWriter, r *http.Request, running bool) {
+	action := "playlist.stop"
+	if running {
+		action = "playlist.start"
+	}
+
+	playlistMu.Lock()
+	defer playlistMu.Unlock()
+
+	p, err := playlist.Load(playlistPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "playlist_error", err.Error())
+		return
+	}
+	if running && len(p.Entries) == 0 {
+		writeError(w, http.StatusConflict, "playlist_empty", "The playlist has no entries")
+		return
+	}
+	old := p.Running
+	p.Running = running
+	if err := p.Save(); err != nil {
+		recordAudit(r, action, "", old, running, err)
+		writeError(w, http.StatusInternalServerError, "playlist_error", err.Error())
+		return
+	}
+
+	recordAudit(r, action, "", old, running, nil)
+	events.publish(eventPlaylist, map[string]any{"running": p.Running, "entries": len(p.Entries)})
+	writePlaylist(w, r, http.StatusOK, p)
+}
+
+// newPlaylistDriver returns the driver that rotates the kiosk through the
+// playlist. Each page change is published as a navigation event.
+func newPlaylistDriver() *playlist.Driver {
+	d := playlist.NewDriver(playlistPath, func(url string) error {
+		return kiosk.Open(context.Background(), url)
+	})
+	d.OnAdvance = func(e playlist.Entry) {
+		events.publish(eventNavigation, map[string]string{"url": e.URL, "source": "playlist"})
+	}
+	return d
+}
+
+// writePlaylist responds with the playlist and the entries currently on
+// screen and up next.
+func writePlaylist(w http.ResponseWriter, r *http.Request, status int, p *playlist.Playlist) {
+	url, _ := kiosk.GetUrl(r.Context())
+	writeJSON(w, status, p.Status(url, time.Now()))
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/policy"
+)
+
+// policyResponse is returned by GET /policy. Decision is set when a URL
+// was given to test.
+type policyResponse struct {
+	*policy.Policy
+	Decision *policy.Decision `json:"decision,omitempty"`
+}
+
+// GET /policy
+func handlePolicy(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "config_error", err.Error())
+		return
+	}
+	p, err := policy.FromValues(cfg.Get)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "config_error", err.Error())
+		return
+	}
+
+	resp := policyResponse{Policy: p}
+	if u := r.URL.Query().Get("url"); u != "" {
+		d := p.Check(u)
+		resp.Decision = &d
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// checkPolicy decides whether a navigation requested over the API may
+// proceed. Blocked navigations are published as events; an unreadable
+// config leaves the decision to the kiosk, which enforces the same policy.
+func checkPolicy(cfg *config.Config, url string) policy.Decision {
+	d := policy.Decision{URL: url, Allowed: true}
+	if cfg == nil {
+		return d
+	}
+	p, err := policy.FromValues(cfg.Get)
+	if err != nil {
+		return d
+	}
+	if d = p.Check(url); !d.Allowed {
+		publishBlocked(url, d.Reason, "api")
+	}
+	return d
+}
+
+func publishBlocked(url, reason, source string) {
+	events.publish(eventBlocked, map[string]string{
+		"url":    url,
+		"reason": reason,
+		"source": source,
+	})
+}
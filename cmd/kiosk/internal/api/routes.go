@@ -1,25 +1,57 @@
 package api
 
-import "net/http"
+import (
+	"net/http"
 
-// registerRoutes sets up all API v1 routes with auth middleware.
-func registerRoutes(mux *http.ServeMux, token string) {
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/tokens"
+)
+
+// registerRoutes sets up all API v1 routes with auth middleware and per-route scopes.
+func registerRoutes(mux *http.ServeMux, auth *authenticator) {
 	v1 := http.NewServeMux()
 
-	v1.HandleFunc("GET /status", handleStatus)
-	v1.HandleFunc("POST /navigate", handleNavigate)
-	v1.HandleFunc("POST /reload", handleReload)
-	v1.HandleFunc("GET /config", handleConfigGet)
-	v1.HandleFunc("PUT /config", handleConfigSet)
-	v1.HandleFunc("POST /clear", handleClear)
-	v1.HandleFunc("GET /extensions", handleExtensionsList)
-	v1.HandleFunc("POST /extensions/{name}/enable", handleExtensionEnable)
-	v1.HandleFunc("POST /extensions/{name}/disable", handleExtensionDisable)
-	v1.HandleFunc("POST /restart", handleRestart)
-	v1.HandleFunc("GET /system", handleSystem)
+	v1.Handle("GET /status", requireScope(tokens.ScopeRead, handleStatus))
+	v1.Handle("POST /navigate", requireScope(tokens.ScopeNavigate, handleNavigate))
+	v1.Handle("POST /reload", requireScope(tokens.ScopeNavigate, handleReload))
+	v1.Handle("POST /back", requireScope(tokens.ScopeNavigate, handleBack))
+	v1.Handle("POST /forward", requireScope(tokens.ScopeNavigate, handleForward))
+	v1.Handle("POST /stop", requireScope(tokens.ScopeNavigate, handleStop))
+	v1.Handle("GET /config", requireScope(tokens.ScopeRead, handleConfigGet))
+	v1.Handle("PUT /config", requireScope(tokens.ScopeConfig, handleConfigSet))
+	v1.Handle("PATCH /config", requireScope(tokens.ScopeConfig, handleConfigPatch))
+	v1.Handle("GET /config/schema", requireScope(tokens.ScopeRead, handleConfigSchema))
+	v1.Handle("GET /config/history", requireScope(tokens.ScopeRead, handleConfigHistory))
+	v1.Handle("GET /config/history/{rev}/diff", requireScope(tokens.ScopeRead, handleConfigDiff))
+	v1.Handle("POST /config/history/{rev}/rollback", requireScope(tokens.ScopeConfig, handleConfigRollback))
+	v1.Handle("POST /clear", requireScope(tokens.ScopeNavigate, handleClear))
+	v1.Handle("GET /extensions", requireScope(tokens.ScopeRead, handleExtensionsList))
+	v1.Handle("POST /extensions/{name}/enable", requireScope(tokens.ScopeConfig, handleExtensionEnable))
+	v1.Handle("POST /extensions/{name}/disable", requireScope(tokens.ScopeConfig, handleExtensionDisable))
+	v1.Handle("GET /volume", requireScope(tokens.ScopeRead, handleVolumeGet))
+	v1.Handle("PUT /volume", requireScope(tokens.ScopeNavigate, handleVolumeSet))
+	v1.Handle("GET /playlist", requireScope(tokens.ScopeRead, handlePlaylistGet))
+	v1.Handle("POST /playlist/entries", requireScope(tokens.ScopeConfig, handlePlaylistAdd))
+	v1.Handle("DELETE /playlist/entries/{position}", requireScope(tokens.ScopeConfig, handlePlaylistRemove))
+	v1.Handle("POST /playlist/start", requireScope(tokens.ScopeConfig, handlePlaylistStart))
+	v1.Handle("POST /playlist/stop", requireScope(tokens.ScopeConfig, handlePlaylistStop))
+	v1.Handle("GET /screenshot", requireScope(tokens.ScopeRead, handleScreenshot))
+	v1.Handle("GET /policy", requireScope(tokens.ScopeRead, handlePolicy))
+	v1.Handle("GET /schedule", requireScope(tokens.ScopeRead, handleSchedule))
+	v1.Handle("POST /eval", requireScope(tokens.ScopeEval, handleEval))
+	v1.Handle("POST /restart", requireScope(tokens.ScopeAdmin, handleRestart))
+	v1.Handle("GET /system", requireScope(tokens.ScopeRead, handleSystem))
+	v1.Handle("GET /events", requireScope(tokens.ScopeRead, handleEvents))
+	v1.Handle("GET /logs", requireScope(tokens.ScopeRead, handleLogs))
+	v1.Handle("GET /logs/stream", requireScope(tokens.ScopeRead, handleLogsStream))
+	v1.Handle("POST /support-bundle", requireScope(tokens.ScopeAdmin, handleSupportBundle))
+	v1.Handle("GET /diagnostics", requireScope(tokens.ScopeRead, handleDiagnostics))
+	v1.Handle("GET /audit", requireScope(tokens.ScopeAdmin, handleAudit))
+
+	mux.Handle(apiPrefix+"/", http.StripPrefix(apiPrefix,
+		instrument(v1, authMiddleware(auth, v1))))
 
-	mux.Handle(apiPrefix+"/", authMiddleware(token,
-		http.StripPrefix(apiPrefix, v1)))
+	// Metrics endpoint — separate optional bearer token for scrapers
+	mux.Handle("GET "+apiPrefix+"/metrics", metricsAuth(auth, http.HandlerFunc(handleMetrics)))
 
 	// Docs endpoints — no auth required
 	mux.HandleFunc("GET "+apiPrefix+"/docs", handleDocs)
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/audio"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/audit"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/schedule"
+)
+
+const (
+	defaultScheduleCount = 10
+	maxScheduleCount     = 100
+)
+
+// schedulePath is the schedule file listed by GET /schedule and run by the
+// scheduler.
+var schedulePath = schedule.DefaultPath
+
+// GET /schedule
+func handleSchedule(w http.ResponseWriter, r *http.Request) {
+	count := defaultScheduleCount
+	if v := r.URL.Query().Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > maxScheduleCount {
+			writeError(w, http.StatusBadRequest, "invalid_query", "Parameter 'count' must be between 0 and 100")
+			return
+		}
+		count = n
+	}
+
+	s, err := schedule.Load(schedulePath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "schedule_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Listing(time.Now(), count))
+}
+
+// runScheduled performs a scheduled action. Actions are audited with
+// "schedule" as actor and publish the same events as their API counterparts.
+func runScheduled(e schedule.Entry) error {
+	var (
+		action, target string
+		newValue       any
+		err            error
+	)
+	switch e.Action {
+	case schedule.ActionNavigate:
+		action, newValue = "navigate", e.Arg
+		err = kiosk.Open(context.Background(), e.Arg)
+		if err == nil {
+			events.publish(eventNavigation, map[string]string{"url": e.Arg, "source": "schedule"})
+		}
+	case schedule.ActionRestart, schedule.ActionStop, schedule.ActionStart:
+		action, target = e.Action, kioskService
+		switch e.Action {
+		case schedule.ActionRestart:
+			err = services.Restart(context.Background(), kioskService)
+		case schedule.ActionStop:
+			err = services.Stop(context.Background(), kioskService)
+		default:
+			err = services.Start(context.Background(), kioskService)
+		}
+	case schedule.ActionMute:
+		action, err = "volume.mute", audio.Mute()
+	case schedule.ActionUnmute:
+		action, err = "volume.unmute", audio.Unmute()
+	case schedule.ActionClear:
+		action, target = "clear", e.Arg
+		if target == "" {
+			target = "all"
+		}
+		err = kiosk.ClearData(context.Background(), target)
+		if err == nil {
+			events.publish(eventClear, map[string]string{"scope": target, "source": "schedule"})
+		}
+	}
+
+	entry := audit.Entry{
+		Actor:  "schedule",
+		Source: audit.SourceSchedule,
+		Action: action,
+		Target: target,
+		New:    newValue,
+	}
+	if err != nil {
+		entry.Outcome = audit.OutcomeFailure
+		entry.Error = err.Error()
+	}
+	if auditErr := auditLog.Record(entry); auditErr != nil {
+		log.Printf("Audit: %v", auditErr)
+	}
+	return err
+}
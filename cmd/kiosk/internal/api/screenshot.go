@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/screenshot"
+)
+
+// GET /screenshot
+func handleScreenshot(w http.ResponseWriter, r *http.Request) {
+	var opts screenshot.Options
+	q := r.URL.Query()
+	if v := q.Get("scale"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_query", "Parameter 'scale' must be a number")
+			return
+		}
+		opts.Scale = f
+	}
+	if v := q.Get("quality"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "invalid_query", "Parameter 'quality' must be between 1 and 100")
+			return
+		}
+		opts.Quality = n
+	}
+	if err := opts.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+
+	png, err := kiosk.Screenshot(r.Context())
+	if err != nil {
+		writeKioskError(w, err)
+		return
+	}
+	data, err := screenshot.Encode(png, opts)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "screenshot_error", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", opts.ContentType())
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
@@ -1,19 +1,43 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/schedule"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/tokens"
 )
 
 const apiPrefix = "/wpe-webkit-kiosk/api/v1"
 
 // NewServer creates an HTTP server with versioned API routing and auth middleware.
-func NewServer(port, token string) *http.Server {
+// Requests are authenticated against the legacy token and the named tokens
+// in tokens.DefaultPath; /metrics is guarded by metricsToken if set. It also
+// starts the background state watcher and kiosk signal relay feeding
+// /events, the playlist rotation driver and the scheduler, which stop
+// together with the server.
+func NewServer(port, token, metricsToken string) *http.Server {
+	auth := newAuthenticator(token, tokens.DefaultPath)
+	auth.metricsToken = []byte(metricsToken)
+
 	mux := http.NewServeMux()
-	registerRoutes(mux, token)
+	registerRoutes(mux, auth)
 
-	return &http.Server{
+	srv := &http.Server{
 		Addr:    fmt.Sprintf("0.0.0.0:%s", port),
 		Handler: mux,
 	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go watchState(ctx)
+	go watchKiosk(ctx)
+	go newPlaylistDriver().Run(ctx)
+	go schedule.NewRunner(schedulePath, runScheduled).Run(ctx)
+	srv.RegisterOnShutdown(func() {
+		cancel()
+		events.closeAll()
+	})
+
+	return srv
 }
@@ -0,0 +1,89 @@
+// Package atomicfile replaces files so that a crash never leaves a partial
+// one behind.
+package atomicfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Write replaces path with data so that readers and a crash at any point
+// leave either the old or the new content, never a partial file. It writes
+// and syncs a temporary file in the same directory, then renames it over
+// path and syncs the directory. An existing file keeps its permission bits;
+// a new one gets perm. If the directory is not writable, the same steps run
+// through sudo (tee, chmod, sync, mv, sync) on path.tmp.
+func Write(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	mode := perm
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return writeSudo(path, data, perm)
+		}
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// Persist the rename itself.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+func writeSudo(path string, data []byte, perm os.FileMode) error {
+	tmpPath := path + ".tmp"
+
+	tee := exec.Command("sudo", "tee", tmpPath)
+	tee.Stdin = bytes.NewReader(data)
+	if err := tee.Run(); err != nil {
+		return fmt.Errorf("cannot write %s with sudo: %w", tmpPath, err)
+	}
+	chmod := exec.Command("sudo", "chmod", fmt.Sprintf("%04o", perm.Perm()), tmpPath)
+	if _, err := os.Stat(path); err == nil {
+		chmod = exec.Command("sudo", "chmod", "--reference="+path, tmpPath)
+	}
+	if err := chmod.Run(); err != nil {
+		return fmt.Errorf("cannot set the mode of %s with sudo: %w", tmpPath, err)
+	}
+	if err := exec.Command("sudo", "sync", tmpPath).Run(); err != nil {
+		return fmt.Errorf("cannot sync %s with sudo: %w", tmpPath, err)
+	}
+	if err := exec.Command("sudo", "mv", "-f", tmpPath, path).Run(); err != nil {
+		return fmt.Errorf("cannot replace %s with sudo: %w", path, err)
+	}
+	// Persist the rename itself.
+	if err := exec.Command("sudo", "sync", filepath.Dir(path)).Run(); err != nil {
+		return fmt.Errorf("cannot sync %s with sudo: %w", filepath.Dir(path), err)
+	}
+	return nil
+}
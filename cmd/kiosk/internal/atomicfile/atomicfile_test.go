@@ -0,0 +1,45 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteNewFileUsesPerm(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := Write(path, []byte("new\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "new\n" {
+		t.Errorf("unexpected content %q", data)
+	}
+	info, _ := os.Stat(path)
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("expected mode 0600, got %04o", mode)
+	}
+}
+
+func TestWriteKeepsExistingMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	if err := os.WriteFile(path, []byte("old\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(path, []byte("new\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "new\n" {
+		t.Errorf("unexpected content %q", data)
+	}
+	info, _ := os.Stat(path)
+	if mode := info.Mode().Perm(); mode != 0640 {
+		t.Errorf("expected mode 0640 kept, got %04o", mode)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary files left, got %d entries", len(entries))
+	}
+}
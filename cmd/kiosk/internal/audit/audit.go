@@ -0,0 +1,295 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultPath = "/var/log/wpe-webkit-kiosk/audit.log"
+
+const (
+	defaultMaxSize  = 10 << 20 // rotate after 10 MiB
+	defaultMaxFiles = 5        // keep audit.log.1 .. audit.log.5
+)
+
+// Outcomes recorded for an action.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Sources of an action.
+const (
+	SourceAPI      = "api"
+	SourceCLI      = "cli"
+	SourceTUI      = "tui"
+	SourceSchedule = "schedule"
+)
+
+// Entry is a single audit record, stored as one JSON line.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Actor   string    `json:"actor"`
+	Source  string    `json:"source"`
+	Remote  string    `json:"remote,omitempty"`
+	Action  string    `json:"action"`
+	Target  string    `json:"target,omitempty"`
+	Old     any       `json:"old,omitempty"`
+	New     any       `json:"new,omitempty"`
+	Outcome string    `json:"outcome"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Log is an append-only, size-rotated JSON lines audit log.
+type Log struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	mu       sync.Mutex
+}
+
+// New returns a Log writing to path with default rotation limits.
+func New(path string) *Log {
+	return &Log{path: path, maxSize: defaultMaxSize, maxFiles: defaultMaxFiles}
+}
+
+// Record appends an entry, rotating the file first if it grew too large.
+// If the log is not writable by the current user, the entry is handed to
+// SudoAppend instead, which records it with the user's real identity.
+func (l *Log) Record(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.Outcome == "" {
+		e.Outcome = OutcomeSuccess
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil && !errors.Is(err, os.ErrPermission) {
+		return fmt.Errorf("cannot create audit directory: %w", err)
+	}
+
+	if info, err := os.Stat(l.path); err == nil && info.Size()+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil && !errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("cannot rotate audit log: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return appendWithSudo(line)
+		}
+		return fmt.Errorf("cannot open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("cannot write audit log: %w", err)
+	}
+	return nil
+}
+
+func (l *Log) rotate() error {
+	os.Remove(l.rotatedPath(l.maxFiles))
+	for i := l.maxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(l.rotatedPath(i), l.rotatedPath(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(l.path, l.rotatedPath(1))
+}
+
+func (l *Log) rotatedPath(n int) string {
+	return fmt.Sprintf("%s.%d", l.path, n)
+}
+
+// SudoAppend is the command users who cannot write the log run to append
+// an entry to it: the entry is read as JSON from stdin and recorded by
+// AppendForCaller as root.
+var SudoAppend = []string{"sudo", "--non-interactive", "/usr/bin/kiosk", "audit", "append"}
+
+func appendWithSudo(line []byte) error {
+	cmd := exec.Command(SudoAppend[0], SudoAppend[1:]...)
+	cmd.Stdin = strings.NewReader(string(line))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return fmt.Errorf("cannot write audit log with sudo: %w", err)
+	}
+	return nil
+}
+
+// AppendForCaller records an entry of the local CLI or TUI read as JSON
+// from r, on behalf of the user running SudoAppend. It must run as root.
+// The time and actor are set here rather than taken from the entry, so a
+// user can only record actions under their own name.
+func (l *Log) AppendForCaller(r io.Reader) error {
+	if os.Geteuid() != 0 {
+		return errors.New("audit append must run as root (through sudo)")
+	}
+	var e Entry
+	if err := json.NewDecoder(io.LimitReader(r, 1<<20)).Decode(&e); err != nil {
+		return fmt.Errorf("invalid audit entry: %w", err)
+	}
+	if e.Source != SourceCLI && e.Source != SourceTUI {
+		return fmt.Errorf("invalid audit source %q", e.Source)
+	}
+	e.Time = time.Now().UTC()
+	e.Actor = LocalActor()
+	e.Remote = ""
+	return l.Record(e)
+}
+
+// Filter narrows down audit entries returned by Query.
+// Zero values match everything.
+type Filter struct {
+	Since  time.Time
+	Until  time.Time
+	Action string // exact action or dotted prefix ("extension" matches "extension.enable")
+	Actor  string
+	Limit  int // most recent N entries
+}
+
+func (f Filter) match(e Entry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+	if f.Action != "" && e.Action != f.Action && !strings.HasPrefix(e.Action, f.Action+".") {
+		return false
+	}
+	if f.Actor != "" && e.Actor != f.Actor {
+		return false
+	}
+	return true
+}
+
+// Query returns matching entries from the current and rotated files,
+// oldest first.
+func (l *Log) Query(f Filter) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var result []Entry
+	for i := l.maxFiles; i >= 0; i-- {
+		path := l.path
+		if i > 0 {
+			path = l.rotatedPath(i)
+		}
+		entries, err := readEntries(path, f)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, entries...)
+	}
+
+	if f.Limit > 0 && len(result) > f.Limit {
+		result = result[len(result)-f.Limit:]
+	}
+	if result == nil {
+		result = []Entry{}
+	}
+	return result, nil
+}
+
+func readEntries(path string, f Filter) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		if f.match(e) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// ParseTime parses a relative age ("90m", "24h", "7d" meaning that long
+// before now), a date ("2026-01-31") or an RFC 3339 timestamp.
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	if strings.HasSuffix(value, "d") {
+		var days int
+		if _, err := fmt.Sscanf(value, "%dd", &days); err == nil && days > 0 {
+			return now.Add(-time.Duration(days) * 24 * time.Hour), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use e.g. 24h, 7d, 2026-01-31 or RFC 3339)", value)
+}
+
+// LocalActor identifies the user running a local command. When invoked
+// through sudo the original user is reported. SUDO_USER is only trusted as
+// root: sudo sets it itself, while any user can set it for their own
+// commands.
+func LocalActor() string {
+	if u := os.Getenv("SUDO_USER"); u != "" && os.Geteuid() == 0 {
+		return u
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return fmt.Sprintf("uid:%d", os.Getuid())
+}
+
+// RecordLocal records an action performed by a local CLI or TUI user.
+// A non-nil actionErr marks the entry as failed.
+func RecordLocal(source, action, target string, oldValue, newValue any, actionErr error) error {
+	e := Entry{
+		Actor:  LocalActor(),
+		Source: source,
+		Action: action,
+		Target: target,
+		Old:    oldValue,
+		New:    newValue,
+	}
+	if actionErr != nil {
+		e.Outcome = OutcomeFailure
+		e.Error = actionErr.Error()
+	}
+	return New(DefaultPath).Record(e)
+}
@@ -0,0 +1,166 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLog(t *testing.T) *Log {
+	t.Helper()
+	return New(filepath.Join(t.TempDir(), "audit.log"))
+}
+
+func TestRecordAndQuery(t *testing.T) {
+	l := newTestLog(t)
+
+	if err := l.Record(Entry{Actor: "alice", Source: SourceCLI, Action: "navigate", New: "https://example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record(Entry{Actor: "support", Source: SourceAPI, Action: "config.set", Target: "URL",
+		Outcome: OutcomeFailure, Error: "permission denied"}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := l.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Actor != "alice" || entries[0].Outcome != OutcomeSuccess {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	if entries[0].Time.IsZero() {
+		t.Error("expected timestamp to be filled in")
+	}
+	if entries[1].Outcome != OutcomeFailure || entries[1].Error != "permission denied" {
+		t.Errorf("unexpected second entry: %+v", entries[1])
+	}
+}
+
+func TestQueryFilters(t *testing.T) {
+	l := newTestLog(t)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	l.Record(Entry{Time: base, Actor: "alice", Action: "navigate"})
+	l.Record(Entry{Time: base.Add(time.Hour), Actor: "bob", Action: "extension.enable"})
+	l.Record(Entry{Time: base.Add(2 * time.Hour), Actor: "alice", Action: "extension.disable"})
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"action prefix", Filter{Action: "extension"}, 2},
+		{"exact action", Filter{Action: "extension.enable"}, 1},
+		{"partial word is not a prefix", Filter{Action: "ext"}, 0},
+		{"actor", Filter{Actor: "alice"}, 2},
+		{"since", Filter{Since: base.Add(30 * time.Minute)}, 2},
+		{"until", Filter{Until: base.Add(30 * time.Minute)}, 1},
+		{"limit keeps most recent", Filter{Limit: 1}, 1},
+	}
+	for _, tt := range tests {
+		entries, err := l.Query(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != tt.want {
+			t.Errorf("%s: expected %d entries, got %d", tt.name, tt.want, len(entries))
+		}
+	}
+
+	entries, _ := l.Query(Filter{Limit: 1})
+	if entries[0].Action != "extension.disable" {
+		t.Errorf("expected most recent entry, got %+v", entries[0])
+	}
+}
+
+func TestRecordRotates(t *testing.T) {
+	l := newTestLog(t)
+	l.maxSize = 200
+	l.maxFiles = 2
+
+	for i := 0; i < 10; i++ {
+		if err := l.Record(Entry{Actor: "alice", Action: "reload"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := os.Stat(l.rotatedPath(1)); err != nil {
+		t.Errorf("expected rotated file: %v", err)
+	}
+	if _, err := os.Stat(l.rotatedPath(3)); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected no more than maxFiles rotated files")
+	}
+	if info, err := os.Stat(l.path); err != nil || info.Size() > l.maxSize {
+		t.Errorf("expected current file to stay under maxSize, got %v", info.Size())
+	}
+
+	entries, err := l.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || len(entries) >= 10 {
+		t.Errorf("expected rotation to drop oldest entries, got %d", len(entries))
+	}
+}
+
+func TestQueryMissingLog(t *testing.T) {
+	entries, err := newTestLog(t).Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no entries, got %d", len(entries))
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	if got, err := ParseTime("24h", now); err != nil || !got.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("ParseTime(24h) = %v, %v", got, err)
+	}
+	if got, err := ParseTime("7d", now); err != nil || !got.Equal(now.Add(-7*24*time.Hour)) {
+		t.Errorf("ParseTime(7d) = %v, %v", got, err)
+	}
+	if got, err := ParseTime("2026-03-01T08:00:00Z", now); err != nil || got.Hour() != 8 {
+		t.Errorf("ParseTime(RFC3339) = %v, %v", got, err)
+	}
+	if _, err := ParseTime("yesterday", now); err == nil {
+		t.Error("expected error for invalid time")
+	}
+}
+
+func TestAppendForCaller(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("AppendForCaller runs as root")
+	}
+	l := newTestLog(t)
+	t.Setenv("SUDO_USER", "alice")
+
+	forged := `{"time":"2020-01-01T00:00:00Z","actor":"bob","source":"cli","remote":"10.0.0.1","action":"config.set","target":"URL"}`
+	if err := l.AppendForCaller(strings.NewReader(forged)); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := l.Query(Filter{})
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.Actor != "alice" || e.Remote != "" || e.Time.Year() == 2020 || e.Action != "config.set" {
+		t.Errorf("expected the caller's identity and the current time, got %+v", e)
+	}
+
+	if err := l.AppendForCaller(strings.NewReader(`{"source":"api","action":"restart"}`)); err == nil {
+		t.Error("expected entries of other sources to be rejected")
+	}
+	if err := l.AppendForCaller(strings.NewReader(`not json`)); err == nil {
+		t.Error("expected invalid JSON to be rejected")
+	}
+}
@@ -0,0 +1,341 @@
+// Package bundle builds support bundles: a tar.gz archive with what is
+// needed to diagnose a kiosk remotely — its redacted config, service
+// states, recent logs, host telemetry, extensions, current page, package
+// versions and optionally a screenshot — plus a manifest of what was
+// collected and what failed.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/logs"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/sysinfo"
+)
+
+const (
+	// LogLines is how many of the most recent journal entries of each unit
+	// a bundle holds.
+	LogLines = logs.MaxLimit
+	// ItemTimeout bounds the time spent collecting a single item.
+	ItemTimeout = 15 * time.Second
+	// CollectTimeout bounds collecting a whole bundle, so a bundle served
+	// over the API is ready before the CLI stops waiting (2 minutes).
+	CollectTimeout = 90 * time.Second
+)
+
+// redacted replaces the values of secret config keys.
+const redacted = "********"
+
+// Packages are the Debian packages whose versions a bundle lists: the
+// kiosk and the compositor, VNC, audio and media stack it runs on.
+var Packages = []string{
+	"wpe-webkit-kiosk", "cage", "seatd", "wayvnc", "pipewire", "wireplumber",
+	"gstreamer1.0-plugins-bad", "libsoup-3.0-0", "libdrm2", "libinput10",
+}
+
+// dpkgQuery is the command package versions are read with.
+var dpkgQuery = "dpkg-query"
+
+// Options select what a bundle is collected from.
+type Options struct {
+	ConfigPath    string
+	ExtensionsDir string
+	Kiosk         dbus.Kiosk
+	Services      service.Manager
+	Screenshot    bool // include a PNG of the screen
+}
+
+// Item records the outcome of collecting one part of a bundle.
+type Item struct {
+	Name  string `json:"name"`
+	File  string `json:"file,omitempty"` // path in the archive, empty if it failed
+	Bytes int    `json:"bytes,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Manifest describes a bundle. It is the first file of the archive.
+type Manifest struct {
+	Created  time.Time `json:"created"`
+	Hostname string    `json:"hostname"`
+	Items    []Item    `json:"items"`
+}
+
+// Failed returns the items that could not be collected.
+func (m *Manifest) Failed() []Item {
+	var failed []Item
+	for _, it := range m.Items {
+		if it.Error != "" {
+			failed = append(failed, it)
+		}
+	}
+	return failed
+}
+
+// Bundle is a collected support bundle, ready to be written.
+type Bundle struct {
+	// Name is the directory the files are archived under, and the
+	// suggested file name without the ".tar.gz" extension.
+	Name     string
+	Manifest Manifest
+	files    map[string][]byte
+}
+
+// Collect gathers a bundle. Parts that cannot be collected are recorded as
+// failed in the manifest rather than failing the bundle; so are the parts
+// left when CollectTimeout runs out.
+func Collect(ctx context.Context, opts Options) *Bundle {
+	ctx, cancel := context.WithTimeout(ctx, CollectTimeout)
+	defer cancel()
+
+	host, _ := os.Hostname()
+	now := time.Now()
+	b := &Bundle{
+		Name:     "kiosk-support-" + fileSafe(host) + "-" + now.Format("20060102-150405"),
+		Manifest: Manifest{Created: now, Hostname: host, Items: []Item{}},
+		files:    map[string][]byte{},
+	}
+
+	b.add(ctx, "config", "config", func(context.Context) ([]byte, error) {
+		return redactedConfig(opts.ConfigPath)
+	})
+	for _, unit := range logs.Units {
+		b.add(ctx, "service "+unit, "services/"+unit+".json", func(ctx context.Context) ([]byte, error) {
+			st, err := opts.Services.Status(ctx, unit)
+			if err != nil {
+				return nil, err
+			}
+			return marshal(st)
+		})
+	}
+	for _, unit := range logs.Units {
+		b.add(ctx, "logs "+unit, "logs/"+unit+".log", func(ctx context.Context) ([]byte, error) {
+			return journal(ctx, unit)
+		})
+	}
+	b.add(ctx, "system", "system.json", func(context.Context) ([]byte, error) {
+		return marshal(sysinfo.Collect())
+	})
+	b.add(ctx, "extensions", "extensions.json", func(context.Context) ([]byte, error) {
+		return extensions(opts.ExtensionsDir)
+	})
+	b.add(ctx, "page", "page.json", func(ctx context.Context) ([]byte, error) {
+		page, err := opts.Kiosk.GetPageInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return marshal(page)
+	})
+	b.add(ctx, "packages", "packages.txt", packages)
+	if opts.Screenshot {
+		b.add(ctx, "screenshot", "screenshot.png", opts.Kiosk.Screenshot)
+	}
+	return b
+}
+
+// add collects an item into file, recording the outcome in the manifest.
+func (b *Bundle) add(ctx context.Context, name, file string, collect func(context.Context) ([]byte, error)) {
+	item := Item{Name: name}
+	var data []byte
+	err := ctx.Err()
+	if err != nil {
+		err = fmt.Errorf("not collected: %w", err)
+	} else {
+		itemCtx, cancel := context.WithTimeout(ctx, ItemTimeout)
+		data, err = collect(itemCtx)
+		cancel()
+	}
+	if err != nil {
+		item.Error = err.Error()
+	} else {
+		item.File, item.Bytes = file, len(data)
+		b.files[file] = data
+	}
+	b.Manifest.Items = append(b.Manifest.Items, item)
+}
+
+// Write writes the bundle to w as a gzip-compressed tar archive, with the
+// manifest first and every file under the bundle's Name.
+func (b *Bundle) Write(w io.Writer) error {
+	manifest, err := marshal(b.Manifest)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	write := func(name string, data []byte) error {
+		hdr := &tar.Header{
+			Name:    path.Join(b.Name, name),
+			Mode:    0o644,
+			Size:    int64(len(data)),
+			ModTime: b.Manifest.Created,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := write("manifest.json", manifest); err != nil {
+		return err
+	}
+	for _, it := range b.Manifest.Items {
+		if it.File == "" {
+			continue
+		}
+		if err := write(it.File, b.files[it.File]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// ReadManifest reads the manifest of a bundle archive.
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a support bundle: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("not a support bundle: %w", err)
+	}
+	if path.Base(hdr.Name) != "manifest.json" {
+		return nil, errors.New("not a support bundle: no manifest")
+	}
+	var m Manifest
+	if err := json.NewDecoder(tr).Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// redactedConfig returns the config file with the values of secret keys
+// replaced.
+func redactedConfig(configPath string) ([]byte, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	for _, e := range cfg.Entries {
+		line := e.Raw
+		if k, ok := config.LookupKey(e.Key); ok && k.Secret && e.Value != "" {
+			line = fmt.Sprintf("%s=\"%s\"", e.Key, redacted)
+		}
+		sb.WriteString(line + "\n")
+	}
+	return []byte(sb.String()), nil
+}
+
+// journal returns the most recent entries of unit, oldest first.
+func journal(ctx context.Context, unit string) ([]byte, error) {
+	page, err := logs.Query(ctx, logs.Filter{Units: []string{unit}, Limit: LogLines})
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	for _, e := range slices.Backward(page.Entries) {
+		sb.WriteString(e.String() + "\n")
+	}
+	return []byte(sb.String()), nil
+}
+
+// extension is an installed extension and its manifest.
+type extension struct {
+	DirName  string          `json:"dir_name"`
+	Enabled  bool            `json:"enabled"`
+	Manifest json.RawMessage `json:"manifest"`
+	Error    string          `json:"error,omitempty"` // why the manifest could not be read
+}
+
+// extensions lists the extensions installed in dir with their manifests.
+func extensions(dir string) ([]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	exts := []extension{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		ext := extension{DirName: entry.Name()}
+		_, disErr := os.Stat(filepath.Join(dir, entry.Name(), ".disabled"))
+		ext.Enabled = os.IsNotExist(disErr)
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name(), "manifest.json"))
+		switch {
+		case err != nil:
+			ext.Error = err.Error()
+		case !json.Valid(data):
+			ext.Error = "manifest.json is not valid JSON"
+		default:
+			ext.Manifest = data
+		}
+		exts = append(exts, ext)
+	}
+	return marshal(exts)
+}
+
+// packages lists the installed versions of Packages. Packages that are
+// not installed are left out.
+func packages(ctx context.Context) ([]byte, error) {
+	args := append([]string{"--show", "--showformat=${Package}\\t${Version}\\t${db:Status-Abbrev}\\n"}, Packages...)
+	cmd := exec.CommandContext(ctx, dpkgQuery, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	// dpkg-query fails if any package is unknown, but still lists the
+	// others.
+	if err != nil && len(out) == 0 {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("failed to list packages: %s", msg)
+		}
+		return nil, fmt.Errorf("failed to list packages: %w", err)
+	}
+	return out, nil
+}
+
+func marshal(v any) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// fileSafe makes s usable in a file name.
+func fileSafe(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, s)
+	if s == "" {
+		return "kiosk"
+	}
+	return s
+}
@@ -0,0 +1,228 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service/servicetest"
+)
+
+// stubKiosk answers the calls a bundle makes; the others are not used.
+type stubKiosk struct {
+	dbus.Kiosk
+	err error
+}
+
+func (k stubKiosk) GetPageInfo(context.Context) (dbus.PageInfo, error) {
+	return dbus.PageInfo{URI: "https://example.com/", Title: "Example"}, k.err
+}
+
+func (k stubKiosk) Screenshot(context.Context) ([]byte, error) {
+	return []byte("png"), k.err
+}
+
+// useDpkg replaces dpkg-query with a script printing output.
+func useDpkg(t *testing.T, output string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dpkg-query")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nprintf '"+output+"'\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	old := dpkgQuery
+	dpkgQuery = path
+	t.Cleanup(func() { dpkgQuery = old })
+}
+
+func testOptions(t *testing.T) Options {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	cfg := "# Kiosk\nURL=\"https://example.com\"\nAPI_TOKEN=\"s3cret\"\nMETRICS_TOKEN=\"\"\n"
+	if err := os.WriteFile(configPath, []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	extDir := filepath.Join(dir, "extensions")
+	for name, manifest := range map[string]string{
+		"idle":   `{"name": "Idle", "version": "1.0"}`,
+		"broken": `{`,
+	} {
+		os.MkdirAll(filepath.Join(extDir, name), 0o755)
+		os.WriteFile(filepath.Join(extDir, name, "manifest.json"), []byte(manifest), 0o644)
+	}
+	os.WriteFile(filepath.Join(extDir, "broken", ".disabled"), nil, 0o644)
+
+	return Options{
+		ConfigPath:    configPath,
+		ExtensionsDir: extDir,
+		Kiosk:         stubKiosk{},
+		Services:      servicetest.NewManager("wpe-webkit-kiosk"),
+	}
+}
+
+// readArchive returns the files of a bundle archive by name, in order.
+func readArchive(t *testing.T, data []byte) ([]string, map[string]string) {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	var names []string
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(tr)
+		names = append(names, hdr.Name)
+		files[hdr.Name] = string(content)
+	}
+	return names, files
+}
+
+func TestCollect(t *testing.T) {
+	useDpkg(t, `wpe-webkit-kiosk\t2.50.5\tii \n`)
+	opts := testOptions(t)
+	opts.Screenshot = true
+
+	b := Collect(context.Background(), opts)
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	names, files := readArchive(t, buf.Bytes())
+	if m, err := ReadManifest(bytes.NewReader(buf.Bytes())); err != nil || len(m.Items) != len(b.Manifest.Items) {
+		t.Errorf("ReadManifest: %v, %v", m, err)
+	}
+	dir := b.Name + "/"
+
+	if len(names) == 0 || names[0] != dir+"manifest.json" {
+		t.Fatalf("expected the manifest first, got %v", names)
+	}
+	var m Manifest
+	if err := json.Unmarshal([]byte(files[dir+"manifest.json"]), &m); err != nil {
+		t.Fatal(err)
+	}
+	for _, it := range m.Items {
+		if it.Error != "" || strings.HasPrefix(it.Name, "logs ") {
+			continue // the journal is not available everywhere
+		}
+		if _, ok := files[dir+it.File]; !ok {
+			t.Errorf("item %s: %s missing from the archive", it.Name, it.File)
+		}
+	}
+
+	config := files[dir+"config"]
+	if strings.Contains(config, "s3cret") || !strings.Contains(config, `API_TOKEN="********"`) {
+		t.Errorf("expected API_TOKEN redacted, got:\n%s", config)
+	}
+	if !strings.Contains(config, "# Kiosk\n") || !strings.Contains(config, `METRICS_TOKEN=""`) {
+		t.Errorf("expected comments and empty secrets kept, got:\n%s", config)
+	}
+
+	var svc struct{ State string }
+	json.Unmarshal([]byte(files[dir+"services/wpe-webkit-kiosk.json"]), &svc)
+	if svc.State != "active" {
+		t.Errorf("expected active kiosk service, got %q", svc.State)
+	}
+
+	var exts []extension
+	if err := json.Unmarshal([]byte(files[dir+"extensions.json"]), &exts); err != nil || len(exts) != 2 {
+		t.Fatalf("expected two extensions, got %v (%v)", exts, err)
+	}
+	for _, e := range exts {
+		switch e.DirName {
+		case "idle":
+			if !e.Enabled || e.Manifest == nil {
+				t.Errorf("unexpected idle extension: %+v", e)
+			}
+		case "broken":
+			if e.Enabled || e.Error == "" {
+				t.Errorf("unexpected broken extension: %+v", e)
+			}
+		}
+	}
+
+	if !strings.Contains(files[dir+"page.json"], "https://example.com/") {
+		t.Errorf("unexpected page: %s", files[dir+"page.json"])
+	}
+	if files[dir+"screenshot.png"] != "png" {
+		t.Error("expected the screenshot")
+	}
+	if !strings.HasPrefix(files[dir+"packages.txt"], "wpe-webkit-kiosk\t2.50.5") {
+		t.Errorf("unexpected packages: %q", files[dir+"packages.txt"])
+	}
+}
+
+func TestCollect_RecordsFailures(t *testing.T) {
+	useDpkg(t, "")
+	opts := testOptions(t)
+	opts.Kiosk = stubKiosk{err: dbus.ErrNotRunning}
+	opts.ConfigPath = filepath.Join(t.TempDir(), "missing")
+	services := servicetest.NewManager()
+	services.Fail("wpe-webkit-kiosk-vnc", errors.New("unit not loaded"))
+	opts.Services = services
+
+	b := Collect(context.Background(), opts)
+	failed := map[string]string{}
+	for _, it := range b.Manifest.Failed() {
+		if it.File != "" || it.Bytes != 0 {
+			t.Errorf("failed item %s has a file", it.Name)
+		}
+		failed[it.Name] = it.Error
+	}
+	for _, name := range []string{"config", "page", "service wpe-webkit-kiosk-vnc"} {
+		if failed[name] == "" {
+			t.Errorf("expected %s to fail, failures: %v", name, failed)
+		}
+	}
+	if _, ok := failed["screenshot"]; ok {
+		t.Error("screenshot was not requested")
+	}
+	if _, ok := failed["system"]; ok {
+		t.Error("expected system telemetry to be collected")
+	}
+}
+
+func TestCollect_StopsWhenContextEnds(t *testing.T) {
+	useDpkg(t, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	b := Collect(ctx, testOptions(t))
+	if len(b.Manifest.Items) == 0 {
+		t.Fatal("expected the skipped items in the manifest")
+	}
+	for _, it := range b.Manifest.Items {
+		if !strings.Contains(it.Error, "not collected") {
+			t.Errorf("expected %s not to be collected, got %+v", it.Name, it)
+		}
+	}
+}
+
+func TestFileSafe(t *testing.T) {
+	for in, want := range map[string]string{
+		"store-12":       "store-12",
+		"lobby kiosk/01": "lobby_kiosk_01",
+		"":               "kiosk",
+	} {
+		if got := fileSafe(in); got != want {
+			t.Errorf("fileSafe(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Default locations of the API server certificate and key.
+const (
+	DefaultCertPath = "/etc/wpe-webkit-kiosk/tls/server.crt"
+	DefaultKeyPath  = "/etc/wpe-webkit-kiosk/tls/server.key"
+)
+
+const selfSignedValidity = 10 * 365 * 24 * time.Hour
+
+// Info describes a certificate for display.
+type Info struct {
+	Subject     string
+	Issuer      string
+	DNSNames    []string
+	IPAddresses []string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	SelfSigned  bool
+	Fingerprint string // SHA-256, colon-separated hex
+}
+
+// GenerateSelfSigned creates a new ECDSA P-256 key and a self-signed
+// certificate for this host, writing them to certPath and keyPath.
+// The key file is readable by its owner only. Both files are written in
+// full before either is replaced, so a failure leaves the old pair in place.
+func GenerateSelfSigned(certPath, keyPath string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("cannot generate key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return fmt.Errorf("cannot generate serial number: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "wpe-webkit-kiosk"
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: hostname, Organization: []string{"WPE WebKit Kiosk"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{hostname, "localhost"},
+		IPAddresses:           localIPs(),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return fmt.Errorf("cannot create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("cannot encode key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(certPath), 0755); err != nil {
+		return fmt.Errorf("cannot create certificate directory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0755); err != nil {
+		return fmt.Errorf("cannot create key directory: %w", err)
+	}
+	keyTmp, err := writePEMTemp(keyPath, "EC PRIVATE KEY", keyDER, 0600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(keyTmp) // no-op once renamed
+	certTmp, err := writePEMTemp(certPath, "CERTIFICATE", der, 0644)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(certTmp)
+
+	// Replace the key first and the certificate last. If the certificate
+	// cannot be replaced, the old key is put back to match the old one.
+	oldKey, oldKeyErr := os.ReadFile(keyPath)
+	if err := os.Rename(keyTmp, keyPath); err != nil {
+		return fmt.Errorf("cannot replace %s: %w", keyPath, err)
+	}
+	if err := os.Rename(certTmp, certPath); err != nil {
+		if oldKeyErr == nil {
+			os.WriteFile(keyPath, oldKey, 0600)
+		} else {
+			os.Remove(keyPath)
+		}
+		return fmt.Errorf("cannot replace %s: %w", certPath, err)
+	}
+
+	// Persist the renames.
+	for _, dir := range []string{filepath.Dir(keyPath), filepath.Dir(certPath)} {
+		if d, err := os.Open(dir); err == nil {
+			d.Sync()
+			d.Close()
+		}
+	}
+	return nil
+}
+
+// EnsureSelfSigned generates a self-signed certificate if either file is
+// missing. It reports whether a new certificate was created.
+func EnsureSelfSigned(certPath, keyPath string) (bool, error) {
+	_, certErr := os.Stat(certPath)
+	_, keyErr := os.Stat(keyPath)
+	if certErr == nil && keyErr == nil {
+		return false, nil
+	}
+	for _, err := range []error{certErr, keyErr} {
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+	}
+	return true, GenerateSelfSigned(certPath, keyPath)
+}
+
+// Load reads and describes the first certificate in a PEM file.
+func Load(certPath string) (*Info, error) {
+	cert, err := readCertificate(certPath)
+	if err != nil {
+		return nil, err
+	}
+	info := &Info{
+		Subject:     cert.Subject.String(),
+		Issuer:      cert.Issuer.String(),
+		DNSNames:    cert.DNSNames,
+		NotBefore:   cert.NotBefore,
+		NotAfter:    cert.NotAfter,
+		SelfSigned:  cert.Subject.String() == cert.Issuer.String() && cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil,
+		Fingerprint: Fingerprint(cert.Raw),
+	}
+	for _, ip := range cert.IPAddresses {
+		info.IPAddresses = append(info.IPAddresses, ip.String())
+	}
+	return info, nil
+}
+
+// Fingerprint returns the colon-separated SHA-256 hash of DER bytes.
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// ServerConfig builds a TLS configuration for the API server. When
+// clientCAPath is set, client certificates are verified against that CA
+// bundle; requireClient makes presenting one mandatory.
+func ServerConfig(certPath, keyPath, clientCAPath string, requireClient bool) (*tls.Config, error) {
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load certificate: %w", err)
+	}
+
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{pair},
+	}
+
+	if clientCAPath != "" {
+		bundle, err := os.ReadFile(clientCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read client CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no certificates found in client CA bundle %s", clientCAPath)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+		if requireClient {
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return cfg, nil
+}
+
+func readCertificate(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read certificate: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM certificate found in %s", path)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// writePEMTemp writes and syncs a PEM block to a temporary file next to
+// path, to be renamed over it, and returns the temporary file's name.
+func writePEMTemp(path, blockType string, der []byte, perm os.FileMode) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	tmpPath := f.Name()
+	err = f.Chmod(perm)
+	if err == nil {
+		err = pem.Encode(f, &pem.Block{Type: blockType, Bytes: der})
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	return tmpPath, nil
+}
+
+func localIPs() []net.IP {
+	ips := []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ips
+	}
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && !ipnet.IP.IsLinkLocalUnicast() {
+			ips = append(ips, ipnet.IP)
+		}
+	}
+	return ips
+}
@@ -0,0 +1,124 @@
+package certs
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSelfSignedAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls", "server.crt")
+	keyPath := filepath.Join(dir, "tls", "server.key")
+
+	if err := GenerateSelfSigned(certPath, keyPath); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := Load(certPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.SelfSigned {
+		t.Error("expected certificate to be self-signed")
+	}
+	if len(strings.Split(info.Fingerprint, ":")) != 32 {
+		t.Errorf("expected 32-byte colon-separated fingerprint, got %q", info.Fingerprint)
+	}
+	if info.NotAfter.Before(info.NotBefore) {
+		t.Error("expected NotAfter after NotBefore")
+	}
+
+	st, err := os.Stat(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Mode().Perm() != 0600 {
+		t.Errorf("expected key mode 0600, got %v", st.Mode().Perm())
+	}
+
+	if _, err := tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+		t.Errorf("generated pair does not load: %v", err)
+	}
+}
+
+func TestGenerateSelfSignedFailureKeepsOldKey(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "server.key")
+	if err := GenerateSelfSigned(filepath.Join(dir, "server.crt"), keyPath); err != nil {
+		t.Fatal(err)
+	}
+	oldKey, _ := os.ReadFile(keyPath)
+
+	// A directory cannot be replaced by the new certificate.
+	blocked := filepath.Join(dir, "blocked.crt")
+	os.MkdirAll(filepath.Join(blocked, "keep"), 0755)
+	if err := GenerateSelfSigned(blocked, keyPath); err == nil {
+		t.Fatal("expected replacing the certificate to fail")
+	}
+	if key, _ := os.ReadFile(keyPath); string(key) != string(oldKey) {
+		t.Error("expected the old key to be kept")
+	}
+	if _, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.crt"), keyPath); err != nil {
+		t.Errorf("old pair no longer loads: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("temporary file %s left behind", e.Name())
+		}
+	}
+}
+
+func TestEnsureSelfSignedKeepsExisting(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "server.crt")
+	keyPath := filepath.Join(dir, "server.key")
+
+	created, err := EnsureSelfSigned(certPath, keyPath)
+	if err != nil || !created {
+		t.Fatalf("expected certificate to be created, got %v, %v", created, err)
+	}
+	first, _ := Load(certPath)
+
+	created, err = EnsureSelfSigned(certPath, keyPath)
+	if err != nil || created {
+		t.Fatalf("expected existing certificate to be kept, got %v, %v", created, err)
+	}
+	second, _ := Load(certPath)
+	if first.Fingerprint != second.Fingerprint {
+		t.Error("expected fingerprint to stay the same")
+	}
+}
+
+func TestServerConfigClientCA(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "server.crt")
+	keyPath := filepath.Join(dir, "server.key")
+	if err := GenerateSelfSigned(certPath, keyPath); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ServerConfig(certPath, keyPath, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ClientAuth != tls.NoClientCert {
+		t.Errorf("expected no client auth without CA, got %v", cfg.ClientAuth)
+	}
+
+	// Any PEM certificate works as a CA bundle for this test.
+	cfg, err = ServerConfig(certPath, keyPath, certPath, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ClientAuth != tls.RequireAndVerifyClientCert || cfg.ClientCAs == nil {
+		t.Errorf("expected required client certs, got %v", cfg.ClientAuth)
+	}
+
+	if _, err := ServerConfig(certPath, keyPath, keyPath, false); err == nil {
+		t.Error("expected error for CA bundle without certificates")
+	}
+}
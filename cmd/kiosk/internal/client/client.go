@@ -0,0 +1,513 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/doctor"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/playlist"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/policy"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/schedule"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/screenshot"
+)
+
+// APIPrefix is the path of the v1 REST API on a kiosk.
+const APIPrefix = "/wpe-webkit-kiosk/api/v1"
+
+const defaultTimeout = 15 * time.Second
+
+// Client talks to the REST API of a (usually remote) kiosk.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// Options configures how a Client connects.
+type Options struct {
+	// Insecure skips TLS certificate verification.
+	Insecure bool
+	// Fingerprint pins the server certificate by its SHA-256 fingerprint
+	// (colon-separated hex, as printed by "kiosk api cert show"). It replaces
+	// CA verification, which suits the kiosk's self-signed certificates.
+	Fingerprint string
+	// Timeout bounds each request. Zero means 15 seconds.
+	Timeout time.Duration
+}
+
+// New returns a client for the API at baseURL (e.g. "https://10.0.0.12:8100").
+func New(baseURL, token string, opts Options) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.Insecure || opts.Fingerprint != "" {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		if opts.Fingerprint != "" {
+			want := normalizeFingerprint(opts.Fingerprint)
+			transport.TLSClientConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+				if len(rawCerts) == 0 {
+					return fmt.Errorf("server presented no certificate")
+				}
+				sum := sha256.Sum256(rawCerts[0])
+				if got := hex.EncodeToString(sum[:]); got != want {
+					return fmt.Errorf("server certificate fingerprint mismatch")
+				}
+				return nil
+			}
+		}
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Transport: transport, Timeout: timeout},
+	}
+}
+
+func normalizeFingerprint(fp string) string {
+	return strings.ToLower(strings.NewReplacer(":", "", " ", "").Replace(fp))
+}
+
+// APIError is an error response returned by the kiosk API.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API error %d (%s)", e.Status, e.Code)
+	}
+	return e.Message
+}
+
+type envelope struct {
+	Data  json.RawMessage `json:"data"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// send sends a request with the API key and, if body is non-nil, a JSON
+// body. The caller closes the response body.
+func (c *Client) send(ctx context.Context, method, path string, header http.Header, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+APIPrefix+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("X-Api-Key", c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.http.Do(req)
+}
+
+// do sends a request and decodes the envelope's data into out (if non-nil).
+// It returns the response headers for callers that need them (e.g. ETag).
+func (c *Client) do(ctx context.Context, method, path string, header http.Header, body, out any) (http.Header, error) {
+	resp, err := c.send(ctx, method, path, header, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return resp.Header, fmt.Errorf("invalid response from %s (HTTP %d): %w", c.baseURL, resp.StatusCode, err)
+	}
+	if env.Error != nil || resp.StatusCode >= 400 {
+		return resp.Header, envelopeError(resp.StatusCode, &env)
+	}
+	if out != nil && len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, out); err != nil {
+			return resp.Header, fmt.Errorf("invalid response data: %w", err)
+		}
+	}
+	return resp.Header, nil
+}
+
+func envelopeError(status int, env *envelope) *APIError {
+	apiErr := &APIError{Status: status}
+	if env.Error != nil {
+		apiErr.Code, apiErr.Message = env.Error.Code, env.Error.Message
+	}
+	return apiErr
+}
+
+// Status is the kiosk service state returned by GET /status.
+type Status struct {
+	Service string  `json:"service"`
+	Uptime  *string `json:"uptime"`
+	URL     *string `json:"url"`
+	Page    *Page   `json:"page"`
+	Idle    *Idle   `json:"idle"`
+}
+
+// Page describes the page shown by the kiosk. Progress is the estimated
+// load progress from 0 to 1; LastError is the error of the last failed
+// load, cleared when a new load starts.
+type Page struct {
+	URI          string  `json:"uri"`
+	Title        string  `json:"title"`
+	Progress     float64 `json:"progress"`
+	Loading      bool    `json:"loading"`
+	CanGoBack    bool    `json:"can_go_back"`
+	CanGoForward bool    `json:"can_go_forward"`
+	LastError    string  `json:"last_error,omitempty"`
+}
+
+// Idle is the state of the kiosk's idle watchdog. Times are in seconds;
+// Timeout is 0 when the watchdog is disabled.
+type Idle struct {
+	Timeout int    `json:"timeout"`
+	Action  string `json:"action"`
+	Seconds int    `json:"seconds"`
+}
+
+// Status returns the kiosk service state and current URL.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var st Status
+	_, err := c.do(ctx, http.MethodGet, "/status", nil, nil, &st)
+	return &st, err
+}
+
+// Navigate opens url on the kiosk and saves it as the configured URL.
+func (c *Client) Navigate(ctx context.Context, url string) error {
+	_, err := c.do(ctx, http.MethodPost, "/navigate", nil, map[string]string{"url": url}, nil)
+	return err
+}
+
+// Reload reloads the current page.
+func (c *Client) Reload(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodPost, "/reload", nil, nil, nil)
+	return err
+}
+
+// Back goes to the previous page in the kiosk's history.
+func (c *Client) Back(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodPost, "/back", nil, nil, nil)
+	return err
+}
+
+// Forward goes to the next page in the kiosk's history.
+func (c *Client) Forward(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodPost, "/forward", nil, nil, nil)
+	return err
+}
+
+// Stop stops loading the current page.
+func (c *Client) Stop(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodPost, "/stop", nil, nil, nil)
+	return err
+}
+
+// Clear clears browsing data. Scope is "cache", "cookies" or "all".
+func (c *Client) Clear(ctx context.Context, scope string) error {
+	_, err := c.do(ctx, http.MethodPost, "/clear", nil, map[string]string{"scope": scope}, nil)
+	return err
+}
+
+// Restart restarts the kiosk service.
+func (c *Client) Restart(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodPost, "/restart", nil, nil, nil)
+	return err
+}
+
+// Config returns the configuration (without secret keys) and its ETag.
+func (c *Client) Config(ctx context.Context) (map[string]string, string, error) {
+	values := map[string]string{}
+	h, err := c.do(ctx, http.MethodGet, "/config", nil, nil, &values)
+	if err != nil {
+		return nil, "", err
+	}
+	return values, h.Get("ETag"), nil
+}
+
+// ConfigResult describes the outcome of a config update.
+type ConfigResult struct {
+	RestartRequired bool `json:"restart_required"`
+	Restarted       bool `json:"restarted"`
+}
+
+// SetConfig sets a single configuration value.
+func (c *Client) SetConfig(ctx context.Context, key, value string) (*ConfigResult, error) {
+	var res ConfigResult
+	_, err := c.do(ctx, http.MethodPut, "/config", nil, map[string]string{"key": key, "value": value}, &res)
+	return &res, err
+}
+
+// PatchConfig sets several values in one write. A non-empty ifMatch makes
+// the update fail with 412 if the config changed since that ETag; restart
+// restarts the service if any change needs it.
+func (c *Client) PatchConfig(ctx context.Context, values map[string]string, ifMatch string, restart bool) (*ConfigResult, error) {
+	path := "/config"
+	if restart {
+		path += "?restart=true"
+	}
+	var header http.Header
+	if ifMatch != "" {
+		header = http.Header{"If-Match": {ifMatch}}
+	}
+	var res ConfigResult
+	_, err := c.do(ctx, http.MethodPatch, path, header, values, &res)
+	return &res, err
+}
+
+// ConfigSchema returns the configuration schema of the kiosk.
+func (c *Client) ConfigSchema(ctx context.Context) ([]config.Key, error) {
+	var keys []config.Key
+	_, err := c.do(ctx, http.MethodGet, "/config/schema", nil, nil, &keys)
+	return keys, err
+}
+
+// Revision is a saved config version as listed by GET /config/history.
+type Revision struct {
+	Rev     int             `json:"rev"`
+	Time    time.Time       `json:"time"`
+	Current bool            `json:"current"`
+	Changes []config.Change `json:"changes"`
+}
+
+// ConfigHistory lists saved config versions, newest first.
+func (c *Client) ConfigHistory(ctx context.Context) ([]Revision, error) {
+	var revs []Revision
+	_, err := c.do(ctx, http.MethodGet, "/config/history", nil, nil, &revs)
+	return revs, err
+}
+
+// ConfigDiff returns the changes from revision rev to the current config.
+func (c *Client) ConfigDiff(ctx context.Context, rev int) ([]config.Change, error) {
+	var res struct {
+		Changes []config.Change `json:"changes"`
+	}
+	_, err := c.do(ctx, http.MethodGet, "/config/history/"+strconv.Itoa(rev)+"/diff", nil, nil, &res)
+	return res.Changes, err
+}
+
+// ConfigRollback restores revision rev and returns the applied changes.
+func (c *Client) ConfigRollback(ctx context.Context, rev int) ([]config.Change, *ConfigResult, error) {
+	var res struct {
+		Changes []config.Change `json:"changes"`
+		ConfigResult
+	}
+	_, err := c.do(ctx, http.MethodPost, "/config/history/"+strconv.Itoa(rev)+"/rollback", nil, nil, &res)
+	return res.Changes, &res.ConfigResult, err
+}
+
+// Extension is an installed extension as listed by GET /extensions.
+type Extension struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Enabled bool   `json:"enabled"`
+	DirName string `json:"dir_name"`
+}
+
+// Extensions lists installed extensions.
+func (c *Client) Extensions(ctx context.Context) ([]Extension, error) {
+	var exts []Extension
+	_, err := c.do(ctx, http.MethodGet, "/extensions", nil, nil, &exts)
+	return exts, err
+}
+
+// SetExtensionEnabled enables or disables the extension in directory dirName.
+func (c *Client) SetExtensionEnabled(ctx context.Context, dirName string, enabled bool) error {
+	action := "disable"
+	if enabled {
+		action = "enable"
+	}
+	_, err := c.do(ctx, http.MethodPost, "/extensions/"+url.PathEscape(dirName)+"/"+action, nil, nil, nil)
+	return err
+}
+
+// Volume is the audio state returned by GET /volume.
+type Volume struct {
+	Level int  `json:"level"`
+	Muted bool `json:"muted"`
+}
+
+// Volume returns the current audio volume.
+func (c *Client) Volume(ctx context.Context) (*Volume, error) {
+	var v Volume
+	_, err := c.do(ctx, http.MethodGet, "/volume", nil, nil, &v)
+	return &v, err
+}
+
+// SetVolume changes the level and/or mute state; nil leaves it unchanged.
+func (c *Client) SetVolume(ctx context.Context, level *int, muted *bool) (*Volume, error) {
+	body := map[string]any{}
+	if level != nil {
+		body["level"] = *level
+	}
+	if muted != nil {
+		body["muted"] = *muted
+	}
+	var v Volume
+	_, err := c.do(ctx, http.MethodPut, "/volume", nil, body, &v)
+	return &v, err
+}
+
+// Playlist returns the playlist with the entries on screen and up next.
+func (c *Client) Playlist(ctx context.Context) (*playlist.Status, error) {
+	var st playlist.Status
+	_, err := c.do(ctx, http.MethodGet, "/playlist", nil, nil, &st)
+	return &st, err
+}
+
+// AddPlaylistEntry inserts e at the 1-based position pos, or appends it if
+// pos is 0.
+func (c *Client) AddPlaylistEntry(ctx context.Context, e playlist.Entry, pos int) (*playlist.Status, error) {
+	body := map[string]any{"url": e.URL, "dwell": e.Dwell, "window": e.Window, "position": pos}
+	var st playlist.Status
+	_, err := c.do(ctx, http.MethodPost, "/playlist/entries", nil, body, &st)
+	return &st, err
+}
+
+// RemovePlaylistEntry deletes the entry at the 1-based position pos.
+func (c *Client) RemovePlaylistEntry(ctx context.Context, pos int) (*playlist.Status, error) {
+	var st playlist.Status
+	_, err := c.do(ctx, http.MethodDelete, "/playlist/entries/"+strconv.Itoa(pos), nil, nil, &st)
+	return &st, err
+}
+
+// SetPlaylistRunning starts or stops the rotation.
+func (c *Client) SetPlaylistRunning(ctx context.Context, running bool) (*playlist.Status, error) {
+	action := "stop"
+	if running {
+		action = "start"
+	}
+	var st playlist.Status
+	_, err := c.do(ctx, http.MethodPost, "/playlist/"+action, nil, nil, &st)
+	return &st, err
+}
+
+// Schedule returns the scheduled actions and their next count runs.
+func (c *Client) Schedule(ctx context.Context, count int) (*schedule.Listing, error) {
+	var l schedule.Listing
+	_, err := c.do(ctx, http.MethodGet, "/schedule?count="+strconv.Itoa(count), nil, nil, &l)
+	return &l, err
+}
+
+// Diagnostics runs the kiosk's self-diagnosis checks, all of them if no
+// IDs are given.
+func (c *Client) Diagnostics(ctx context.Context, checks []string) (*doctor.Report, error) {
+	path := "/diagnostics"
+	if len(checks) > 0 {
+		path += "?check=" + url.QueryEscape(strings.Join(checks, ","))
+	}
+	var r doctor.Report
+	_, err := c.do(ctx, http.MethodGet, path, nil, nil, &r)
+	return &r, err
+}
+
+// Policy is the navigation policy returned by GET /policy, with the
+// decision for the tested URL if one was given.
+type Policy struct {
+	policy.Policy
+	Decision *policy.Decision `json:"decision,omitempty"`
+}
+
+// Policy returns the navigation policy and, if rawURL is not empty, whether
+// the kiosk may open it.
+func (c *Client) Policy(ctx context.Context, rawURL string) (*Policy, error) {
+	path := "/policy"
+	if rawURL != "" {
+		path += "?url=" + url.QueryEscape(rawURL)
+	}
+	var p Policy
+	_, err := c.do(ctx, http.MethodGet, path, nil, nil, &p)
+	return &p, err
+}
+
+// Screenshot returns a snapshot of the kiosk's screen, encoded as
+// described by opts.
+func (c *Client) Screenshot(ctx context.Context, opts screenshot.Options) ([]byte, error) {
+	q := url.Values{}
+	if opts.Scale != 0 {
+		q.Set("scale", strconv.FormatFloat(opts.Scale, 'g', -1, 64))
+	}
+	if opts.Quality != 0 {
+		q.Set("quality", strconv.Itoa(opts.Quality))
+	}
+	path := "/screenshot"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
+	resp, err := c.send(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		var env envelope
+		json.NewDecoder(resp.Body).Decode(&env)
+		return nil, envelopeError(resp.StatusCode, &env)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// SupportBundle returns a support bundle of the kiosk as a tar.gz
+// archive, and its suggested file name.
+func (c *Client) SupportBundle(ctx context.Context, screenshot bool) ([]byte, string, error) {
+	resp, err := c.send(ctx, http.MethodPost, "/support-bundle", nil, map[string]bool{"screenshot": screenshot})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		var env envelope
+		json.NewDecoder(resp.Body).Decode(&env)
+		return nil, "", envelopeError(resp.StatusCode, &env)
+	}
+	var name string
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		name = filepath.Base(params["filename"])
+	}
+	data, err := io.ReadAll(resp.Body)
+	return data, name, err
+}
+
+// Evaluate runs script in the kiosk's page and returns its result as JSON.
+// timeout bounds the evaluation on the kiosk; zero uses the API default.
+func (c *Client) Evaluate(ctx context.Context, script string, timeout time.Duration) (json.RawMessage, error) {
+	body := map[string]any{"script": script}
+	if timeout > 0 {
+		body["timeout"] = int(timeout.Round(time.Second) / time.Second)
+	}
+	var out struct {
+		Result json.RawMessage `json:"result"`
+	}
+	_, err := c.do(ctx, http.MethodPost, "/eval", nil, body, &out)
+	return out.Result, err
+}
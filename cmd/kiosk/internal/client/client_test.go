@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/screenshot"
+)
+
+func apiServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func writeData(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"data": data, "error": nil})
+}
+
+func TestNavigateSendsTokenAndBody(t *testing.T) {
+	var gotKey, gotPath string
+	var body map[string]string
+	srv := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("X-Api-Key")
+		gotPath = r.Method + " " + r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+		writeData(w, http.StatusOK, map[string]string{"url": body["url"]})
+	})
+
+	c := New(srv.URL, "secret", Options{})
+	if err := c.Navigate(context.Background(), "https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if gotKey != "secret" {
+		t.Errorf("expected X-Api-Key 'secret', got %q", gotKey)
+	}
+	if gotPath != "POST "+APIPrefix+"/navigate" {
+		t.Errorf("unexpected request %q", gotPath)
+	}
+	if body["url"] != "https://example.com" {
+		t.Errorf("unexpected body %v", body)
+	}
+}
+
+func TestErrorEnvelopeBecomesAPIError(t *testing.T) {
+	srv := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"data":null,"error":{"code":"forbidden","message":"API key lacks required scope: admin"}}`))
+	})
+
+	err := New(srv.URL, "t", Options{}).Restart(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.Status != http.StatusForbidden || apiErr.Code != "forbidden" {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+	if apiErr.Error() != "API key lacks required scope: admin" {
+		t.Errorf("unexpected message %q", apiErr.Error())
+	}
+}
+
+func TestScreenshotReturnsImageBytes(t *testing.T) {
+	var gotQuery string
+	srv := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte("JPEGDATA"))
+	})
+
+	c := New(srv.URL, "t", Options{})
+	data, err := c.Screenshot(context.Background(), screenshot.Options{Scale: 0.5, Quality: 80})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "JPEGDATA" {
+		t.Errorf("unexpected data %q", data)
+	}
+	if gotQuery != "quality=80&scale=0.5" {
+		t.Errorf("unexpected query %q", gotQuery)
+	}
+}
+
+func TestSupportBundleReturnsArchiveAndName(t *testing.T) {
+	var body string
+	srv := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", `attachment; filename="kiosk-support-lobby-20260220-120000.tar.gz"`)
+		w.Write([]byte("TARGZ"))
+	})
+
+	c := New(srv.URL, "t", Options{})
+	data, name, err := c.SupportBundle(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "TARGZ" || name != "kiosk-support-lobby-20260220-120000.tar.gz" {
+		t.Errorf("unexpected bundle %q named %q", data, name)
+	}
+	if body != `{"screenshot":true}` {
+		t.Errorf("unexpected body %s", body)
+	}
+}
+
+func TestDiagnosticsSendsChecks(t *testing.T) {
+	var gotQuery string
+	srv := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("check")
+		writeData(w, http.StatusOK, map[string]any{
+			"status": "warn", "pass": 0, "warn": 1, "fail": 0,
+			"checks": []map[string]any{{"check": "tty", "status": "warn", "message": "getty", "hint": "disable it"}},
+		})
+	})
+
+	report, err := New(srv.URL, "t", Options{}).Diagnostics(context.Background(), []string{"tty", "ports"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotQuery != "tty,ports" {
+		t.Errorf("unexpected check query %q", gotQuery)
+	}
+	if report.Status != "warn" || len(report.Checks) != 1 || report.Checks[0].Hint != "disable it" {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestConfigReturnsETagAndPatchSendsIfMatch(t *testing.T) {
+	var ifMatch, query string
+	srv := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("ETag", `"abc"`)
+			writeData(w, http.StatusOK, map[string]string{"URL": "https://example.com"})
+		case http.MethodPatch:
+			ifMatch, query = r.Header.Get("If-Match"), r.URL.RawQuery
+			writeData(w, http.StatusOK, map[string]any{"restart_required": true, "restarted": true})
+		}
+	})
+
+	c := New(srv.URL, "t", Options{})
+	values, etag, err := c.Config(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["URL"] != "https://example.com" || etag != `"abc"` {
+		t.Errorf("unexpected config %v, etag %q", values, etag)
+	}
+
+	res, err := c.PatchConfig(context.Background(), map[string]string{"VNC_ENABLED": "true"}, etag, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ifMatch != `"abc"` || query != "restart=true" {
+		t.Errorf("expected If-Match and restart query, got %q, %q", ifMatch, query)
+	}
+	if !res.RestartRequired || !res.Restarted {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
+
+func TestSetVolumeOmitsUnsetFields(t *testing.T) {
+	var body map[string]any
+	srv := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		writeData(w, http.StatusOK, map[string]any{"level": 40, "muted": true})
+	})
+
+	muted := true
+	v, err := New(srv.URL, "t", Options{}).SetVolume(context.Background(), nil, &muted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := body["level"]; ok {
+		t.Errorf("expected level to be omitted, got %v", body)
+	}
+	if v.Level != 40 || !v.Muted {
+		t.Errorf("unexpected volume %+v", v)
+	}
+}
+
+func TestFingerprintPinning(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeData(w, http.StatusOK, map[string]string{"status": "reloaded"})
+	}))
+	defer srv.Close()
+
+	sum := sha256.Sum256(srv.Certificate().Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	if err := New(srv.URL, "t", Options{Fingerprint: fingerprint}).Reload(context.Background()); err != nil {
+		t.Errorf("expected pinned certificate to be accepted: %v", err)
+	}
+	wrong := "00" + fingerprint[2:]
+	if err := New(srv.URL, "t", Options{Fingerprint: wrong}).Reload(context.Background()); err == nil {
+		t.Error("expected mismatched fingerprint to be rejected")
+	}
+	if err := New(srv.URL, "t", Options{}).Reload(context.Background()); err == nil {
+		t.Error("expected self-signed certificate to be rejected without pinning")
+	}
+}
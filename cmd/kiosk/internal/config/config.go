@@ -2,36 +2,36 @@ package config
 
 import (
 	"bufio"
-	"errors"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"strings"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/atomicfile"
 )
 
 const DefaultPath = "/etc/wpe-webkit-kiosk/config"
 
-// LiveKeys can be applied at runtime without restarting the service.
-var LiveKeys = map[string]bool{
-	"URL": true,
-}
-
-// ValidKeys is the set of recognized configuration keys.
 // DefaultExtensionsDir is the default path where extensions are stored.
 const DefaultExtensionsDir = "/opt/wpe-webkit-kiosk/extensions"
 
+// LiveKeys can be applied at runtime without restarting the service.
+var LiveKeys = keySet(func(k Key) bool { return k.Live })
+
 // ValidKeys is the set of recognized configuration keys.
-var ValidKeys = map[string]bool{
-	"URL":                 true,
-	"INSPECTOR_PORT":      true,
-	"INSPECTOR_HTTP_PORT": true,
-	"VNC_ENABLED":         true,
-	"VNC_PORT":            true,
-	"CURSOR_VISIBLE":      true,
-	"EXTENSIONS_DIR":      true,
-	"TTY":                 true,
-	"API_PORT":            true,
-	"API_TOKEN":           true,
+var ValidKeys = keySet(func(Key) bool { return true })
+
+func keySet(match func(Key) bool) map[string]bool {
+	set := map[string]bool{}
+	for _, k := range Schema {
+		if match(k) {
+			set[k.Name] = true
+		}
+	}
+	return set
 }
 
 // Entry represents a single line in the config file.
@@ -55,8 +55,17 @@ func Load(path string) (*Config, error) {
 	}
 	defer f.Close()
 
+	cfg, err := parse(f)
+	if err != nil {
+		return nil, err
+	}
+	cfg.path = path
+	return cfg, nil
+}
+
+func parse(r io.Reader) (*Config, error) {
 	var entries []Entry
-	scanner := bufio.NewScanner(f)
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
 		entry := Entry{Raw: line}
@@ -75,17 +84,22 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("error reading config: %w", err)
 	}
 
-	return &Config{Entries: entries, path: path}, nil
+	return &Config{Entries: entries}, nil
 }
 
 // Get returns the value for a key, or empty string if not found.
 func (c *Config) Get(key string) string {
+	v, _ := c.lookup(key)
+	return v
+}
+
+func (c *Config) lookup(key string) (string, bool) {
 	for _, e := range c.Entries {
 		if e.Key == key {
-			return e.Value
+			return e.Value, true
 		}
 	}
-	return ""
+	return "", false
 }
 
 // Set updates or appends a key-value pair.
@@ -104,6 +118,17 @@ func (c *Config) Set(key, value string) {
 	})
 }
 
+// remove drops every line that sets key.
+func (c *Config) remove(key string) {
+	kept := c.Entries[:0]
+	for _, e := range c.Entries {
+		if e.Key != key {
+			kept = append(kept, e)
+		}
+	}
+	c.Entries = kept
+}
+
 // KeyValues returns all key-value pairs in order.
 func (c *Config) KeyValues() []Entry {
 	var kvs []Entry
@@ -120,22 +145,32 @@ func (c *Config) Save() error {
 	return c.SaveTo(c.path)
 }
 
-// SaveTo writes the config to the specified path.
-// Falls back to sudo tee if direct write fails with permission denied.
+// SaveTo writes the config to the specified path atomically and records
+// the new version in the path's history (see LoadHistory). History is best
+// effort: a failure to update it does not fail the save.
 func (c *Config) SaveTo(path string) error {
 	content := c.render()
+	previous, prevErr := os.ReadFile(path)
 
-	f, err := os.Create(path)
-	if err != nil {
-		if errors.Is(err, os.ErrPermission) {
-			return c.saveWithSudo(path, content)
-		}
+	if err := atomicfile.Write(path, []byte(content), 0644); err != nil {
 		return fmt.Errorf("cannot write config: %w", err)
 	}
-	defer f.Close()
 
-	_, err = f.WriteString(content)
-	return err
+	if h, err := LoadHistory(path); err == nil {
+		if len(h.Revisions) == 0 && prevErr == nil {
+			h.add(string(previous), time.Now())
+		}
+		if h.add(content, time.Now()) {
+			h.save()
+		}
+	}
+	return nil
+}
+
+// Version identifies the config content, for optimistic concurrency checks.
+func (c *Config) Version() string {
+	sum := sha256.Sum256([]byte(c.render()))
+	return hex.EncodeToString(sum[:8])
 }
 
 func (c *Config) render() string {
@@ -146,16 +181,6 @@ func (c *Config) render() string {
 	return b.String()
 }
 
-func (c *Config) saveWithSudo(path, content string) error {
-	cmd := exec.Command("sudo", "tee", path)
-	cmd.Stdin = strings.NewReader(content)
-	cmd.Stdout = nil
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("cannot write config with sudo: %w", err)
-	}
-	return nil
-}
-
 // NeedsRestart returns true if changing the given key requires a service restart.
 func NeedsRestart(key string) bool {
 	return !LiveKeys[key]
@@ -118,6 +118,32 @@ func TestSavePreservesComments(t *testing.T) {
 	}
 }
 
+func TestSaveKeepsFileMode(t *testing.T) {
+	path := writeTempConfig(t, testConfig)
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Set("URL", "https://changed.com")
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600 kept, got %04o", info.Mode().Perm())
+	}
+
+	newPath := filepath.Join(t.TempDir(), "config")
+	if err := cfg.SaveTo(newPath); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(newPath); info.Mode().Perm() != 0644 {
+		t.Errorf("expected a new file to get 0644, got %04o", info.Mode().Perm())
+	}
+}
+
 func TestNeedsRestart(t *testing.T) {
 	if NeedsRestart("URL") {
 		t.Error("URL should not need restart")
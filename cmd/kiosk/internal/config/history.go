@@ -0,0 +1,188 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/atomicfile"
+)
+
+// DefaultHistorySize is how many config versions are kept.
+const DefaultHistorySize = 20
+
+// Revision is a saved version of the config file.
+type Revision struct {
+	Rev     int       `json:"rev"`
+	Time    time.Time `json:"time"`
+	Content string    `json:"content"`
+}
+
+// History holds the saved versions of a config file, oldest first.
+type History struct {
+	Revisions []Revision `json:"revisions"`
+	path      string
+	size      int
+}
+
+// HistoryPath returns where the history of the config at configPath is kept.
+func HistoryPath(configPath string) string {
+	return configPath + ".history"
+}
+
+// LoadHistory reads the version history of the config at configPath.
+// A missing history file yields an empty history.
+func LoadHistory(configPath string) (*History, error) {
+	h := &History{path: HistoryPath(configPath), size: DefaultHistorySize}
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return h, nil
+		}
+		return nil, fmt.Errorf("cannot read config history: %w", err)
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("invalid config history: %w", err)
+	}
+	return h, nil
+}
+
+// Get returns the revision with the given number.
+func (h *History) Get(rev int) (*Revision, bool) {
+	for i := range h.Revisions {
+		if h.Revisions[i].Rev == rev {
+			return &h.Revisions[i], true
+		}
+	}
+	return nil, false
+}
+
+// Previous returns the revision saved before rev, if it is still kept.
+func (h *History) Previous(rev int) (*Revision, bool) {
+	for i := range h.Revisions {
+		if h.Revisions[i].Rev == rev && i > 0 {
+			return &h.Revisions[i-1], true
+		}
+	}
+	return nil, false
+}
+
+// add appends content as a new revision unless it matches the latest one,
+// dropping the oldest revisions beyond the history size. Secret values are
+// redacted, so the history never holds them.
+func (h *History) add(content string, now time.Time) bool {
+	content = RedactContent(content)
+	next := 1
+	if n := len(h.Revisions); n > 0 {
+		if h.Revisions[n-1].Content == content {
+			return false
+		}
+		next = h.Revisions[n-1].Rev + 1
+	}
+	h.Revisions = append(h.Revisions, Revision{Rev: next, Time: now.UTC(), Content: content})
+	if len(h.Revisions) > h.size {
+		h.Revisions = h.Revisions[len(h.Revisions)-h.size:]
+	}
+	return true
+}
+
+func (h *History) save() error {
+	// Also scrub revisions written before secrets were redacted.
+	for i := range h.Revisions {
+		h.Revisions[i].Content = RedactContent(h.Revisions[i].Content)
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if err := atomicfile.Write(h.path, data, 0644); err != nil {
+		return fmt.Errorf("cannot write config history: %w", err)
+	}
+	return nil
+}
+
+// Change kinds reported by Diff.
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// Change is a single key difference between two config versions.
+type Change struct {
+	Key  string `json:"key"`
+	Kind string `json:"kind"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// Diff compares two config file contents key by key. Changes are ordered
+// as the keys appear in newContent, followed by removed keys.
+func Diff(oldContent, newContent string) []Change {
+	oldCfg, _ := parse(strings.NewReader(oldContent))
+	newCfg, _ := parse(strings.NewReader(newContent))
+
+	var changes []Change
+	for _, kv := range newCfg.KeyValues() {
+		old, ok := oldCfg.lookup(kv.Key)
+		switch {
+		case !ok:
+			changes = append(changes, Change{Key: kv.Key, Kind: ChangeAdded, New: kv.Value})
+		case old != kv.Value:
+			changes = append(changes, Change{Key: kv.Key, Kind: ChangeModified, Old: old, New: kv.Value})
+		}
+	}
+	for _, kv := range oldCfg.KeyValues() {
+		if _, ok := newCfg.lookup(kv.Key); !ok {
+			changes = append(changes, Change{Key: kv.Key, Kind: ChangeRemoved, Old: kv.Value})
+		}
+	}
+	return changes
+}
+
+// Rollback restores the config at path to revision rev and returns the
+// applied changes. Secret keys (see Key.Secret) keep their current values;
+// the history only holds them redacted.
+// The restored state is saved as a new revision.
+func Rollback(path string, rev int) ([]Change, error) {
+	h, err := LoadHistory(path)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := h.Get(rev)
+	if !ok {
+		return nil, fmt.Errorf("config revision %d not found", rev)
+	}
+
+	current, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	target, err := parse(strings.NewReader(r.Content))
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range Schema {
+		if !k.Secret {
+			continue
+		}
+		if v, ok := current.lookup(k.Name); ok {
+			target.Set(k.Name, v)
+		} else if v, _ := target.lookup(k.Name); v == Redacted {
+			target.remove(k.Name)
+		}
+	}
+
+	changes := Diff(current.render(), target.render())
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if err := target.SaveTo(path); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
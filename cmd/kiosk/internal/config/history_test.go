@@ -0,0 +1,183 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveRecordsHistory(t *testing.T) {
+	path := writeTempConfig(t, testConfig)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Set("URL", "https://one.example")
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Set("URL", "https://two.example")
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	// Saving unchanged content does not add a revision.
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Revisions) != 3 {
+		t.Fatalf("expected original + 2 revisions, got %d", len(h.Revisions))
+	}
+	if h.Revisions[0].Content != testConfig {
+		t.Error("expected first revision to hold the original file")
+	}
+	if h.Revisions[2].Rev != 3 {
+		t.Errorf("expected latest rev 3, got %d", h.Revisions[2].Rev)
+	}
+}
+
+func TestSaveLeavesNoTempFiles(t *testing.T) {
+	path := writeTempConfig(t, testConfig)
+	cfg, _ := Load(path)
+	cfg.Set("TTY", "2")
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	for _, e := range entries {
+		if e.Name() != "config" && e.Name() != "config.history" {
+			t.Errorf("unexpected file left behind: %s", e.Name())
+		}
+	}
+	st, _ := os.Stat(path)
+	if st.Mode().Perm() != 0644 {
+		t.Errorf("expected mode 0644, got %v", st.Mode().Perm())
+	}
+}
+
+func TestHistoryKeepsLastN(t *testing.T) {
+	h := &History{size: 3}
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		h.add(string(rune('a'+i)), now)
+	}
+	if len(h.Revisions) != 3 || h.Revisions[0].Rev != 3 || h.Revisions[2].Rev != 5 {
+		t.Errorf("unexpected revisions: %+v", h.Revisions)
+	}
+	if _, ok := h.Previous(3); ok {
+		t.Error("expected no previous revision for the oldest kept")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	changes := Diff("A=\"1\"\nB=\"2\"\n", "A=\"1\"\nB=\"3\"\nC=\"4\"\n")
+	want := []Change{
+		{Key: "B", Kind: ChangeModified, Old: "2", New: "3"},
+		{Key: "C", Kind: ChangeAdded, New: "4"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d: got %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+
+	removed := Diff("A=\"1\"\n", "")
+	if len(removed) != 1 || removed[0].Kind != ChangeRemoved {
+		t.Errorf("expected removal, got %+v", removed)
+	}
+}
+
+func TestRollbackKeepsSecrets(t *testing.T) {
+	path := writeTempConfig(t, testConfig+"API_TOKEN=\"old\"\n")
+	cfg, _ := Load(path)
+	cfg.Set("URL", "https://changed.example")
+	cfg.Set("API_TOKEN", "new")
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := Rollback(path, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Key != "URL" || changes[0].New != "https://wpewebkit.org" {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+
+	restored, _ := Load(path)
+	if restored.Get("URL") != "https://wpewebkit.org" {
+		t.Errorf("expected URL to be rolled back, got %s", restored.Get("URL"))
+	}
+	if restored.Get("API_TOKEN") != "new" {
+		t.Errorf("expected API_TOKEN to keep its current value, got %s", restored.Get("API_TOKEN"))
+	}
+
+	h, _ := LoadHistory(path)
+	if len(h.Revisions) != 3 {
+		t.Errorf("expected rollback to add a revision, got %d", len(h.Revisions))
+	}
+
+	if _, err := Rollback(path, 99); err == nil {
+		t.Error("expected error for unknown revision")
+	}
+}
+
+func TestHistoryRedactsSecrets(t *testing.T) {
+	path := writeTempConfig(t, testConfig+"API_TOKEN=\"first-secret\"\n")
+	// A revision saved before secrets were redacted.
+	legacy := `{"revisions": [{"rev": 1, "content": "API_TOKEN=\"legacy-secret\"\n"}]}`
+	if err := os.WriteFile(HistoryPath(path), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _ := Load(path)
+	cfg.Set("API_TOKEN", "second-secret")
+	cfg.Set("URL", "https://changed.example")
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(HistoryPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"legacy-secret", "second-secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("history holds secret %q:\n%s", secret, data)
+		}
+	}
+	h, _ := LoadHistory(path)
+	latest := h.Revisions[len(h.Revisions)-1]
+	if !strings.Contains(latest.Content, `API_TOKEN="`+Redacted+`"`) {
+		t.Errorf("expected the token redacted in place, got:\n%s", latest.Content)
+	}
+
+	// Rolling back to a revision with a secret the config no longer has
+	// drops it rather than restoring the placeholder.
+	cfg, _ = Load(path)
+	cfg.remove("API_TOKEN")
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Rollback(path, latest.Rev); err != nil {
+		t.Fatal(err)
+	}
+	restored, _ := Load(path)
+	if _, ok := restored.lookup("API_TOKEN"); ok {
+		t.Errorf("expected no API_TOKEN after rollback, got %q", restored.Get("API_TOKEN"))
+	}
+	if restored.Get("URL") != "https://changed.example" {
+		t.Errorf("expected URL to be rolled back, got %s", restored.Get("URL"))
+	}
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/policy"
+)
+
+// Type is the kind of value a configuration key holds.
+type Type string
+
+const (
+	TypeString   Type = "string"
+	TypeURL      Type = "url"
+	TypePort     Type = "port"
+	TypeBool     Type = "bool"
+	TypeInt      Type = "int"
+	TypePath     Type = "path"
+	TypeEnum     Type = "enum"
+	TypePatterns Type = "patterns" // space-separated URL patterns (see internal/policy)
+)
+
+// Key describes a configuration key: its type, constraints and defaults.
+type Key struct {
+	Name        string   `json:"name"`
+	Type        Type     `json:"type"`
+	Default     string   `json:"default"`
+	Description string   `json:"description"`
+	Live        bool     `json:"live"`               // applied without a service restart
+	Optional    bool     `json:"optional,omitempty"` // empty value allowed
+	Secret      bool     `json:"secret,omitempty"`   // never exposed over the API
+	Min         int      `json:"min,omitempty"`      // TypeInt lower bound
+	Max         int      `json:"max,omitempty"`      // TypeInt upper bound
+	Values      []string `json:"values,omitempty"`   // TypeEnum choices
+}
+
+// Schema lists every recognized configuration key in display order.
+var Schema = []Key{
+	{Name: "URL", Type: TypeURL, Default: "https://wpewebkit.org", Description: "Page to display", Live: true},
+	{Name: "NAV_ALLOW", Type: TypePatterns, Description: "URL patterns the kiosk may open (empty = any)", Live: true, Optional: true},
+	{Name: "NAV_DENY", Type: TypePatterns, Description: "URL patterns the kiosk may never open", Live: true, Optional: true},
+	{Name: "INSPECTOR_PORT", Type: TypePort, Default: "8080", Description: "Remote Inspector port"},
+	{Name: "INSPECTOR_HTTP_PORT", Type: TypePort, Default: "8090", Description: "HTTP Inspector port"},
+	{Name: "VNC_ENABLED", Type: TypeBool, Default: "false", Description: "Enable VNC remote access"},
+	{Name: "VNC_PORT", Type: TypePort, Default: "5900", Description: "VNC listening port"},
+	{Name: "CURSOR_VISIBLE", Type: TypeBool, Default: "true", Description: "Show mouse cursor"},
+	{Name: "EXTENSIONS_DIR", Type: TypePath, Default: DefaultExtensionsDir, Description: "Extensions path"},
+	{Name: "IDLE_TIMEOUT", Type: TypeInt, Default: "0", Description: "Idle seconds before returning to URL (0 = off)", Max: 86400},
+	{Name: "IDLE_ACTION", Type: TypeEnum, Default: "home", Description: "What to do when idle", Values: []string{"home", "reset"}},
+	{Name: "TTY", Type: TypeInt, Default: "1", Description: "Virtual terminal", Min: 1, Max: 12},
+	{Name: "API_PORT", Type: TypePort, Default: "8100", Description: "REST API server port"},
+	{Name: "API_TOKEN", Type: TypeString, Description: "API authentication key", Optional: true, Secret: true},
+	{Name: "API_TLS_ENABLED", Type: TypeBool, Default: "false", Description: "Serve the REST API over HTTPS"},
+	{Name: "API_TLS_CERT", Type: TypePath, Default: "/etc/wpe-webkit-kiosk/tls/server.crt", Description: "API server certificate", Optional: true},
+	{Name: "API_TLS_KEY", Type: TypePath, Default: "/etc/wpe-webkit-kiosk/tls/server.key", Description: "API server private key", Optional: true},
+	{Name: "API_TLS_CLIENT_CA", Type: TypePath, Description: "CA bundle for client certificate verification", Optional: true},
+	{Name: "API_TLS_CLIENT_AUTH", Type: TypeEnum, Default: "optional", Description: "Whether client certificates are required", Optional: true, Values: []string{"optional", "required"}},
+	{Name: "METRICS_TOKEN", Type: TypeString, Description: "Bearer token for /metrics (empty = public)", Optional: true, Secret: true},
+}
+
+// LookupKey returns the schema entry for name.
+func LookupKey(name string) (Key, bool) {
+	for _, k := range Schema {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// Redacted replaces the values of secret keys wherever they are shown or
+// logged.
+const Redacted = "********"
+
+// Redact returns value, or Redacted if key is secret and value is set.
+func Redact(key, value string) string {
+	if k, ok := LookupKey(key); ok && k.Secret && value != "" {
+		return Redacted
+	}
+	return value
+}
+
+// RedactContent returns config file content with the values of secret
+// keys redacted, keeping everything else as it is.
+func RedactContent(content string) string {
+	cfg, err := parse(strings.NewReader(content))
+	if err != nil {
+		return ""
+	}
+	for i, e := range cfg.Entries {
+		if e.Key != "" && Redact(e.Key, e.Value) != e.Value {
+			cfg.Entries[i].Value = Redacted
+			cfg.Entries[i].Raw = fmt.Sprintf("%s=\"%s\"", e.Key, Redacted)
+		}
+	}
+	return cfg.render()
+}
+
+// RedactValues returns a copy of values with the secret ones redacted.
+func RedactValues(values map[string]string) map[string]string {
+	out := make(map[string]string, len(values))
+	for k, v := range values {
+		out[k] = Redact(k, v)
+	}
+	return out
+}
+
+// KeyNames returns the names of all recognized keys in schema order.
+func KeyNames() []string {
+	names := make([]string, len(Schema))
+	for i, k := range Schema {
+		names[i] = k.Name
+	}
+	return names
+}
+
+// ValidationError reports a value rejected by the schema.
+type ValidationError struct {
+	Key    string
+	Value  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid value %q for %s: %s", e.Value, e.Key, e.Reason)
+}
+
+// UnknownKeyError reports a key that is not in the schema.
+type UnknownKeyError struct {
+	Key string
+}
+
+func (e *UnknownKeyError) Error() string {
+	return fmt.Sprintf("unknown config key: %s (valid: %s)", e.Key, strings.Join(KeyNames(), ", "))
+}
+
+// Validate checks value against the schema entry for key. It returns an
+// *UnknownKeyError or *ValidationError on failure.
+func Validate(key, value string) error {
+	k, ok := LookupKey(key)
+	if !ok {
+		return &UnknownKeyError{Key: key}
+	}
+	if reason := k.check(value); reason != "" {
+		return &ValidationError{Key: key, Value: value, Reason: reason}
+	}
+	return nil
+}
+
+// check returns why value is invalid for k, or "" if it is valid.
+func (k Key) check(value string) string {
+	if strings.ContainsAny(value, "\"\n\r") {
+		return "must not contain quotes or line breaks"
+	}
+	if value == "" {
+		if k.Optional {
+			return ""
+		}
+		return "must not be empty"
+	}
+
+	switch k.Type {
+	case TypeURL:
+		u, err := url.Parse(value)
+		if err != nil {
+			return "must be a valid URL"
+		}
+		switch u.Scheme {
+		case "http", "https":
+			if u.Host == "" {
+				return "must include a host"
+			}
+		case "file", "about":
+		default:
+			return "must be an http, https, file or about URL"
+		}
+	case TypePort:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 65535 {
+			return "must be a port number between 1 and 65535"
+		}
+	case TypeBool:
+		if value != "true" && value != "false" {
+			return "must be true or false"
+		}
+	case TypeInt:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < k.Min || n > k.Max {
+			return fmt.Sprintf("must be an integer between %d and %d", k.Min, k.Max)
+		}
+	case TypePath:
+		if !filepath.IsAbs(value) {
+			return "must be an absolute path"
+		}
+	case TypeEnum:
+		for _, v := range k.Values {
+			if value == v {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(k.Values, ", ")
+	case TypePatterns:
+		for _, p := range strings.Fields(value) {
+			if err := policy.CheckPattern(p); err != nil {
+				return err.Error()
+			}
+		}
+	}
+	return ""
+}
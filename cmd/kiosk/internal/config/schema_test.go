@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		key, value string
+		ok         bool
+	}{
+		{"URL", "https://example.com/page", true},
+		{"URL", "file:///opt/app/index.html", true},
+		{"URL", "example.com", false},
+		{"URL", "https://", false},
+		{"URL", "", false},
+		{"API_PORT", "8100", true},
+		{"API_PORT", "99999", false},
+		{"API_PORT", "0", false},
+		{"VNC_ENABLED", "true", true},
+		{"VNC_ENABLED", "yes", false},
+		{"TTY", "12", true},
+		{"TTY", "13", false},
+		{"TTY", "banana", false},
+		{"NAV_ALLOW", "", true},
+		{"NAV_ALLOW", "example.com https://*.example.org/kiosk/*", true},
+		{"NAV_DENY", "://example.com", false},
+		{"IDLE_TIMEOUT", "0", true},
+		{"IDLE_TIMEOUT", "-1", false},
+		{"IDLE_ACTION", "reset", true},
+		{"IDLE_ACTION", "logout", false},
+		{"EXTENSIONS_DIR", "/opt/ext", true},
+		{"EXTENSIONS_DIR", "ext", false},
+		{"API_TLS_CLIENT_CA", "", true},
+		{"API_TLS_CLIENT_AUTH", "required", true},
+		{"API_TLS_CLIENT_AUTH", "always", false},
+		{"METRICS_TOKEN", `ab"c`, false},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.key, tt.value)
+		if tt.ok && err != nil {
+			t.Errorf("Validate(%s, %q): unexpected error: %v", tt.key, tt.value, err)
+		}
+		if !tt.ok {
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Errorf("Validate(%s, %q): expected ValidationError, got %v", tt.key, tt.value, err)
+			}
+		}
+	}
+}
+
+func TestValidateUnknownKey(t *testing.T) {
+	err := Validate("NOPE", "x")
+	var unknown *UnknownKeyError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("expected UnknownKeyError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "TTY") {
+		t.Errorf("expected valid keys in message, got %q", err.Error())
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := Validate("TTY", "banana")
+	want := `invalid value "banana" for TTY: must be an integer between 1 and 12`
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
+	}
+}
+
+func TestSchemaDefaultsAreValid(t *testing.T) {
+	for _, k := range Schema {
+		if err := Validate(k.Name, k.Default); err != nil {
+			t.Errorf("default for %s is invalid: %v", k.Name, err)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	if got := Redact("API_TOKEN", "s3cret"); got != Redacted {
+		t.Errorf("expected API_TOKEN redacted, got %q", got)
+	}
+	if got := Redact("API_TOKEN", ""); got != "" {
+		t.Errorf("expected an empty secret kept empty, got %q", got)
+	}
+	values := map[string]string{"METRICS_TOKEN": "s3cret", "TTY": "7"}
+	if got := RedactValues(values); got["METRICS_TOKEN"] != Redacted || got["TTY"] != "7" || values["METRICS_TOKEN"] != "s3cret" {
+		t.Errorf("unexpected redaction %v of %v", got, values)
+	}
+}
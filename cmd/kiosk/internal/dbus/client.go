@@ -1,7 +1,13 @@
 package dbus
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/godbus/dbus/v5"
 )
@@ -12,60 +18,356 @@ const (
 	interfaceName = "com.wpe.Kiosk"
 )
 
-// Client communicates with the WPE Kiosk D-Bus interface.
+// DefaultTimeout bounds calls whose context has no deadline, so a hung
+// launcher cannot block the caller forever.
+const DefaultTimeout = 5 * time.Second
+
+// Errors returned by Client methods, whatever the method.
+var (
+	// ErrNotRunning is returned when the kiosk is not on the bus.
+	ErrNotRunning = errors.New("kiosk service is not running")
+	// ErrTimeout is returned when the kiosk does not answer in time.
+	ErrTimeout = errors.New("kiosk did not respond in time")
+	// ErrAccessDenied is returned when the bus policy refuses the call,
+	// as it does EvaluateScript and Screenshot for users other than root.
+	ErrAccessDenied = errors.New("access denied by the D-Bus policy (run with sudo)")
+	// ErrInvalidScope is returned by ClearData for an unknown scope.
+	ErrInvalidScope = errors.New("scope must be 'cache', 'cookies', or 'all'")
+)
+
+// Kiosk is the com.wpe.Kiosk interface of the launcher. *Client
+// implements it; tests can substitute their own implementation.
+type Kiosk interface {
+	Open(ctx context.Context, url string) error
+	Reload(ctx context.Context) error
+	GoBack(ctx context.Context) error
+	GoForward(ctx context.Context) error
+	StopLoading(ctx context.Context) error
+	GetUrl(ctx context.Context) (string, error)
+	GetPageInfo(ctx context.Context) (PageInfo, error)
+	ClearData(ctx context.Context, scope string) error
+	Evaluate(ctx context.Context, script string) (json.RawMessage, error)
+	Screenshot(ctx context.Context) ([]byte, error)
+	GetStats(ctx context.Context) (Stats, error)
+	GetBlocked(ctx context.Context) (uint32, []BlockedNavigation, error)
+	GetIdle(ctx context.Context) (Idle, error)
+	Subscribe(ctx context.Context) (<-chan Event, error)
+}
+
+var _ Kiosk = (*Client)(nil)
+
+// Client communicates with the WPE Kiosk D-Bus interface. It connects to
+// the system bus on first use and reconnects after the connection is
+// lost, so a single Client can serve a whole process; see Default. Calls
+// go to the bus name, so they reach the kiosk again once it has been
+// restarted.
 type Client struct {
+	// Timeout bounds calls whose context has no deadline.
+	Timeout time.Duration
+
+	connect func() (*dbus.Conn, error)
+
+	mu   sync.Mutex
 	conn *dbus.Conn
-	obj  dbus.BusObject
 }
 
-// NewClient connects to the system bus and returns a Client.
-func NewClient() (*Client, error) {
-	conn, err := dbus.SystemBus()
+// AddressEnv names the environment variable that points Default at a bus
+// other than the system bus, such as the private bus of a test harness.
+const AddressEnv = "KIOSK_DBUS_ADDRESS"
+
+// NewClient returns a Client for the kiosk on the system bus.
+func NewClient() *Client {
+	return &Client{Timeout: DefaultTimeout, connect: func() (*dbus.Conn, error) {
+		return dbus.ConnectSystemBus()
+	}}
+}
+
+// NewClientAt returns a Client for the kiosk on the bus at address, in
+// D-Bus address format (e.g. "unix:path=/run/kiosk/bus").
+func NewClientAt(address string) *Client {
+	return &Client{Timeout: DefaultTimeout, connect: func() (*dbus.Conn, error) {
+		return dbus.Connect(address)
+	}}
+}
+
+var defaultClient = sync.OnceValue(func() *Client {
+	if address := os.Getenv(AddressEnv); address != "" {
+		return NewClientAt(address)
+	}
+	return NewClient()
+})
+
+// Default returns the Client shared by the whole process. It uses the bus
+// named by $KIOSK_DBUS_ADDRESS if set, and the system bus otherwise.
+func Default() *Client {
+	return defaultClient()
+}
+
+// Close closes the client's bus connection. A later call reconnects.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// connection returns the current bus connection, connecting first if
+// there is none or the previous one was lost.
+func (c *Client) connection() (*dbus.Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil && c.conn.Connected() {
+		return c.conn, nil
+	}
+	conn, err := c.connect()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
+		return nil, fmt.Errorf("failed to connect to D-Bus: %w", err)
+	}
+	c.conn = conn
+	return conn, nil
+}
+
+// call invokes method on the kiosk, applying the client timeout if ctx
+// has no deadline. A call that fails because the connection was closed
+// is retried once on a new connection.
+func (c *Client) call(ctx context.Context, method string, args ...any) (*dbus.Call, error) {
+	if _, ok := ctx.Deadline(); !ok && c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	var call *dbus.Call
+	for attempt := 0; attempt < 2; attempt++ {
+		conn, err := c.connection()
+		if err != nil {
+			return nil, err
+		}
+		call = conn.Object(busName, objectPath).CallWithContext(ctx, interfaceName+"."+method, 0, args...)
+		if !errors.Is(call.Err, dbus.ErrClosed) {
+			break
+		}
+	}
+	if call.Err != nil && ctx.Err() != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrTimeout
+		}
+		return nil, ctx.Err()
+	}
+	if err := wrapCallError(call, method); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+// store reads the reply of a successful call into out.
+func store(call *dbus.Call, method string, out ...any) error {
+	if err := call.Store(out...); err != nil {
+		return fmt.Errorf("failed to read %s response: %w", method, err)
 	}
-	obj := conn.Object(busName, objectPath)
-	return &Client{conn: conn, obj: obj}, nil
+	return nil
 }
 
 // Open navigates the kiosk to the given URL.
-func (c *Client) Open(url string) error {
-	call := c.obj.Call(interfaceName+".Open", 0, url)
-	return wrapCallError(call, "Open")
+func (c *Client) Open(ctx context.Context, url string) error {
+	_, err := c.call(ctx, "Open", url)
+	return err
 }
 
 // Reload reloads the current page.
-func (c *Client) Reload() error {
-	call := c.obj.Call(interfaceName+".Reload", 0)
-	return wrapCallError(call, "Reload")
+func (c *Client) Reload(ctx context.Context) error {
+	_, err := c.call(ctx, "Reload")
+	return err
 }
 
 // GetUrl returns the currently loaded URL.
-func (c *Client) GetUrl() (string, error) {
+func (c *Client) GetUrl(ctx context.Context) (string, error) {
 	var url string
-	call := c.obj.Call(interfaceName+".GetUrl", 0)
-	if call.Err != nil {
-		return "", wrapCallError(call, "GetUrl")
+	call, err := c.call(ctx, "GetUrl")
+	if err != nil {
+		return "", err
 	}
-	if err := call.Store(&url); err != nil {
-		return "", fmt.Errorf("failed to read GetUrl response: %w", err)
+	if err := store(call, "GetUrl", &url); err != nil {
+		return "", err
 	}
 	return url, nil
 }
 
-// ClearData clears browser data. Scope must be "cache", "cookies", or "all".
-func (c *Client) ClearData(scope string) error {
-	call := c.obj.Call(interfaceName+".ClearData", 0, scope)
-	return wrapCallError(call, "ClearData")
+// GoBack navigates to the previous page in the history.
+func (c *Client) GoBack(ctx context.Context) error {
+	_, err := c.call(ctx, "GoBack")
+	return err
+}
+
+// GoForward navigates to the next page in the history.
+func (c *Client) GoForward(ctx context.Context) error {
+	_, err := c.call(ctx, "GoForward")
+	return err
+}
+
+// StopLoading stops loading the current page.
+func (c *Client) StopLoading(ctx context.Context) error {
+	_, err := c.call(ctx, "StopLoading")
+	return err
+}
+
+// PageInfo describes the page shown by the kiosk.
+type PageInfo struct {
+	URI          string  `json:"uri"`
+	Title        string  `json:"title"`
+	Progress     float64 `json:"progress"` // estimated load progress, 0 to 1
+	Loading      bool    `json:"loading"`
+	CanGoBack    bool    `json:"can_go_back"`
+	CanGoForward bool    `json:"can_go_forward"`
+	LastError    string  `json:"last_error,omitempty"` // cleared when a load starts
+}
+
+// GetPageInfo returns the current page, its load state and history.
+func (c *Client) GetPageInfo(ctx context.Context) (PageInfo, error) {
+	var p PageInfo
+	call, err := c.call(ctx, "GetPageInfo")
+	if err != nil {
+		return p, err
+	}
+	if err := store(call, "GetPageInfo", &p.URI, &p.Title, &p.Progress, &p.Loading,
+		&p.CanGoBack, &p.CanGoForward, &p.LastError); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// ClearData clears browser data. Scope must be "cache", "cookies", or "all";
+// other scopes fail with ErrInvalidScope.
+func (c *Client) ClearData(ctx context.Context, scope string) error {
+	_, err := c.call(ctx, "ClearData", scope)
+	return err
+}
+
+// ScriptError is an exception thrown by a script run with Evaluate.
+type ScriptError struct {
+	Message string
+}
+
+func (e *ScriptError) Error() string { return "script error: " + e.Message }
+
+// Evaluate runs script in the page and returns its result as JSON
+// ("null" for undefined). Exceptions are returned as *ScriptError. The
+// call is abandoned when ctx is done; the script itself keeps running.
+func (c *Client) Evaluate(ctx context.Context, script string) (json.RawMessage, error) {
+	var result string
+	call, err := c.call(ctx, "EvaluateScript", script)
+	if err != nil {
+		return nil, err
+	}
+	if err := store(call, "EvaluateScript", &result); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(result), nil
+}
+
+// Screenshot returns a PNG snapshot of the visible part of the page.
+func (c *Client) Screenshot(ctx context.Context) ([]byte, error) {
+	var png []byte
+	call, err := c.call(ctx, "Screenshot")
+	if err != nil {
+		return nil, err
+	}
+	if err := store(call, "Screenshot", &png); err != nil {
+		return nil, err
+	}
+	return png, nil
+}
+
+// Stats holds counters kept by the kiosk since it started.
+type Stats struct {
+	PageLoads         uint32
+	WebProcessCrashes uint32
+}
+
+// GetStats returns the page load and web process crash counters.
+func (c *Client) GetStats(ctx context.Context) (Stats, error) {
+	var st Stats
+	call, err := c.call(ctx, "GetStats")
+	if err != nil {
+		return st, err
+	}
+	if err := store(call, "GetStats", &st.PageLoads, &st.WebProcessCrashes); err != nil {
+		return st, err
+	}
+	return st, nil
+}
+
+// BlockedNavigation is a navigation refused by the navigation policy.
+type BlockedNavigation struct {
+	URL    string
+	Reason string
+}
+
+// GetBlocked returns how many navigations the policy has blocked since
+// the kiosk started and the most recent ones, oldest first.
+func (c *Client) GetBlocked(ctx context.Context) (uint32, []BlockedNavigation, error) {
+	var total uint32
+	var recent []BlockedNavigation
+	call, err := c.call(ctx, "GetBlocked")
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := store(call, "GetBlocked", &total, &recent); err != nil {
+		return 0, nil, err
+	}
+	return total, recent, nil
+}
+
+// Idle is the state of the kiosk's idle watchdog.
+type Idle struct {
+	Timeout uint32 `json:"timeout"` // seconds, 0 when disabled
+	Action  string `json:"action"`  // "home" or "reset"
+	Seconds uint32 `json:"seconds"` // time since the last activity
+}
+
+// GetIdle returns the idle timeout, its action and the current idle time.
+func (c *Client) GetIdle(ctx context.Context) (Idle, error) {
+	var idle Idle
+	call, err := c.call(ctx, "GetIdle")
+	if err != nil {
+		return idle, err
+	}
+	if err := store(call, "GetIdle", &idle.Timeout, &idle.Action, &idle.Seconds); err != nil {
+		return idle, err
+	}
+	return idle, nil
 }
 
+// wrapCallError maps the D-Bus error of a failed call to the errors of
+// this package.
 func wrapCallError(call *dbus.Call, method string) error {
 	if call.Err == nil {
 		return nil
 	}
-	dbusErr, ok := call.Err.(dbus.Error)
-	if ok && dbusErr.Name == "org.freedesktop.DBus.Error.ServiceUnknown" {
-		return fmt.Errorf("kiosk service is not running")
+	var dbusErr dbus.Error
+	if errors.As(call.Err, &dbusErr) {
+		switch dbusErr.Name {
+		case "org.freedesktop.DBus.Error.ServiceUnknown",
+			"org.freedesktop.DBus.Error.NameHasNoOwner":
+			return ErrNotRunning
+		case "org.freedesktop.DBus.Error.NoReply",
+			"org.freedesktop.DBus.Error.Timeout":
+			return ErrTimeout
+		case "org.freedesktop.DBus.Error.AccessDenied":
+			return ErrAccessDenied
+		case "com.wpe.Kiosk.Error.InvalidScope":
+			return ErrInvalidScope
+		case "com.wpe.Kiosk.Error.ScriptException":
+			msg := ""
+			if len(dbusErr.Body) > 0 {
+				msg, _ = dbusErr.Body[0].(string)
+			}
+			return &ScriptError{Message: msg}
+		}
 	}
 	return fmt.Errorf("D-Bus %s failed: %w", method, call.Err)
 }
@@ -1,6 +1,7 @@
 package dbus
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -18,6 +19,9 @@ func TestWrapCallErrorServiceUnknown(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
+	if !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning, got: %v", err)
+	}
 	if !strings.Contains(err.Error(), "not running") {
 		t.Errorf("expected 'not running' message, got: %s", err.Error())
 	}
@@ -46,3 +50,37 @@ func TestWrapCallErrorGeneric(t *testing.T) {
 		t.Errorf("expected method name in error, got: %s", err.Error())
 	}
 }
+
+func TestWrapCallErrorTypedErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		want error
+	}{
+		{"org.freedesktop.DBus.Error.NameHasNoOwner", ErrNotRunning},
+		{"org.freedesktop.DBus.Error.NoReply", ErrTimeout},
+		{"org.freedesktop.DBus.Error.AccessDenied", ErrAccessDenied},
+		{"com.wpe.Kiosk.Error.InvalidScope", ErrInvalidScope},
+	}
+	for _, tt := range tests {
+		err := wrapCallError(&godbus.Call{Err: godbus.Error{Name: tt.name}}, "ClearData")
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestWrapCallErrorScriptException(t *testing.T) {
+	call := &godbus.Call{
+		Err: godbus.Error{
+			Name: "com.wpe.Kiosk.Error.ScriptException",
+			Body: []interface{}{"ReferenceError: foo is not defined"},
+		},
+	}
+	var scriptErr *ScriptError
+	if err := wrapCallError(call, "EvaluateScript"); !errors.As(err, &scriptErr) {
+		t.Fatalf("expected *ScriptError, got %v", err)
+	}
+	if scriptErr.Message != "ReferenceError: foo is not defined" {
+		t.Errorf("unexpected message: %q", scriptErr.Message)
+	}
+}
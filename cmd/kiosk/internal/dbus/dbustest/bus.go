@@ -0,0 +1,74 @@
+// Package dbustest provides a private D-Bus daemon and a fake com.wpe.Kiosk
+// service, so the D-Bus paths of the API, CLI and TUI can be tested
+// without the launcher or WebKit.
+//
+// A typical test starts a bus, puts a fake kiosk on it and points a
+// client at the bus:
+//
+//	addr := dbustest.StartBus(t)
+//	fake := dbustest.NewKiosk(t, addr)
+//	client := dbus.NewClientAt(addr)
+//
+// Processes started by the test, such as the kiosk CLI, can be pointed at
+// the bus with $KIOSK_DBUS_ADDRESS.
+package dbustest
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-BUS Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// StartBus starts a private dbus-daemon for the test and returns its
+// address. The daemon is stopped when the test finishes. The test is
+// skipped if dbus-daemon is not installed.
+func StartBus(t testing.TB) string {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not found in PATH")
+	}
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(config, []byte(fmt.Sprintf(busConfig, filepath.Join(dir, "bus"))), 0o644); err != nil {
+		t.Fatalf("writing bus config: %v", err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+config, "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("starting dbus-daemon: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("starting dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading dbus-daemon address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
@@ -0,0 +1,331 @@
+package dbustest
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+)
+
+const (
+	busName       = "com.wpe.Kiosk"
+	objectPath    = "/"
+	interfaceName = "com.wpe.Kiosk"
+)
+
+// Errors the launcher replies with, for use with Kiosk.Fail.
+var (
+	ErrInvalidScope = godbus.NewError("com.wpe.Kiosk.Error.InvalidScope",
+		[]any{"scope must be 'cache', 'cookies', or 'all'"})
+	ErrNotReady = godbus.NewError("com.wpe.Kiosk.Error.NotReady", []any{"web view not ready"})
+)
+
+// ScriptException returns the error the launcher replies with when a
+// script passed to EvaluateScript throws.
+func ScriptException(message string) *godbus.Error {
+	return godbus.NewError("com.wpe.Kiosk.Error.ScriptException", []any{message})
+}
+
+// Call is a method call received by a fake Kiosk.
+type Call struct {
+	Method string
+	Args   []any
+}
+
+// State is what a fake Kiosk reports. Navigation methods update it the way
+// the launcher would.
+type State struct {
+	History   []string // visited URLs, oldest first
+	Index     int      // position of the current page in History
+	Title     string
+	Progress  float64
+	Loading   bool
+	LastError string
+
+	Stats        dbus.Stats
+	BlockedTotal uint32
+	Blocked      []dbus.BlockedNavigation
+	Idle         dbus.Idle
+	Cleared      []string // scopes passed to ClearData
+
+	EvalResult string // JSON returned by EvaluateScript, "null" if empty
+	PNG        []byte // returned by Screenshot
+}
+
+// URL returns the current page, or "" if nothing was opened.
+func (s State) URL() string {
+	if len(s.History) == 0 {
+		return ""
+	}
+	return s.History[s.Index]
+}
+
+// Kiosk is a fake com.wpe.Kiosk service. It records the calls it
+// receives, and can be told to fail or delay them and to emit signals.
+type Kiosk struct {
+	t    testing.TB
+	conn *godbus.Conn
+
+	mu     sync.Mutex
+	state  State
+	calls  []Call
+	fail   map[string]*godbus.Error
+	delays map[string]time.Duration
+}
+
+// NewKiosk exports a fake kiosk on the bus at address and claims the
+// kiosk's bus name. It is removed from the bus when the test finishes.
+func NewKiosk(t testing.TB, address string) *Kiosk {
+	t.Helper()
+	conn, err := godbus.Connect(address)
+	if err != nil {
+		t.Fatalf("connecting to test bus: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	k := &Kiosk{
+		t:      t,
+		conn:   conn,
+		fail:   make(map[string]*godbus.Error),
+		delays: make(map[string]time.Duration),
+	}
+	if err := conn.ExportMethodTable(k.methods(), objectPath, interfaceName); err != nil {
+		t.Fatalf("exporting fake kiosk: %v", err)
+	}
+	k.Start()
+	return k
+}
+
+// Start claims the kiosk's bus name, as the launcher does when it starts.
+func (k *Kiosk) Start() {
+	k.t.Helper()
+	reply, err := k.conn.RequestName(busName, godbus.NameFlagDoNotQueue)
+	if err != nil {
+		k.t.Fatalf("requesting %s: %v", busName, err)
+	}
+	if reply != godbus.RequestNameReplyPrimaryOwner && reply != godbus.RequestNameReplyAlreadyOwner {
+		k.t.Fatalf("requesting %s: name is taken", busName)
+	}
+}
+
+// Stop releases the kiosk's bus name, as if the launcher had exited.
+// Calls fail with ServiceUnknown until Start is called again.
+func (k *Kiosk) Stop() {
+	k.t.Helper()
+	if _, err := k.conn.ReleaseName(busName); err != nil {
+		k.t.Fatalf("releasing %s: %v", busName, err)
+	}
+}
+
+// Update changes the state reported by the fake.
+func (k *Kiosk) Update(f func(*State)) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	f(&k.state)
+}
+
+// State returns a copy of the current state.
+func (k *Kiosk) State() State {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	s := k.state
+	s.History = slices.Clone(s.History)
+	s.Cleared = slices.Clone(s.Cleared)
+	return s
+}
+
+// Calls returns the calls received so far, oldest first.
+func (k *Kiosk) Calls() []Call {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return slices.Clone(k.calls)
+}
+
+// Methods returns the names of the methods called so far, oldest first.
+func (k *Kiosk) Methods() []string {
+	var names []string
+	for _, c := range k.Calls() {
+		names = append(names, c.Method)
+	}
+	return names
+}
+
+// Fail makes calls to method reply with err. A nil err restores normal
+// replies. Failed calls are still recorded.
+func (k *Kiosk) Fail(method string, err *godbus.Error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err == nil {
+		delete(k.fail, method)
+		return
+	}
+	k.fail[method] = err
+}
+
+// Delay makes calls to method wait d before replying.
+func (k *Kiosk) Delay(method string, d time.Duration) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.delays[method] = d
+}
+
+// Emit sends a kiosk signal, e.g. Emit(dbus.SignalLoadFailed, url, msg).
+func (k *Kiosk) Emit(signal string, args ...any) error {
+	return k.conn.Emit(objectPath, interfaceName+"."+signal, args...)
+}
+
+// enter records a call, waits for its delay and returns its injected
+// error. The state lock is held on return unless an error is returned.
+func (k *Kiosk) enter(method string, args ...any) *godbus.Error {
+	k.mu.Lock()
+	k.calls = append(k.calls, Call{Method: method, Args: args})
+	delay, err := k.delays[method], k.fail[method]
+	k.mu.Unlock()
+
+	time.Sleep(delay)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	return nil
+}
+
+// load starts and finishes loading url, emitting the launcher's signals.
+// It is called with the state lock held.
+func (k *Kiosk) load(url string, changed bool) {
+	k.state.Stats.PageLoads++
+	k.state.Progress, k.state.Loading, k.state.LastError = 1, false, ""
+	if changed {
+		k.state.Title = ""
+		k.Emit(dbus.SignalUrlChanged, url)
+	}
+	k.Emit(dbus.SignalLoadStarted, url)
+	k.Emit(dbus.SignalLoadFinished, url)
+}
+
+func (k *Kiosk) methods() map[string]any {
+	return map[string]any{
+		"Open": func(url string) *godbus.Error {
+			if err := k.enter("Open", url); err != nil {
+				return err
+			}
+			defer k.mu.Unlock()
+			s := &k.state
+			if len(s.History) > 0 {
+				s.History = s.History[:s.Index+1]
+			}
+			s.History = append(s.History, url)
+			s.Index = len(s.History) - 1
+			k.load(url, true)
+			return nil
+		},
+		"Reload": func() *godbus.Error {
+			if err := k.enter("Reload"); err != nil {
+				return err
+			}
+			defer k.mu.Unlock()
+			k.load(k.state.URL(), false)
+			return nil
+		},
+		"GoBack": func() *godbus.Error {
+			if err := k.enter("GoBack"); err != nil {
+				return err
+			}
+			defer k.mu.Unlock()
+			if k.state.Index > 0 {
+				k.state.Index--
+				k.load(k.state.URL(), true)
+			}
+			return nil
+		},
+		"GoForward": func() *godbus.Error {
+			if err := k.enter("GoForward"); err != nil {
+				return err
+			}
+			defer k.mu.Unlock()
+			if k.state.Index < len(k.state.History)-1 {
+				k.state.Index++
+				k.load(k.state.URL(), true)
+			}
+			return nil
+		},
+		"StopLoading": func() *godbus.Error {
+			if err := k.enter("StopLoading"); err != nil {
+				return err
+			}
+			defer k.mu.Unlock()
+			k.state.Loading = false
+			return nil
+		},
+		"GetUrl": func() (string, *godbus.Error) {
+			if err := k.enter("GetUrl"); err != nil {
+				return "", err
+			}
+			defer k.mu.Unlock()
+			return k.state.URL(), nil
+		},
+		"GetPageInfo": func() (string, string, float64, bool, bool, bool, string, *godbus.Error) {
+			if err := k.enter("GetPageInfo"); err != nil {
+				return "", "", 0, false, false, false, "", err
+			}
+			defer k.mu.Unlock()
+			s := k.state
+			return s.URL(), s.Title, s.Progress, s.Loading,
+				s.Index > 0, s.Index < len(s.History)-1, s.LastError, nil
+		},
+		"ClearData": func(scope string) *godbus.Error {
+			if err := k.enter("ClearData", scope); err != nil {
+				return err
+			}
+			defer k.mu.Unlock()
+			switch scope {
+			case "cache", "cookies", "all":
+				k.state.Cleared = append(k.state.Cleared, scope)
+				return nil
+			}
+			return ErrInvalidScope
+		},
+		"EvaluateScript": func(script string) (string, *godbus.Error) {
+			if err := k.enter("EvaluateScript", script); err != nil {
+				return "", err
+			}
+			defer k.mu.Unlock()
+			if k.state.EvalResult == "" {
+				return "null", nil
+			}
+			return k.state.EvalResult, nil
+		},
+		"Screenshot": func() ([]byte, *godbus.Error) {
+			if err := k.enter("Screenshot"); err != nil {
+				return nil, err
+			}
+			defer k.mu.Unlock()
+			return k.state.PNG, nil
+		},
+		"GetStats": func() (uint32, uint32, *godbus.Error) {
+			if err := k.enter("GetStats"); err != nil {
+				return 0, 0, err
+			}
+			defer k.mu.Unlock()
+			return k.state.Stats.PageLoads, k.state.Stats.WebProcessCrashes, nil
+		},
+		"GetBlocked": func() (uint32, []dbus.BlockedNavigation, *godbus.Error) {
+			if err := k.enter("GetBlocked"); err != nil {
+				return 0, nil, err
+			}
+			defer k.mu.Unlock()
+			return k.state.BlockedTotal, slices.Clone(k.state.Blocked), nil
+		},
+		"GetIdle": func() (uint32, string, uint32, *godbus.Error) {
+			if err := k.enter("GetIdle"); err != nil {
+				return 0, "", 0, err
+			}
+			defer k.mu.Unlock()
+			return k.state.Idle.Timeout, k.state.Idle.Action, k.state.Idle.Seconds, nil
+		},
+	}
+}
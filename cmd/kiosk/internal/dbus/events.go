@@ -0,0 +1,176 @@
+package dbus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Signals emitted by the kiosk.
+const (
+	SignalLoadStarted          = "LoadStarted"
+	SignalLoadFinished         = "LoadFinished"
+	SignalLoadFailed           = "LoadFailed"
+	SignalWebProcessTerminated = "WebProcessTerminated"
+	SignalUrlChanged           = "UrlChanged"
+	SignalTitleChanged         = "TitleChanged"
+)
+
+// eventBufferSize is how many events Subscribe buffers for a slow reader.
+const eventBufferSize = 32
+
+// Event is a page lifecycle signal emitted by the kiosk. Only the fields
+// of its signal are set: URL for the load signals and UrlChanged, Error
+// for LoadFailed, Reason for WebProcessTerminated and Title for
+// TitleChanged.
+type Event struct {
+	Signal string    `json:"signal"`
+	Time   time.Time `json:"time"`
+	URL    string    `json:"url,omitempty"`
+	Title  string    `json:"title,omitempty"`
+	Error  string    `json:"error,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+func (e Event) String() string {
+	switch e.Signal {
+	case SignalLoadFailed:
+		return fmt.Sprintf("%s %s: %s", e.Signal, e.URL, e.Error)
+	case SignalWebProcessTerminated:
+		return e.Signal + " (" + e.Reason + ")"
+	case SignalTitleChanged:
+		return fmt.Sprintf("%s %q", e.Signal, e.Title)
+	}
+	return e.Signal + " " + e.URL
+}
+
+// reconnectInterval is how often Subscribe tries to get a new bus
+// connection after losing one.
+const reconnectInterval = 2 * time.Second
+
+// Subscribe delivers the kiosk's signals until ctx is done, then closes
+// the returned channel. Signals keep coming across kiosk restarts, and
+// the subscription is renewed on a new connection if the bus connection
+// is lost. Events are dropped, not queued, while the reader falls more
+// than a few dozen events behind.
+func (c *Client) Subscribe(ctx context.Context) (<-chan Event, error) {
+	conn, err := c.connection()
+	if err != nil {
+		return nil, err
+	}
+	signals, cancel, err := subscribe(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event, eventBufferSize)
+	go func() {
+		defer close(events)
+		for {
+			relay(ctx, conn, signals, events)
+			cancel()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(reconnectInterval):
+				}
+				if conn, err = c.connection(); err != nil {
+					continue
+				}
+				if signals, cancel, err = subscribe(ctx, conn); err == nil {
+					break
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// subscribe adds a match rule for the kiosk's signals on conn. The
+// returned function removes it again.
+func subscribe(ctx context.Context, conn *dbus.Conn) (chan *dbus.Signal, func(), error) {
+	match := []dbus.MatchOption{
+		dbus.WithMatchSender(busName),
+		dbus.WithMatchObjectPath(objectPath),
+		dbus.WithMatchInterface(interfaceName),
+	}
+	if err := conn.AddMatchSignalContext(ctx, match...); err != nil {
+		return nil, nil, fmt.Errorf("failed to subscribe to kiosk signals: %w", err)
+	}
+	signals := make(chan *dbus.Signal, eventBufferSize)
+	conn.Signal(signals)
+	return signals, func() {
+		conn.RemoveSignal(signals)
+		if conn.Connected() {
+			conn.RemoveMatchSignal(match...)
+		}
+	}, nil
+}
+
+// relay forwards kiosk signals to events until ctx is done or the
+// connection is lost.
+func relay(ctx context.Context, conn *dbus.Conn, signals <-chan *dbus.Signal, events chan<- Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-conn.Context().Done():
+			return
+		case sig, ok := <-signals:
+			if !ok {
+				return
+			}
+			ev, ok := parseSignal(sig)
+			if !ok {
+				continue
+			}
+			select {
+			case events <- ev:
+			default:
+			}
+		}
+	}
+}
+
+// parseSignal converts a kiosk signal into an Event. Other signals on the
+// connection, and kiosk signals with an unexpected body, are rejected.
+func parseSignal(sig *dbus.Signal) (Event, bool) {
+	if sig.Path != objectPath || !strings.HasPrefix(sig.Name, interfaceName+".") {
+		return Event{}, false
+	}
+	ev := Event{Signal: strings.TrimPrefix(sig.Name, interfaceName+"."), Time: time.Now()}
+
+	var args []string
+	for _, v := range sig.Body {
+		s, ok := v.(string)
+		if !ok {
+			return Event{}, false
+		}
+		args = append(args, s)
+	}
+	want := 1
+	if ev.Signal == SignalLoadFailed {
+		want = 2
+	}
+	if len(args) != want {
+		return Event{}, false
+	}
+
+	switch ev.Signal {
+	case SignalLoadStarted, SignalLoadFinished, SignalUrlChanged:
+		ev.URL = args[0]
+	case SignalLoadFailed:
+		ev.URL, ev.Error = args[0], args[1]
+	case SignalWebProcessTerminated:
+		ev.Reason = args[0]
+	case SignalTitleChanged:
+		ev.Title = args[0]
+	default:
+		return Event{}, false
+	}
+	return ev, true
+}
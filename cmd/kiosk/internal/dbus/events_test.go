@@ -0,0 +1,49 @@
+package dbus
+
+import (
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+)
+
+func TestParseSignal(t *testing.T) {
+	tests := []struct {
+		name string
+		body []interface{}
+		want Event
+	}{
+		{"LoadStarted", []interface{}{"https://a.example/"}, Event{Signal: SignalLoadStarted, URL: "https://a.example/"}},
+		{"LoadFailed", []interface{}{"https://a.example/", "Could not resolve host"},
+			Event{Signal: SignalLoadFailed, URL: "https://a.example/", Error: "Could not resolve host"}},
+		{"WebProcessTerminated", []interface{}{"crashed"}, Event{Signal: SignalWebProcessTerminated, Reason: "crashed"}},
+		{"TitleChanged", []interface{}{"Menu"}, Event{Signal: SignalTitleChanged, Title: "Menu"}},
+	}
+	for _, tt := range tests {
+		ev, ok := parseSignal(&godbus.Signal{
+			Path: objectPath,
+			Name: interfaceName + "." + tt.name,
+			Body: tt.body,
+		})
+		if !ok {
+			t.Errorf("%s: not parsed", tt.name)
+			continue
+		}
+		ev.Time = tt.want.Time
+		if ev != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, ev, tt.want)
+		}
+	}
+}
+
+func TestParseSignalRejectsOthers(t *testing.T) {
+	for _, sig := range []*godbus.Signal{
+		{Path: "/org/freedesktop/DBus", Name: "org.freedesktop.DBus.NameOwnerChanged", Body: []interface{}{"a", "b", "c"}},
+		{Path: objectPath, Name: interfaceName + ".Unknown", Body: []interface{}{"x"}},
+		{Path: objectPath, Name: interfaceName + ".LoadFailed", Body: []interface{}{"https://a.example/"}},
+		{Path: objectPath, Name: interfaceName + ".UrlChanged", Body: []interface{}{uint32(1)}},
+	} {
+		if ev, ok := parseSignal(sig); ok {
+			t.Errorf("%s %v: parsed as %+v", sig.Name, sig.Body, ev)
+		}
+	}
+}
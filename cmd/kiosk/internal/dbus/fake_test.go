@@ -0,0 +1,125 @@
+package dbus_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus/dbustest"
+)
+
+func startFake(t *testing.T) (*dbustest.Kiosk, *dbus.Client) {
+	t.Helper()
+	addr := dbustest.StartBus(t)
+	fake := dbustest.NewKiosk(t, addr)
+	client := dbus.NewClientAt(addr)
+	t.Cleanup(func() { client.Close() })
+	return fake, client
+}
+
+func TestClient_AgainstFakeKiosk(t *testing.T) {
+	fake, client := startFake(t)
+	ctx := context.Background()
+
+	for _, url := range []string{"https://a.example/", "https://b.example/"} {
+		if err := client.Open(ctx, url); err != nil {
+			t.Fatalf("Open(%s): %v", url, err)
+		}
+	}
+	if err := client.GoBack(ctx); err != nil {
+		t.Fatalf("GoBack: %v", err)
+	}
+	page, err := client.GetPageInfo(ctx)
+	if err != nil {
+		t.Fatalf("GetPageInfo: %v", err)
+	}
+	if page.URI != "https://a.example/" || page.CanGoBack || !page.CanGoForward {
+		t.Errorf("unexpected page info: %+v", page)
+	}
+
+	stats, err := client.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if stats.PageLoads != 3 {
+		t.Errorf("expected 3 page loads, got %d", stats.PageLoads)
+	}
+
+	want := []string{"Open", "Open", "GoBack", "GetPageInfo", "GetStats"}
+	if got := fake.Methods(); !slices.Equal(got, want) {
+		t.Errorf("expected calls %v, got %v", want, got)
+	}
+}
+
+func TestClient_FakeKioskErrors(t *testing.T) {
+	fake, client := startFake(t)
+	ctx := context.Background()
+
+	if err := client.ClearData(ctx, "everything"); !errors.Is(err, dbus.ErrInvalidScope) {
+		t.Errorf("ClearData: expected ErrInvalidScope, got %v", err)
+	}
+
+	fake.Fail("EvaluateScript", dbustest.ScriptException("ReferenceError: x is not defined"))
+	_, err := client.Evaluate(ctx, "x")
+	var scriptErr *dbus.ScriptError
+	if !errors.As(err, &scriptErr) || scriptErr.Message != "ReferenceError: x is not defined" {
+		t.Errorf("Evaluate: expected script error, got %v", err)
+	}
+
+	fake.Delay("Reload", time.Second)
+	client.Timeout = 50 * time.Millisecond
+	if err := client.Reload(ctx); !errors.Is(err, dbus.ErrTimeout) {
+		t.Errorf("Reload: expected ErrTimeout, got %v", err)
+	}
+
+	fake.Stop()
+	if _, err := client.GetUrl(ctx); !errors.Is(err, dbus.ErrNotRunning) {
+		t.Errorf("GetUrl: expected ErrNotRunning, got %v", err)
+	}
+}
+
+func TestSubscribe_FakeKioskSignals(t *testing.T) {
+	fake, client := startFake(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	next := func() dbus.Event {
+		t.Helper()
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an event")
+			return dbus.Event{}
+		}
+	}
+
+	if err := fake.Emit(dbus.SignalLoadFailed, "https://a.example/", "Not found"); err != nil {
+		t.Fatal(err)
+	}
+	if ev := next(); ev.Signal != dbus.SignalLoadFailed || ev.Error != "Not found" {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+
+	// Signals keep coming after the kiosk restarts.
+	fake.Stop()
+	fake.Start()
+	if err := fake.Emit(dbus.SignalTitleChanged, "Welcome"); err != nil {
+		t.Fatal(err)
+	}
+	if ev := next(); ev.Signal != dbus.SignalTitleChanged || ev.Title != "Welcome" {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+
+	cancel()
+	for range events {
+	}
+}
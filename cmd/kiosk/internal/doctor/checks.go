@@ -0,0 +1,349 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+)
+
+var configCheck = Check{
+	ID:    "config",
+	Title: "Config file",
+	Run: func(ctx context.Context, env *Env) Result {
+		cfg, err := env.config()
+		if err != nil {
+			return fail(reinstallHint, "%v", err)
+		}
+		var invalid, unknown []string
+		for _, kv := range cfg.KeyValues() {
+			err := config.Validate(kv.Key, kv.Value)
+			var unknownErr *config.UnknownKeyError
+			switch {
+			case errors.As(err, &unknownErr):
+				unknown = append(unknown, kv.Key)
+			case err != nil:
+				invalid = append(invalid, err.Error())
+			}
+		}
+		if len(invalid) > 0 {
+			return fail("Correct the values: kiosk config set KEY VALUE", "%s", strings.Join(invalid, "; "))
+		}
+		if len(unknown) > 0 {
+			return warn("Correct or remove them in "+env.ConfigPath, "unknown keys are ignored: %s", list(unknown))
+		}
+		return pass("%s is valid", env.ConfigPath)
+	},
+}
+
+var ttyCheck = Check{
+	ID:    "tty",
+	Title: "Virtual terminal",
+	Run: func(ctx context.Context, env *Env) Result {
+		tty := env.value("TTY")
+		if n, err := strconv.Atoi(tty); err != nil || n < 1 || n > 12 {
+			return fail("Pick a terminal between 1 and 12: kiosk config set TTY 7", "TTY %q is not a virtual terminal", tty)
+		}
+		dev := filepath.Join(env.DevDir, "tty"+tty)
+		if _, err := os.Stat(dev); err != nil {
+			return fail("Pick an existing terminal: kiosk config set TTY 7", "%s does not exist", dev)
+		}
+		getty := "getty@tty" + tty
+		if st, err := env.Services.Status(ctx, getty); err == nil && st.State == "active" {
+			return warn(
+				fmt.Sprintf("Disable the login prompt (sudo systemctl disable --now %s) or move the kiosk to a free terminal (kiosk config set TTY 7)", getty),
+				"a login prompt (%s) runs on tty%s and competes with the kiosk for the screen", getty, tty)
+		}
+		return pass("tty%s is free", tty)
+	},
+}
+
+var portsCheck = Check{
+	ID:    "ports",
+	Title: "Port conflicts",
+	Run: func(ctx context.Context, env *Env) Result {
+		keys := []string{"INSPECTOR_PORT", "INSPECTOR_HTTP_PORT", "API_PORT"}
+		if env.value("VNC_ENABLED") == "true" {
+			keys = append(keys, "VNC_PORT")
+		}
+		byPort := map[string][]string{}
+		var ports []string
+		for _, k := range keys {
+			p := env.value(k)
+			if byPort[p] == nil {
+				ports = append(ports, p)
+			}
+			byPort[p] = append(byPort[p], k)
+		}
+
+		var conflicts []string
+		var move string
+		for _, p := range ports {
+			if users := byPort[p]; len(users) > 1 {
+				conflicts = append(conflicts, fmt.Sprintf("%s use port %s", strings.Join(users, " and "), p))
+				if move == "" {
+					move = users[len(users)-1]
+				}
+			}
+		}
+		if len(conflicts) > 0 {
+			return fail("Give each service its own port, e.g. kiosk config set "+move+" <free port>",
+				"%s", strings.Join(conflicts, "; "))
+		}
+		return pass("%s are distinct", strings.Join(keys, ", "))
+	},
+}
+
+var dbusPolicyCheck = Check{
+	ID:    "dbus-policy",
+	Title: "D-Bus policy",
+	Run: func(ctx context.Context, env *Env) Result {
+		for _, p := range env.DBusPolicyPaths {
+			data, err := os.ReadFile(p)
+			if err != nil {
+				continue
+			}
+			rules, err := defaultPolicyRules(data)
+			if err != nil {
+				return fail(reinstallHint, "cannot parse %s: %v", p, err)
+			}
+			for _, want := range []string{`allow own="com.wpe.Kiosk"`, `allow send_destination="com.wpe.Kiosk"`} {
+				if !rules[want] {
+					return fail(reinstallHint, "%s lacks the rule %s", p, want)
+				}
+			}
+			var open []string
+			for _, m := range restrictedMethods {
+				if !rules[`deny send_destination="com.wpe.Kiosk" send_member="`+m+`"`] {
+					open = append(open, m)
+				}
+			}
+			if len(open) > 0 {
+				return fail(reinstallHint, "%s lets every local user call %s, bypassing the API's scopes", p, strings.Join(open, " and "))
+			}
+			return pass("%s allows com.wpe.Kiosk and restricts %s to root", p, strings.Join(restrictedMethods, " and "))
+		}
+		return fail(reinstallHint+", then restart the kiosk",
+			"no D-Bus policy for com.wpe.Kiosk in %s; the kiosk cannot claim its bus name and the CLI and API cannot reach it",
+			list(env.DBusPolicyPaths))
+	},
+}
+
+// restrictedMethods are the kiosk methods the D-Bus policy reserves for
+// root: they run scripts in the page or capture the screen.
+var restrictedMethods = []string{"EvaluateScript", "Screenshot"}
+
+// defaultPolicyRules returns the rules of the default policy context in a
+// D-Bus policy file, as "allow own=..." or "deny send_destination=...
+// send_member=...". Other attributes are ignored.
+func defaultPolicyRules(data []byte) (map[string]bool, error) {
+	var doc struct {
+		Policies []struct {
+			Context string `xml:"context,attr"`
+			Rules   []struct {
+				XMLName xml.Name
+				Attrs   []xml.Attr `xml:",any,attr"`
+			} `xml:",any"`
+		} `xml:"policy"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	rules := map[string]bool{}
+	for _, p := range doc.Policies {
+		if p.Context != "default" {
+			continue
+		}
+		for _, r := range p.Rules {
+			attrs := map[string]string{}
+			for _, a := range r.Attrs {
+				attrs[a.Name.Local] = a.Value
+			}
+			rule := r.XMLName.Local
+			for _, name := range []string{"own", "send_destination", "send_member"} {
+				if v, ok := attrs[name]; ok {
+					rule += fmt.Sprintf(" %s=%q", name, v)
+				}
+			}
+			rules[rule] = true
+		}
+	}
+	return rules, nil
+}
+
+var sudoersCheck = Check{
+	ID:    "sudoers",
+	Title: "Sudo rules",
+	Run: func(ctx context.Context, env *Env) Result {
+		info, err := os.Stat(env.SudoersPath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			return fail(reinstallHint, "%s is missing; the CLI cannot restart services or change the config without a password", env.SudoersPath)
+		case errors.Is(err, os.ErrPermission):
+			// /etc/sudoers.d is usually not readable by users; ask sudo
+			// whether the rules apply instead.
+			probe := exec.CommandContext(ctx, "sudo", append([]string{"--non-interactive", "--list"}, sudoProbe...)...)
+			if probe.Run() != nil {
+				return fail(reinstallHint, "sudo asks for a password for %s, so the CLI cannot restart services or change the config", strings.Join(sudoProbe, " "))
+			}
+			return pass("sudo allows the kiosk's commands without a password")
+		case err != nil:
+			return fail(reinstallHint, "%v", err)
+		}
+
+		// Never repair these in place: the file may hold rules someone else
+		// wrote, which fixing the permissions would switch on.
+		if st, ok := info.Sys().(*syscall.Stat_t); ok && st.Uid != 0 {
+			return fail(reinstallHint, "%s is not owned by root, so sudo ignores it", env.SudoersPath)
+		}
+		switch mode := info.Mode().Perm(); {
+		case mode&0o022 != 0:
+			return fail(reinstallHint, "%s is writable by other users (mode %04o), so sudo ignores it", env.SudoersPath, mode)
+		case mode != 0o440:
+			return warn(reinstallHint, "%s has mode %04o instead of 0440", env.SudoersPath, mode)
+		}
+		return pass("%s is installed", env.SudoersPath)
+	},
+}
+
+// sudoProbe is a command the package's sudo rules allow without a password.
+var sudoProbe = []string{"/usr/bin/systemctl", "restart", "wpe-webkit-kiosk"}
+
+var extensionsDirCheck = Check{
+	ID:    "extensions-dir",
+	Title: "Extensions directory",
+	Run: func(ctx context.Context, env *Env) Result {
+		dir := env.value("EXTENSIONS_DIR")
+		info, err := os.Stat(dir)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			return warn("Run kiosk doctor --fix to create it, or point EXTENSIONS_DIR to the right directory", "%s does not exist, so no extensions are loaded", dir).fixable()
+		case err != nil:
+			return fail("Make it accessible: sudo chmod 755 "+dir, "%v", err)
+		case !info.IsDir():
+			return fail("Point EXTENSIONS_DIR to a directory: kiosk config set EXTENSIONS_DIR "+config.DefaultExtensionsDir, "%s is not a directory", dir)
+		}
+		if _, err := os.ReadDir(dir); err != nil {
+			return fail("Make it readable: sudo chmod 755 "+dir, "cannot read %s: %v", dir, errors.Unwrap(err))
+		}
+		return pass("%s is readable", dir)
+	},
+	Fix: func(ctx context.Context, env *Env) error {
+		err := os.MkdirAll(env.value("EXTENSIONS_DIR"), 0o755)
+		if errors.Is(err, os.ErrPermission) && !env.Root {
+			return errors.New("run with sudo")
+		}
+		return err
+	},
+}
+
+var manifestsCheck = Check{
+	ID:    "extension-manifests",
+	Title: "Extension manifests",
+	Run: func(ctx context.Context, env *Env) Result {
+		enabled, broken, err := checkManifests(env.value("EXTENSIONS_DIR"))
+		if err != nil {
+			return pass("no extensions to check")
+		}
+		if len(broken) > 0 {
+			var names []string
+			for _, b := range broken {
+				names = append(names, b.dir+" ("+b.reason+")")
+			}
+			return fail("Correct manifest.json, or run kiosk doctor --fix to disable the extensions",
+				"%d of %d enabled extensions have a broken manifest: %s", len(broken), enabled, list(names)).fixable()
+		}
+		return pass("%d enabled extensions, all manifests valid", enabled)
+	},
+	Fix: func(ctx context.Context, env *Env) error {
+		dir := env.value("EXTENSIONS_DIR")
+		_, broken, err := checkManifests(dir)
+		if err != nil {
+			return err
+		}
+		for _, b := range broken {
+			if err := disableExtension(filepath.Join(dir, b.dir)); err != nil {
+				return fmt.Errorf("cannot disable %s: %w", b.dir, err)
+			}
+		}
+		return nil
+	},
+}
+
+var amixerCheck = Check{
+	ID:    "amixer",
+	Title: "Volume control",
+	Run: func(ctx context.Context, env *Env) Result {
+		path, err := env.LookPath("amixer")
+		if err != nil {
+			return fail("Install the ALSA utilities: sudo apt install alsa-utils", "amixer not found, so the volume cannot be read or set")
+		}
+		return pass("%s", path)
+	},
+}
+
+// brokenExtension is an enabled extension whose manifest is unusable.
+type brokenExtension struct {
+	dir    string
+	reason string
+}
+
+// checkManifests returns how many extensions in dir are enabled, and those
+// of them with a missing or invalid manifest.
+func checkManifests(dir string) (int, []brokenExtension, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, nil, err
+	}
+	enabled := 0
+	var broken []brokenExtension
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, e.Name(), ".disabled")); err == nil {
+			continue
+		}
+		enabled++
+
+		data, err := os.ReadFile(filepath.Join(dir, e.Name(), "manifest.json"))
+		if err != nil {
+			broken = append(broken, brokenExtension{e.Name(), "no readable manifest.json"})
+			continue
+		}
+		var m struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}
+		switch {
+		case json.Unmarshal(data, &m) != nil:
+			broken = append(broken, brokenExtension{e.Name(), "invalid JSON"})
+		case m.Name == "" || m.Version == "":
+			broken = append(broken, brokenExtension{e.Name(), "name or version missing"})
+		}
+	}
+	return enabled, broken, nil
+}
+
+// disableExtension creates the .disabled marker in an extension's
+// directory, through sudo if needed.
+func disableExtension(extDir string) error {
+	marker := filepath.Join(extDir, ".disabled")
+	err := os.WriteFile(marker, nil, 0o644)
+	if errors.Is(err, os.ErrPermission) {
+		// Fallback to sudo touch for permission issues
+		if cmdErr := exec.Command("sudo", "/usr/bin/touch", marker).Run(); cmdErr == nil {
+			return nil
+		}
+	}
+	return err
+}
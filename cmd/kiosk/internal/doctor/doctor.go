@@ -0,0 +1,224 @@
+// Package doctor diagnoses common misconfigurations of a kiosk: a TTY
+// taken by a login prompt, conflicting ports, missing D-Bus policy or
+// sudoers rules, unreadable extensions and missing tools. Each check
+// reports pass, warn or fail with a hint on how to fix the problem; some
+// can fix it themselves.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	Pass Status = "pass"
+	Warn Status = "warn"
+	Fail Status = "fail"
+)
+
+// severity orders statuses from best to worst.
+func (s Status) severity() int {
+	switch s {
+	case Warn:
+		return 1
+	case Fail:
+		return 2
+	}
+	return 0
+}
+
+// Result is the outcome of running a check.
+type Result struct {
+	Check   string `json:"check"`
+	Title   string `json:"title"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`    // how to fix the problem
+	Fixable bool   `json:"fixable,omitempty"` // the check's Fix can fix it
+	Fixed   bool   `json:"fixed,omitempty"`   // it was fixed in this run
+}
+
+// Check is a diagnosis in the registry.
+type Check struct {
+	ID    string
+	Title string
+	Run   func(ctx context.Context, env *Env) Result
+	// Fix repairs the problems Run marks as fixable, those that are safe
+	// to repair unattended. It is nil for checks whose problems all need a
+	// decision or a reinstall.
+	Fix func(ctx context.Context, env *Env) error
+}
+
+// Checks is the registry of checks, in the order they run.
+var Checks = []Check{
+	configCheck,
+	ttyCheck,
+	portsCheck,
+	dbusPolicyCheck,
+	sudoersCheck,
+	extensionsDirCheck,
+	manifestsCheck,
+	amixerCheck,
+}
+
+// Lookup returns the check with the given ID.
+func Lookup(id string) (Check, bool) {
+	for _, c := range Checks {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return Check{}, false
+}
+
+// IDs returns the IDs of all checks.
+func IDs() []string {
+	ids := make([]string, len(Checks))
+	for i, c := range Checks {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+// Env is the system the checks inspect. DefaultEnv describes this machine;
+// tests point the paths elsewhere.
+type Env struct {
+	ConfigPath string
+	Services   service.Manager
+	// DBusPolicyPaths are where the kiosk's D-Bus policy may be installed.
+	DBusPolicyPaths []string
+	SudoersPath     string
+	DevDir          string // where tty devices are
+	Root            bool   // running as root, so sudo is not needed
+	LookPath        func(file string) (string, error)
+
+	once   sync.Once
+	cfg    *config.Config
+	cfgErr error
+}
+
+// DefaultEnv returns the environment of a packaged install.
+func DefaultEnv() *Env {
+	return &Env{
+		ConfigPath: config.DefaultPath,
+		Services:   service.Default(),
+		DBusPolicyPaths: []string{
+			"/usr/share/dbus-1/system.d/com.wpe.Kiosk.conf",
+			"/etc/dbus-1/system.d/com.wpe.Kiosk.conf",
+		},
+		SudoersPath: "/etc/sudoers.d/wpe-webkit-kiosk",
+		DevDir:      "/dev",
+		Root:        os.Geteuid() == 0,
+		LookPath:    exec.LookPath,
+	}
+}
+
+// config returns the kiosk config, loading it once.
+func (env *Env) config() (*config.Config, error) {
+	env.once.Do(func() {
+		env.cfg, env.cfgErr = config.Load(env.ConfigPath)
+	})
+	return env.cfg, env.cfgErr
+}
+
+// value returns the configured value of key, or its default if it is not
+// set or the config cannot be read.
+func (env *Env) value(key string) string {
+	if cfg, err := env.config(); err == nil {
+		for _, kv := range cfg.KeyValues() {
+			if kv.Key == key {
+				return kv.Value
+			}
+		}
+	}
+	k, _ := config.LookupKey(key)
+	return k.Default
+}
+
+// Report is the outcome of a run of checks.
+type Report struct {
+	Status Status   `json:"status"` // the worst status of all checks
+	Pass   int      `json:"pass"`
+	Warn   int      `json:"warn"`
+	Fail   int      `json:"fail"`
+	Checks []Result `json:"checks"`
+}
+
+// Run runs checks, all of them if none are given. With fix, checks that
+// report a fixable problem fix it and run again.
+func Run(ctx context.Context, env *Env, checks []Check, fix bool) *Report {
+	if len(checks) == 0 {
+		checks = Checks
+	}
+	report := &Report{Status: Pass, Checks: []Result{}}
+	for _, c := range checks {
+		r := run(ctx, env, c)
+		if fix && r.Fixable && c.Fix != nil {
+			if err := c.Fix(ctx, env); err != nil {
+				r.Message += fmt.Sprintf(" (fix failed: %v)", err)
+			} else {
+				r = run(ctx, env, c)
+				r.Fixed = r.Status == Pass
+			}
+		}
+
+		switch r.Status {
+		case Pass:
+			report.Pass++
+		case Warn:
+			report.Warn++
+		case Fail:
+			report.Fail++
+		}
+		if r.Status.severity() > report.Status.severity() {
+			report.Status = r.Status
+		}
+		report.Checks = append(report.Checks, r)
+	}
+	return report
+}
+
+func run(ctx context.Context, env *Env, c Check) Result {
+	r := c.Run(ctx, env)
+	r.Check, r.Title = c.ID, c.Title
+	return r
+}
+
+func pass(format string, a ...any) Result {
+	return Result{Status: Pass, Message: fmt.Sprintf(format, a...)}
+}
+
+func warn(hint, format string, a ...any) Result {
+	return Result{Status: Warn, Message: fmt.Sprintf(format, a...), Hint: hint}
+}
+
+func fail(hint, format string, a ...any) Result {
+	return Result{Status: Fail, Message: fmt.Sprintf(format, a...), Hint: hint}
+}
+
+// fixable marks the problem r reports as one the check can fix.
+func (r Result) fixable() Result {
+	r.Fixable = true
+	return r
+}
+
+// reinstallHint is the fix for files the package installs.
+const reinstallHint = "Reinstall the package: sudo apt install --reinstall wpe-webkit-kiosk"
+
+// list joins items for a message, shortening long lists.
+func list(items []string) string {
+	if len(items) > 5 {
+		return strings.Join(items[:5], ", ") + fmt.Sprintf(" and %d more", len(items)-5)
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,235 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service/servicetest"
+)
+
+const policy = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-BUS Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig><policy context="default">
+<allow own="com.wpe.Kiosk"/><allow send_destination="com.wpe.Kiosk"/>
+<deny send_destination="com.wpe.Kiosk" send_interface="com.wpe.Kiosk" send_member="EvaluateScript"/>
+<deny send_destination="com.wpe.Kiosk" send_interface="com.wpe.Kiosk" send_member="Screenshot"/>
+</policy></busconfig>`
+
+// testEnv returns a healthy environment in a temporary directory. cfg is
+// prepended to the config, so its values take precedence.
+func testEnv(t *testing.T, cfg string) *Env {
+	t.Helper()
+	dir := t.TempDir()
+	write := func(name, content string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	extDir := filepath.Join(dir, "extensions")
+	write("extensions/idle/manifest.json", `{"name": "idle", "version": "1.0"}`, 0o644)
+	write("dev/tty7", "", 0o644)
+	env := &Env{
+		ConfigPath:      write("config", cfg+"TTY=\"7\"\nEXTENSIONS_DIR=\""+extDir+"\"\n", 0o644),
+		Services:        servicetest.NewManager(),
+		DBusPolicyPaths: []string{filepath.Join(dir, "missing.conf"), write("com.wpe.Kiosk.conf", policy, 0o644)},
+		SudoersPath:     write("sudoers", "", 0o440),
+		DevDir:          filepath.Join(dir, "dev"),
+		Root:            os.Geteuid() == 0,
+		LookPath:        func(file string) (string, error) { return "/usr/bin/" + file, nil },
+	}
+	return env
+}
+
+func runCheck(t *testing.T, env *Env, c Check) Result {
+	t.Helper()
+	r := Run(context.Background(), env, []Check{c}, false).Checks[0]
+	if r.Status != Pass && r.Hint == "" {
+		t.Errorf("%s: %s without a hint", c.ID, r.Status)
+	}
+	return r
+}
+
+func TestRun_HealthyEnv(t *testing.T) {
+	env := testEnv(t, "")
+	checks := Checks
+	if !env.Root {
+		// Files created by the test are not owned by root.
+		checks = nil
+		for _, c := range Checks {
+			if c.ID != "sudoers" {
+				checks = append(checks, c)
+			}
+		}
+	}
+	report := Run(context.Background(), env, checks, false)
+	for _, r := range report.Checks {
+		if r.Status != Pass {
+			t.Errorf("%s: %s: %s", r.Check, r.Status, r.Message)
+		}
+	}
+	if report.Status != Pass || report.Pass != len(checks) {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestRun_ReportsWorstStatus(t *testing.T) {
+	env := testEnv(t, "API_PORT=\"8080\"\nFOO=\"1\"\n")
+	env.LookPath = func(string) (string, error) { return "", errors.New("not found") }
+
+	report := Run(context.Background(), env, []Check{configCheck, portsCheck, amixerCheck, ttyCheck}, false)
+	if report.Status != Fail || report.Pass != 1 || report.Warn != 1 || report.Fail != 2 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestConfigCheck(t *testing.T) {
+	r := runCheck(t, testEnv(t, "FOO=\"1\"\n"), configCheck)
+	if r.Status != Warn || !strings.Contains(r.Message, "FOO") {
+		t.Errorf("expected a warning about FOO, got %+v", r)
+	}
+	r = runCheck(t, testEnv(t, "VNC_PORT=\"99999\"\n"), configCheck)
+	if r.Status != Fail || !strings.Contains(r.Message, "VNC_PORT") {
+		t.Errorf("expected VNC_PORT to fail, got %+v", r)
+	}
+	env := testEnv(t, "")
+	env.ConfigPath = filepath.Join(t.TempDir(), "missing")
+	if r := runCheck(t, env, configCheck); r.Status != Fail {
+		t.Errorf("expected a missing config to fail, got %+v", r)
+	}
+}
+
+func TestTTYCheck(t *testing.T) {
+	env := testEnv(t, "")
+	env.Services.(*servicetest.Manager).Set("getty@tty7", service.Status{State: "active"})
+	r := runCheck(t, env, ttyCheck)
+	if r.Status != Warn || !strings.Contains(r.Hint, "getty@tty7") {
+		t.Errorf("expected a warning about getty, got %+v", r)
+	}
+
+	r = runCheck(t, testEnv(t, "TTY=\"3\"\n"), ttyCheck)
+	if r.Status != Fail || !strings.Contains(r.Message, "tty3") {
+		t.Errorf("expected a missing tty3 to fail, got %+v", r)
+	}
+}
+
+func TestPortsCheck(t *testing.T) {
+	r := runCheck(t, testEnv(t, "API_PORT=\"8090\"\n"), portsCheck)
+	if r.Status != Fail || r.Message != "INSPECTOR_HTTP_PORT and API_PORT use port 8090" ||
+		!strings.Contains(r.Hint, "API_PORT") {
+		t.Errorf("expected a conflict, got %+v", r)
+	}
+
+	// VNC_PORT only counts when VNC is enabled.
+	if r := runCheck(t, testEnv(t, "VNC_PORT=\"8100\"\n"), portsCheck); r.Status != Pass {
+		t.Errorf("expected disabled VNC to be ignored, got %+v", r)
+	}
+	if r := runCheck(t, testEnv(t, "VNC_ENABLED=\"true\"\nVNC_PORT=\"8100\"\n"), portsCheck); r.Status != Fail {
+		t.Errorf("expected VNC_PORT conflict, got %+v", r)
+	}
+}
+
+func TestDBusPolicyCheck(t *testing.T) {
+	env := testEnv(t, "")
+	env.DBusPolicyPaths = env.DBusPolicyPaths[:1]
+	if r := runCheck(t, env, dbusPolicyCheck); r.Status != Fail {
+		t.Errorf("expected a missing policy to fail, got %+v", r)
+	}
+
+	path := filepath.Join(t.TempDir(), "com.wpe.Kiosk.conf")
+	os.WriteFile(path, []byte(`<busconfig><policy context="default"><allow own="com.wpe.Kiosk"/></policy></busconfig>`), 0o644)
+	env.DBusPolicyPaths = []string{path}
+	if r := runCheck(t, env, dbusPolicyCheck); r.Status != Fail || !strings.Contains(r.Message, "send_destination") {
+		t.Errorf("expected an incomplete policy to fail, got %+v", r)
+	}
+
+	// The policy the package shipped before EvaluateScript and Screenshot
+	// were restricted.
+	os.WriteFile(path, []byte(`<busconfig><policy context="default">
+<allow own="com.wpe.Kiosk"/><allow send_destination="com.wpe.Kiosk"/>
+<deny send_destination="com.wpe.Kiosk" send_member="Screenshot"/>
+</policy></busconfig>`), 0o644)
+	if r := runCheck(t, env, dbusPolicyCheck); r.Status != Fail || !strings.Contains(r.Message, "EvaluateScript") || strings.Contains(r.Message, "Screenshot") {
+		t.Errorf("expected the unrestricted EvaluateScript reported, got %+v", r)
+	}
+
+	env.DBusPolicyPaths = []string{"../../../../debian/com.wpe.Kiosk.conf"}
+	if r := runCheck(t, env, dbusPolicyCheck); r.Status != Pass {
+		t.Errorf("expected the packaged policy to pass, got %+v", r)
+	}
+}
+
+func TestSudoersCheck(t *testing.T) {
+	env := testEnv(t, "")
+	os.Remove(env.SudoersPath)
+	if r := runCheck(t, env, sudoersCheck); r.Status != Fail || r.Fixable {
+		t.Errorf("expected missing sudoers to fail without a fix, got %+v", r)
+	}
+
+	if !env.Root {
+		t.Skip("checking ownership needs root")
+	}
+	os.WriteFile(env.SudoersPath, []byte("ALL ALL=(ALL) NOPASSWD: ALL\n"), 0o666)
+	os.Chmod(env.SudoersPath, 0o666)
+	r := Run(context.Background(), env, []Check{sudoersCheck}, true).Checks[0]
+	if r.Status != Fail || r.Fixable || r.Fixed || !strings.Contains(r.Hint, "Reinstall") {
+		t.Errorf("expected a writable sudoers file to fail without a fix, got %+v", r)
+	}
+	if info, _ := os.Stat(env.SudoersPath); info.Mode().Perm() != 0o666 {
+		t.Errorf("expected the mode untouched, got %04o", info.Mode().Perm())
+	}
+}
+
+func TestExtensionChecks_Fix(t *testing.T) {
+	env := testEnv(t, "")
+	extDir := env.value("EXTENSIONS_DIR")
+	os.MkdirAll(filepath.Join(extDir, "broken"), 0o755)
+	os.WriteFile(filepath.Join(extDir, "broken", "manifest.json"), []byte(`{"name":`), 0o644)
+	os.MkdirAll(filepath.Join(extDir, "old"), 0o755)
+	os.WriteFile(filepath.Join(extDir, "old", ".disabled"), nil, 0o644)
+
+	r := runCheck(t, env, manifestsCheck)
+	if r.Status != Fail || !r.Fixable || !strings.Contains(r.Message, "1 of 2") || !strings.Contains(r.Message, "broken (invalid JSON)") {
+		t.Errorf("expected the broken manifest reported, got %+v", r)
+	}
+	r = Run(context.Background(), env, []Check{manifestsCheck}, true).Checks[0]
+	if !r.Fixed || r.Status != Pass {
+		t.Errorf("expected the extension disabled, got %+v", r)
+	}
+	if _, err := os.Stat(filepath.Join(extDir, "broken", ".disabled")); err != nil {
+		t.Error("expected .disabled marker")
+	}
+
+	missing := filepath.Join(t.TempDir(), "extensions")
+	env = testEnv(t, "EXTENSIONS_DIR=\""+missing+"\"\n")
+	if r := runCheck(t, env, extensionsDirCheck); r.Status != Warn || !r.Fixable {
+		t.Errorf("expected a fixable warning, got %+v", r)
+	}
+	if r := Run(context.Background(), env, []Check{extensionsDirCheck}, true).Checks[0]; !r.Fixed {
+		t.Errorf("expected the directory created, got %+v", r)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	seen := map[string]bool{}
+	for _, id := range IDs() {
+		if seen[id] {
+			t.Errorf("duplicate check %s", id)
+		}
+		seen[id] = true
+		if c, ok := Lookup(id); !ok || c.Title == "" || c.Run == nil {
+			t.Errorf("check %s is incomplete", id)
+		}
+	}
+	if _, ok := Lookup("nope"); ok {
+		t.Error("expected unknown check")
+	}
+}
@@ -0,0 +1,180 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/targets"
+)
+
+// Defaults for Run.
+const (
+	DefaultConcurrency = 10
+	DefaultTimeout     = 15 * time.Second
+)
+
+// Host is a kiosk in the inventory. Connection settings are the same as
+// for a CLI target; tags group hosts for selection.
+type Host struct {
+	targets.Target
+	Tags []string `json:"tags,omitempty"`
+}
+
+// HasTags reports whether the host carries every tag in tags.
+func (h *Host) HasTags(tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, t := range h.Tags {
+			if t == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Inventory is the list of kiosks managed together.
+type Inventory struct {
+	Hosts []Host `json:"hosts"`
+}
+
+// DefaultPath returns the per-user inventory file,
+// e.g. ~/.config/wpe-webkit-kiosk/fleet.json.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "wpe-webkit-kiosk", "fleet.json"), nil
+}
+
+// LoadInventory reads and checks the inventory file at path.
+func LoadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read inventory: %w", err)
+	}
+	var inv Inventory
+	if err := json.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("invalid inventory %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for i, h := range inv.Hosts {
+		if h.Name == "" {
+			inv.Hosts[i].Name = h.Host
+		}
+		if inv.Hosts[i].Name == "" || h.Host == "" {
+			return nil, fmt.Errorf("invalid inventory %s: host %d has no host address", path, i+1)
+		}
+		if seen[inv.Hosts[i].Name] {
+			return nil, fmt.Errorf("invalid inventory %s: duplicate host name %q", path, inv.Hosts[i].Name)
+		}
+		seen[inv.Hosts[i].Name] = true
+	}
+	return &inv, nil
+}
+
+// Select returns the hosts carrying all of tags, and named in names if
+// names is not empty. Hosts keep their inventory order.
+func (inv *Inventory) Select(tags, names []string) []Host {
+	var nameSet map[string]bool
+	if len(names) > 0 {
+		nameSet = make(map[string]bool, len(names))
+		for _, n := range names {
+			nameSet[n] = true
+		}
+	}
+
+	var hosts []Host
+	for _, h := range inv.Hosts {
+		if nameSet != nil && !nameSet[h.Name] {
+			continue
+		}
+		if h.HasTags(tags) {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// Op performs an operation on one host and returns a short description
+// of the outcome. The description is dropped if the operation fails.
+type Op func(ctx context.Context, h *Host) (string, error)
+
+// Result is the outcome of an operation on one host.
+type Result struct {
+	Host     string        `json:"host"`
+	OK       bool          `json:"ok"`
+	Detail   string        `json:"detail,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration_ms"`
+}
+
+// MarshalJSON reports the duration in milliseconds.
+func (r Result) MarshalJSON() ([]byte, error) {
+	type alias Result
+	a := alias(r)
+	a.Duration = r.Duration / time.Millisecond
+	return json.Marshal(a)
+}
+
+// Run applies op to every host, at most concurrency at a time, giving each
+// host timeout to finish. Results are returned in the order of hosts.
+func Run(ctx context.Context, hosts []Host, concurrency int, timeout time.Duration, op Op) []Result {
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	results := make([]Result, len(hosts))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range hosts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			hctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			detail, err := op(hctx, &hosts[i])
+			res := Result{Host: hosts[i].Name, OK: err == nil, Detail: detail, Duration: time.Since(start)}
+			if err != nil {
+				res.Detail = ""
+				res.Error = err.Error()
+				if hctx.Err() == context.DeadlineExceeded {
+					res.Error = fmt.Sprintf("timed out after %s", timeout)
+				}
+			}
+			results[i] = res
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// Failed returns how many results are failures.
+func Failed(results []Result) int {
+	n := 0
+	for _, r := range results {
+		if !r.OK {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,137 @@
+package fleet
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/targets"
+)
+
+func writeInventory(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fleet.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadInventoryAndSelect(t *testing.T) {
+	path := writeInventory(t, `{"hosts": [
+		{"name": "a", "host": "10.0.0.1", "token": "x", "tags": ["store", "eu"]},
+		{"host": "10.0.0.2", "port": 9000, "token": "y", "tags": ["store"]},
+		{"name": "c", "host": "10.0.0.3", "tls": true, "token": "z", "tags": ["hq", "eu"]}
+	]}`)
+	inv, err := LoadInventory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.Hosts[1].Name != "10.0.0.2" {
+		t.Errorf("expected host address as default name, got %q", inv.Hosts[1].Name)
+	}
+	if got := inv.Hosts[2].BaseURL(); got != "https://10.0.0.3:8100" {
+		t.Errorf("unexpected base URL %q", got)
+	}
+
+	names := func(hosts []Host) string {
+		var n []string
+		for _, h := range hosts {
+			n = append(n, h.Name)
+		}
+		return strings.Join(n, ",")
+	}
+	cases := []struct {
+		tags, names []string
+		want        string
+	}{
+		{nil, nil, "a,10.0.0.2,c"},
+		{[]string{"store"}, nil, "a,10.0.0.2"},
+		{[]string{"store", "eu"}, nil, "a"},
+		{[]string{"eu"}, []string{"c"}, "c"},
+		{[]string{"missing"}, nil, ""},
+	}
+	for _, c := range cases {
+		if got := names(inv.Select(c.tags, c.names)); got != c.want {
+			t.Errorf("Select(%v, %v) = %q, want %q", c.tags, c.names, got, c.want)
+		}
+	}
+}
+
+func TestLoadInventoryRejectsInvalid(t *testing.T) {
+	for _, content := range []string{
+		`not json`,
+		`{"hosts": [{"name": "a"}]}`,
+		`{"hosts": [{"name": "a", "host": "h1"}, {"name": "a", "host": "h2"}]}`,
+	} {
+		if _, err := LoadInventory(writeInventory(t, content)); err == nil {
+			t.Errorf("expected error for %s", content)
+		}
+	}
+}
+
+func hosts(n int) []Host {
+	h := make([]Host, n)
+	for i := range h {
+		h[i] = Host{Target: targets.Target{Name: string(rune('a' + i)), Host: "h"}}
+	}
+	return h
+}
+
+func TestRunBoundsConcurrencyAndKeepsOrder(t *testing.T) {
+	var running, peak int32
+	results := Run(context.Background(), hosts(8), 3, time.Second, func(ctx context.Context, h *Host) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		if h.Name == "c" {
+			return "", errors.New("boom")
+		}
+		return "done " + h.Name, nil
+	})
+
+	if peak > 3 {
+		t.Errorf("expected at most 3 concurrent operations, got %d", peak)
+	}
+	for i, r := range results {
+		if want := string(rune('a' + i)); r.Host != want {
+			t.Fatalf("result %d is for %q, want %q", i, r.Host, want)
+		}
+	}
+	if results[0].Detail != "done a" || !results[0].OK {
+		t.Errorf("unexpected result %+v", results[0])
+	}
+	if results[2].OK || results[2].Error != "boom" {
+		t.Errorf("expected failure for c, got %+v", results[2])
+	}
+	if Failed(results) != 1 {
+		t.Errorf("expected 1 failure, got %d", Failed(results))
+	}
+}
+
+func TestRunTimesOutPerHost(t *testing.T) {
+	results := Run(context.Background(), hosts(2), 2, 20*time.Millisecond, func(ctx context.Context, h *Host) (string, error) {
+		if h.Name == "a" {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "ok", nil
+	})
+	if results[0].OK || !strings.Contains(results[0].Error, "timed out") {
+		t.Errorf("expected timeout for a, got %+v", results[0])
+	}
+	if !results[1].OK {
+		t.Errorf("expected b to succeed, got %+v", results[1])
+	}
+}
@@ -0,0 +1,337 @@
+// Package logs reads the journal entries of the kiosk's systemd units.
+package logs
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
+)
+
+// Units are the units whose logs can be read.
+var Units = []string{service.KioskUnit, service.APIUnit, service.VNCUnit}
+
+const (
+	// DefaultLimit is how many entries Query returns by default.
+	DefaultLimit = 100
+	// MaxLimit is the most entries Query returns at once.
+	MaxLimit = 1000
+)
+
+// journalctl is the command entries are read with.
+var journalctl = "journalctl"
+
+// Priorities are the syslog priority names, indexed by level.
+var Priorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// ParsePriority parses a priority name ("err") or level ("3").
+func ParsePriority(s string) (int, error) {
+	if i := slices.Index(Priorities, s); i >= 0 {
+		return i, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n < len(Priorities) {
+		return n, nil
+	}
+	return 0, fmt.Errorf("invalid priority %q (use %s or 0-7)", s, strings.Join(Priorities, ", "))
+}
+
+// Entry is a journal entry of one of the kiosk's units.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Unit       string    `json:"unit"`
+	Priority   int       `json:"priority"` // syslog level, 0 (emerg) to 7 (debug)
+	Identifier string    `json:"identifier,omitempty"`
+	PID        int       `json:"pid,omitempty"`
+	Message    string    `json:"message"`
+	Cursor     string    `json:"cursor"` // journal position, for paging and resuming
+}
+
+// PriorityName returns the syslog name of the entry's priority.
+func (e Entry) PriorityName() string {
+	if e.Priority < 0 || e.Priority >= len(Priorities) {
+		return strconv.Itoa(e.Priority)
+	}
+	return Priorities[e.Priority]
+}
+
+// String formats the entry like journalctl's short output:
+// "Jan 02 15:04:05 identifier[pid]: message".
+func (e Entry) String() string {
+	ident := e.Identifier
+	if ident == "" {
+		ident = e.Unit
+	}
+	if e.PID != 0 {
+		ident = fmt.Sprintf("%s[%d]", ident, e.PID)
+	}
+	return fmt.Sprintf("%s %s: %s", e.Time.Format("Jan 02 15:04:05"), ident, e.Message)
+}
+
+// Filter selects journal entries. The zero value selects every entry of
+// every unit in Units.
+type Filter struct {
+	Units    []string // names from Units; all of them if empty
+	Priority string   // only this priority and more severe ones, e.g. "warning"
+	Since    time.Time
+	Until    time.Time
+	Grep     string // case-insensitive regular expression on the message
+	Limit    int    // at most this many entries, DefaultLimit if zero
+	Before   string // only entries older than this cursor
+	After    string // only entries newer than this cursor (Follow)
+}
+
+// Validate checks the filter's units, priority, expression and limit.
+func (f *Filter) Validate() error {
+	for _, u := range f.Units {
+		if !slices.Contains(Units, u) {
+			return fmt.Errorf("unknown unit %q (use %s)", u, strings.Join(Units, ", "))
+		}
+	}
+	if f.Priority != "" {
+		if _, err := ParsePriority(f.Priority); err != nil {
+			return err
+		}
+	}
+	if _, err := f.grep(); err != nil {
+		return err
+	}
+	if f.Limit < 0 || f.Limit > MaxLimit {
+		return fmt.Errorf("limit must be between 0 and %d", MaxLimit)
+	}
+	if f.Before != "" && f.After != "" {
+		return errors.New("before and after cannot be combined")
+	}
+	return nil
+}
+
+func (f *Filter) grep() (*regexp.Regexp, error) {
+	if f.Grep == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile("(?i)" + f.Grep)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grep expression: %w", err)
+	}
+	return re, nil
+}
+
+func (f *Filter) units() []string {
+	if len(f.Units) == 0 {
+		return Units
+	}
+	return f.Units
+}
+
+// args returns the journalctl arguments selecting the filter's entries.
+func (f *Filter) args() []string {
+	args := []string{"--output=json", "--no-pager", "--quiet"}
+	for _, u := range f.units() {
+		args = append(args, "--unit="+u)
+	}
+	if f.Priority != "" {
+		p, _ := ParsePriority(f.Priority)
+		args = append(args, "--priority="+strconv.Itoa(p))
+	}
+	if !f.Since.IsZero() {
+		args = append(args, "--since=@"+strconv.FormatInt(f.Since.Unix(), 10))
+	}
+	if !f.Until.IsZero() {
+		args = append(args, "--until=@"+strconv.FormatInt(f.Until.Unix(), 10))
+	}
+	return args
+}
+
+// Page is a page of entries, newest first.
+type Page struct {
+	Entries []Entry `json:"entries"`
+	// Next is the cursor to pass as Before for the next, older page; empty
+	// on the last page.
+	Next string `json:"next,omitempty"`
+}
+
+// Query returns the newest entries matching f, newest first.
+func Query(ctx context.Context, f Filter) (*Page, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	limit := f.Limit
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+
+	args := append(f.args(), "--reverse")
+	if f.Before != "" {
+		// In reverse, --after-cursor skips the cursor entry backwards.
+		args = append(args, "--after-cursor="+f.Before)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, journalctl, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	// Read one entry more than asked for, to know if there is a next page.
+	page := &Page{Entries: []Entry{}}
+	more := false
+	err = read(out, &f, func(e Entry) bool {
+		if len(page.Entries) == limit {
+			more = true
+			return false
+		}
+		page.Entries = append(page.Entries, e)
+		return true
+	})
+	if more || err != nil {
+		cancel() // stop journalctl early
+	}
+	waitErr := cmd.Wait()
+	if err != nil {
+		return nil, err
+	}
+	if waitErr != nil && !more {
+		return nil, journalError(waitErr, stderr.String())
+	}
+	if more {
+		page.Next = page.Entries[len(page.Entries)-1].Cursor
+	}
+	return page, nil
+}
+
+// Follow delivers entries matching f as they are written, starting with
+// the last f.Limit existing ones (none if zero), or those after f.After.
+// The channel is closed when ctx is done or journalctl exits.
+func Follow(ctx context.Context, f Filter) (<-chan Entry, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	args := append(f.args(), "--follow")
+	if f.After != "" {
+		args = append(args, "--after-cursor="+f.After)
+	} else {
+		args = append(args, "--lines="+strconv.Itoa(f.Limit))
+	}
+
+	cmd := exec.CommandContext(ctx, journalctl, args...)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	entries := make(chan Entry)
+	go func() {
+		defer close(entries)
+		defer cmd.Wait()
+		read(out, &f, func(e Entry) bool {
+			select {
+			case entries <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return entries, nil
+}
+
+// read parses journalctl JSON output from r and passes the entries
+// matching f to yield until it returns false.
+func read(r io.Reader, f *Filter, yield func(Entry) bool) error {
+	re, err := f.grep()
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for scanner.Scan() {
+		e, ok := parseEntry(scanner.Bytes(), f.units())
+		if !ok || (f.Before != "" && e.Cursor == f.Before) {
+			continue
+		}
+		if re != nil && !re.MatchString(e.Message) {
+			continue
+		}
+		if !yield(e) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// journalEntry holds the journal fields an Entry is made of. journalctl
+// prints field values as strings, or as byte arrays when they are not
+// valid UTF-8.
+type journalEntry struct {
+	Cursor     string          `json:"__CURSOR"`
+	Realtime   string          `json:"__REALTIME_TIMESTAMP"`
+	Unit       string          `json:"_SYSTEMD_UNIT"`
+	ObjectUnit string          `json:"UNIT"` // systemd's own messages about a unit
+	Priority   string          `json:"PRIORITY"`
+	Identifier string          `json:"SYSLOG_IDENTIFIER"`
+	PID        string          `json:"_PID"`
+	Message    json.RawMessage `json:"MESSAGE"`
+}
+
+// parseEntry parses a line of journalctl JSON output, attributing it to
+// one of units.
+func parseEntry(line []byte, units []string) (Entry, bool) {
+	var je journalEntry
+	if err := json.Unmarshal(line, &je); err != nil {
+		return Entry{}, false
+	}
+	e := Entry{Cursor: je.Cursor, Identifier: je.Identifier, Priority: 6}
+	for _, u := range []string{je.Unit, je.ObjectUnit} {
+		if name := strings.TrimSuffix(u, ".service"); slices.Contains(units, name) {
+			e.Unit = name
+			break
+		}
+	}
+	if e.Unit == "" {
+		return Entry{}, false
+	}
+	if usec, err := strconv.ParseInt(je.Realtime, 10, 64); err == nil {
+		e.Time = time.UnixMicro(usec)
+	}
+	if p, err := strconv.Atoi(je.Priority); err == nil {
+		e.Priority = p
+	}
+	e.PID, _ = strconv.Atoi(je.PID)
+
+	var s string
+	var b []byte
+	switch {
+	case json.Unmarshal(je.Message, &s) == nil:
+		e.Message = s
+	case json.Unmarshal(je.Message, &b) == nil:
+		e.Message = strings.ToValidUTF8(string(b), "�")
+	}
+	return e, true
+}
+
+// journalError describes a failed journalctl run.
+func journalError(err error, stderr string) error {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("failed to read journal: %s", msg)
+	}
+	return fmt.Errorf("failed to read journal: %w", err)
+}
@@ -0,0 +1,177 @@
+package logs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Journal lines as journalctl --reverse prints them, newest first.
+const journal = `{"__CURSOR":"c3","__REALTIME_TIMESTAMP":"1771630200000000","_SYSTEMD_UNIT":"wpe-webkit-kiosk.service","PRIORITY":"3","SYSLOG_IDENTIFIER":"wpe-webkit-kiosk","_PID":"812","MESSAGE":"Load failed: https://example.com/ (Not found)"}
+{"__CURSOR":"c2","__REALTIME_TIMESTAMP":"1771630100000000","_SYSTEMD_UNIT":"init.scope","UNIT":"wpe-webkit-kiosk-api.service","PRIORITY":"6","SYSLOG_IDENTIFIER":"systemd","MESSAGE":"Started WPE WebKit Kiosk REST API."}
+{"__CURSOR":"cx","__REALTIME_TIMESTAMP":"1771630050000000","_SYSTEMD_UNIT":"ssh.service","PRIORITY":"6","MESSAGE":"Accepted publickey"}
+{"__CURSOR":"c1","__REALTIME_TIMESTAMP":"1771630000000000","_SYSTEMD_UNIT":"wpe-webkit-kiosk.service","PRIORITY":"6","MESSAGE":[75,105,111,115,107,32,255]}
+`
+
+// useJournal replaces journalctl with a script printing output and
+// recording its arguments, one per line, in the returned file.
+func useJournal(t *testing.T, output string, follow bool) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "journal"), []byte(output), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	argsFile := filepath.Join(dir, "args")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsFile + "\ncat " + filepath.Join(dir, "journal") + "\n"
+	if follow {
+		script += "exec sleep 60\n"
+	}
+	path := filepath.Join(dir, "journalctl")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	old := journalctl
+	journalctl = path
+	t.Cleanup(func() { journalctl = old })
+	return argsFile
+}
+
+func readArgs(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Fields(string(data))
+}
+
+func TestParseEntry(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(journal), "\n")
+
+	e, ok := parseEntry([]byte(lines[0]), Units)
+	if !ok || e.Unit != "wpe-webkit-kiosk" || e.Priority != 3 || e.PID != 812 || e.Cursor != "c3" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if !e.Time.Equal(time.UnixMicro(1771630200000000)) {
+		t.Errorf("unexpected time: %s", e.Time)
+	}
+
+	if e, ok := parseEntry([]byte(lines[1]), Units); !ok || e.Unit != "wpe-webkit-kiosk-api" {
+		t.Errorf("expected systemd message attributed to the API unit, got %+v", e)
+	}
+	if _, ok := parseEntry([]byte(lines[2]), Units); ok {
+		t.Error("expected entry of another unit to be rejected")
+	}
+	if e, ok := parseEntry([]byte(lines[3]), Units); !ok || e.Message != "Kiosk �" {
+		t.Errorf("expected binary message decoded, got %q", e.Message)
+	}
+	if _, ok := parseEntry([]byte(lines[0]), []string{"wpe-webkit-kiosk-vnc"}); ok {
+		t.Error("expected entry of an unselected unit to be rejected")
+	}
+}
+
+func TestQuery_PagesNewestFirst(t *testing.T) {
+	argsFile := useJournal(t, journal, false)
+
+	page, err := Query(context.Background(), Filter{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cursors []string
+	for _, e := range page.Entries {
+		cursors = append(cursors, e.Cursor)
+	}
+	if !slices.Equal(cursors, []string{"c3", "c2"}) || page.Next != "c2" {
+		t.Errorf("expected c3, c2 and next c2, got %v and next %q", cursors, page.Next)
+	}
+
+	page, err = Query(context.Background(), Filter{Before: "c2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Entries) != 2 || page.Entries[0].Cursor != "c3" || page.Next != "" {
+		t.Errorf("unexpected last page: %+v", page)
+	}
+	if args := readArgs(t, argsFile); !slices.Contains(args, "--after-cursor=c2") || !slices.Contains(args, "--reverse") {
+		t.Errorf("expected reverse read after the cursor, got %v", args)
+	}
+}
+
+func TestQuery_Filters(t *testing.T) {
+	argsFile := useJournal(t, journal, false)
+
+	since := time.Unix(1771630000, 0)
+	page, err := Query(context.Background(), Filter{
+		Units:    []string{"wpe-webkit-kiosk"},
+		Priority: "warning",
+		Since:    since,
+		Grep:     "LOAD FAILED",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Entries) != 1 || page.Entries[0].Cursor != "c3" {
+		t.Errorf("expected only c3, got %+v", page.Entries)
+	}
+
+	args := readArgs(t, argsFile)
+	for _, want := range []string{"--unit=wpe-webkit-kiosk", "--priority=4", "--since=@1771630000"} {
+		if !slices.Contains(args, want) {
+			t.Errorf("expected %s in %v", want, args)
+		}
+	}
+	if slices.Contains(args, "--unit=wpe-webkit-kiosk-api") {
+		t.Errorf("expected only the selected unit, got %v", args)
+	}
+}
+
+func TestFilter_Validate(t *testing.T) {
+	for _, f := range []Filter{
+		{Units: []string{"ssh"}},
+		{Priority: "loud"},
+		{Grep: "("},
+		{Limit: MaxLimit + 1},
+		{Before: "a", After: "b"},
+	} {
+		if err := f.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", f)
+		}
+	}
+	if err := (&Filter{Priority: "3", Units: []string{"wpe-webkit-kiosk-vnc"}}).Validate(); err != nil {
+		t.Errorf("expected valid filter, got %v", err)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	argsFile := useJournal(t, journal, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	entries, err := Follow(ctx, Filter{After: "c0", Priority: "err"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for len(got) < 3 {
+		select {
+		case e := <-entries:
+			got = append(got, e.Cursor)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out, got %v", got)
+		}
+	}
+	if !slices.Equal(got, []string{"c3", "c2", "c1"}) {
+		t.Errorf("unexpected entries: %v", got)
+	}
+
+	cancel()
+	for range entries {
+	}
+	if args := readArgs(t, argsFile); !slices.Contains(args, "--follow") || !slices.Contains(args, "--after-cursor=c0") {
+		t.Errorf("unexpected arguments: %v", args)
+	}
+}
@@ -0,0 +1,94 @@
+package playlist
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// pollInterval bounds how long a playlist change takes to be noticed.
+const pollInterval = 5 * time.Second
+
+// Driver rotates the kiosk through the playlist file while it is running.
+// The file is re-read on every step, so changes made by the CLI or the API
+// are picked up without restarting the driver.
+type Driver struct {
+	path string
+	open func(url string) error
+
+	// OnAdvance, if set, is called after the driver navigates to an entry.
+	OnAdvance func(e Entry)
+
+	idx   int
+	url   string
+	until time.Time
+}
+
+// NewDriver returns a driver for the playlist at path that shows pages
+// with open (normally dbus.Client.Open).
+func NewDriver(path string, open func(url string) error) *Driver {
+	return &Driver{path: path, open: open, idx: -1}
+}
+
+// Run drives the rotation until ctx is cancelled.
+func (d *Driver) Run(ctx context.Context) {
+	var lastErr string
+	for {
+		wait := pollInterval
+		p, err := Load(d.path)
+		if err != nil {
+			if err.Error() != lastErr {
+				log.Printf("Playlist: %v", err)
+			}
+			lastErr = err.Error()
+		} else {
+			lastErr = ""
+			wait = d.step(p, time.Now())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// step advances the rotation if the current entry's dwell time is over,
+// its window has closed or it was removed, and returns how long to
+// wait before the next step.
+func (d *Driver) step(p *Playlist, now time.Time) time.Duration {
+	if !p.Running || len(p.Entries) == 0 {
+		d.idx = -1
+		return pollInterval
+	}
+
+	// Entries added or removed around the current one move it; follow it.
+	if d.idx >= 0 && (d.idx >= len(p.Entries) || p.Entries[d.idx].URL != d.url) {
+		d.idx = p.index(d.url)
+	}
+
+	if d.idx < 0 || !now.Before(d.until) || !p.Entries[d.idx].Active(now) {
+		next := p.Next(d.idx, now)
+		if next < 0 {
+			d.idx = -1
+			return pollInterval
+		}
+
+		e := p.Entries[next]
+		// With a single active entry, keep the page instead of reloading it.
+		if d.idx < 0 || e.URL != d.url {
+			if err := d.open(e.URL); err != nil {
+				log.Printf("Playlist: cannot open %s: %v", e.URL, err)
+			} else if d.OnAdvance != nil {
+				d.OnAdvance(e)
+			}
+		}
+		d.idx, d.url, d.until = next, e.URL, now.Add(e.DwellDuration())
+	}
+
+	if wait := d.until.Sub(now); wait < pollInterval {
+		return wait
+	}
+	return pollInterval
+}
@@ -0,0 +1,228 @@
+package playlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/atomicfile"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+)
+
+const DefaultPath = "/etc/wpe-webkit-kiosk/playlist.json"
+
+// Dwell time limits, in seconds.
+const (
+	DefaultDwell = 60
+	MinDwell     = 5
+)
+
+// Entry is a page in the rotation.
+type Entry struct {
+	URL    string `json:"url"`
+	Dwell  int    `json:"dwell"`            // seconds the page stays on screen
+	Window string `json:"window,omitempty"` // "HH:MM-HH:MM" in local time; empty means always
+}
+
+// Validate checks the URL, dwell time and time window.
+func (e *Entry) Validate() error {
+	if err := config.Validate("URL", e.URL); err != nil {
+		return err
+	}
+	if e.Dwell < MinDwell {
+		return fmt.Errorf("dwell time must be at least %d seconds", MinDwell)
+	}
+	if e.Window != "" {
+		if _, _, err := parseWindow(e.Window); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DwellDuration returns how long the entry stays on screen.
+func (e *Entry) DwellDuration() time.Duration {
+	return time.Duration(e.Dwell) * time.Second
+}
+
+// Active reports whether the entry may be shown at now. A window whose end
+// is before its start spans midnight (e.g. "22:00-06:00").
+func (e *Entry) Active(now time.Time) bool {
+	if e.Window == "" {
+		return true
+	}
+	from, to, err := parseWindow(e.Window)
+	if err != nil {
+		return false
+	}
+	t := now.Hour()*60 + now.Minute()
+	if from <= to {
+		return t >= from && t < to
+	}
+	return t >= from || t < to
+}
+
+// parseWindow returns the window bounds in minutes after midnight.
+func parseWindow(w string) (from, to int, err error) {
+	start, end, ok := strings.Cut(w, "-")
+	if ok {
+		from, err = parseClock(strings.TrimSpace(start))
+		if err == nil {
+			to, err = parseClock(strings.TrimSpace(end))
+		}
+	}
+	if !ok || err != nil || from == to {
+		return 0, 0, fmt.Errorf("invalid time window %q (expected HH:MM-HH:MM, e.g. 08:00-18:00)", w)
+	}
+	return from, to, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Playlist is the ordered list of pages the kiosk rotates through while
+// Running is set.
+type Playlist struct {
+	Running bool    `json:"running"`
+	Entries []Entry `json:"entries"`
+	path    string
+}
+
+// Load reads the playlist at path. A missing file yields an empty,
+// stopped playlist.
+func Load(path string) (*Playlist, error) {
+	p := &Playlist{path: path, Entries: []Entry{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return p, nil
+		}
+		return nil, fmt.Errorf("cannot read playlist: %w", err)
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("invalid playlist: %w", err)
+	}
+	if p.Entries == nil {
+		p.Entries = []Entry{}
+	}
+	return p, nil
+}
+
+// Add validates e and inserts it at the 1-based position pos, or appends it
+// if pos is 0.
+func (p *Playlist) Add(e Entry, pos int) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	if pos == 0 {
+		p.Entries = append(p.Entries, e)
+		return nil
+	}
+	if pos < 1 || pos > len(p.Entries)+1 {
+		return fmt.Errorf("position %d is out of range (1-%d)", pos, len(p.Entries)+1)
+	}
+	p.Entries = append(p.Entries[:pos-1], append([]Entry{e}, p.Entries[pos-1:]...)...)
+	return nil
+}
+
+// Remove deletes the entry at the 1-based position pos and returns it.
+func (p *Playlist) Remove(pos int) (Entry, error) {
+	if pos < 1 || pos > len(p.Entries) {
+		return Entry{}, fmt.Errorf("no playlist entry at position %d", pos)
+	}
+	e := p.Entries[pos-1]
+	p.Entries = append(p.Entries[:pos-1], p.Entries[pos:]...)
+	return e, nil
+}
+
+// Next returns the index of the first entry after index i that is active
+// at now, wrapping around and ending with i itself. It returns -1 if no
+// entry is active. Pass -1 to start from the top.
+func (p *Playlist) Next(i int, now time.Time) int {
+	n := len(p.Entries)
+	if i < -1 || i >= n {
+		i = -1
+	}
+	for step := 1; step <= n; step++ {
+		j := (i + step) % n
+		if i == -1 {
+			j = step - 1
+		}
+		if p.Entries[j].Active(now) {
+			return j
+		}
+	}
+	return -1
+}
+
+func (p *Playlist) index(url string) int {
+	for i, e := range p.Entries {
+		if e.URL == url {
+			return i
+		}
+	}
+	return -1
+}
+
+// Status is the playlist together with the entry on screen and the one
+// that follows it. Positions are 1-based; nil means none.
+type Status struct {
+	Running bool    `json:"running"`
+	Current *int    `json:"current"`
+	Next    *int    `json:"next"`
+	Entries []Entry `json:"entries"`
+}
+
+// Status reports the playlist state given the URL the kiosk shows at now.
+// The current entry is the first active one showing url; the next entry is
+// only reported while the playlist is running.
+func (p *Playlist) Status(url string, now time.Time) Status {
+	st := Status{Running: p.Running, Entries: p.Entries}
+
+	cur := -1
+	for i := range p.Entries {
+		if p.Entries[i].URL != url {
+			continue
+		}
+		if p.Entries[i].Active(now) {
+			cur = i
+			break
+		}
+		if cur < 0 {
+			cur = i
+		}
+	}
+	if cur >= 0 {
+		pos := cur + 1
+		st.Current = &pos
+	}
+	if p.Running {
+		if next := p.Next(cur, now); next >= 0 {
+			pos := next + 1
+			st.Next = &pos
+		}
+	}
+	return st
+}
+
+// Save writes the playlist back to disk atomically, through sudo if needed.
+func (p *Playlist) Save() error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if err := atomicfile.Write(p.path, data, 0644); err != nil {
+		return fmt.Errorf("cannot write playlist: %w", err)
+	}
+	return nil
+}
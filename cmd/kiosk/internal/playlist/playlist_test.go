@@ -0,0 +1,219 @@
+package playlist
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func at(hour, min int) time.Time {
+	return time.Date(2026, 3, 2, hour, min, 0, 0, time.Local)
+}
+
+func TestEntryActiveWindow(t *testing.T) {
+	day := Entry{URL: "https://a.example", Dwell: 10, Window: "08:00-18:00"}
+	night := Entry{URL: "https://b.example", Dwell: 10, Window: "22:00-06:00"}
+
+	tests := []struct {
+		e    Entry
+		now  time.Time
+		want bool
+	}{
+		{day, at(7, 59), false},
+		{day, at(8, 0), true},
+		{day, at(17, 59), true},
+		{day, at(18, 0), false},
+		{night, at(23, 0), true},
+		{night, at(5, 59), true},
+		{night, at(12, 0), false},
+		{Entry{URL: "https://c.example", Dwell: 10}, at(3, 0), true},
+	}
+	for _, tt := range tests {
+		if got := tt.e.Active(tt.now); got != tt.want {
+			t.Errorf("%s at %s: got %v, want %v", tt.e.Window, tt.now.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestEntryValidate(t *testing.T) {
+	bad := []Entry{
+		{URL: "not a url", Dwell: 10},
+		{URL: "https://a.example", Dwell: 1},
+		{URL: "https://a.example", Dwell: 10, Window: "8-18"},
+		{URL: "https://a.example", Dwell: 10, Window: "08:00-08:00"},
+		{URL: "https://a.example", Dwell: 10, Window: "08:00-25:00"},
+	}
+	for _, e := range bad {
+		if err := e.Validate(); err == nil {
+			t.Errorf("expected error for %+v", e)
+		}
+	}
+	if err := (&Entry{URL: "https://a.example", Dwell: 10, Window: "08:00-18:00"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAddRemovePositions(t *testing.T) {
+	p := &Playlist{}
+	for _, u := range []string{"https://a.example", "https://c.example"} {
+		if err := p.Add(Entry{URL: u, Dwell: 10}, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Add(Entry{URL: "https://b.example", Dwell: 10}, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Add(Entry{URL: "https://d.example", Dwell: 10}, 5); err == nil {
+		t.Error("expected error for position out of range")
+	}
+	if got := p.Entries[1].URL; got != "https://b.example" {
+		t.Errorf("expected b at position 2, got %s", got)
+	}
+
+	e, err := p.Remove(1)
+	if err != nil || e.URL != "https://a.example" {
+		t.Fatalf("Remove(1) = %+v, %v", e, err)
+	}
+	if len(p.Entries) != 2 || p.Entries[0].URL != "https://b.example" {
+		t.Errorf("unexpected entries after remove: %+v", p.Entries)
+	}
+	if _, err := p.Remove(3); err == nil {
+		t.Error("expected error for missing position")
+	}
+}
+
+func TestNextSkipsInactiveEntries(t *testing.T) {
+	p := &Playlist{Entries: []Entry{
+		{URL: "https://a.example", Dwell: 10},
+		{URL: "https://b.example", Dwell: 10, Window: "08:00-09:00"},
+		{URL: "https://c.example", Dwell: 10},
+	}}
+
+	if got := p.Next(-1, at(12, 0)); got != 0 {
+		t.Errorf("Next(-1) = %d, want 0", got)
+	}
+	if got := p.Next(0, at(12, 0)); got != 2 {
+		t.Errorf("Next(0) outside window = %d, want 2", got)
+	}
+	if got := p.Next(0, at(8, 30)); got != 1 {
+		t.Errorf("Next(0) inside window = %d, want 1", got)
+	}
+	if got := p.Next(2, at(12, 0)); got != 0 {
+		t.Errorf("Next(2) = %d, want 0 (wrap around)", got)
+	}
+
+	only := &Playlist{Entries: []Entry{{URL: "https://b.example", Dwell: 10, Window: "08:00-09:00"}}}
+	if got := only.Next(-1, at(12, 0)); got != -1 {
+		t.Errorf("expected no active entry, got %d", got)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	p := &Playlist{Running: true, Entries: []Entry{
+		{URL: "https://a.example", Dwell: 10},
+		{URL: "https://b.example", Dwell: 10},
+	}}
+
+	st := p.Status("https://b.example", at(12, 0))
+	if st.Current == nil || *st.Current != 2 || st.Next == nil || *st.Next != 1 {
+		t.Errorf("unexpected status %+v", st)
+	}
+
+	st = p.Status("https://elsewhere.example", at(12, 0))
+	if st.Current != nil || st.Next == nil || *st.Next != 1 {
+		t.Errorf("unexpected status for unknown URL %+v", st)
+	}
+
+	p.Running = false
+	if st := p.Status("https://a.example", at(12, 0)); st.Next != nil {
+		t.Errorf("expected no next entry while stopped, got %d", *st.Next)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "playlist.json")
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Running || len(p.Entries) != 0 {
+		t.Fatalf("expected empty stopped playlist, got %+v", p)
+	}
+
+	p.Running = true
+	p.Add(Entry{URL: "https://a.example", Dwell: 30, Window: "08:00-18:00"}, 0)
+	if err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Running || len(loaded.Entries) != 1 || loaded.Entries[0] != p.Entries[0] {
+		t.Errorf("round trip mismatch: %+v", loaded)
+	}
+}
+
+func TestDriverStep(t *testing.T) {
+	var opened []string
+	d := NewDriver("", func(url string) error {
+		opened = append(opened, url)
+		return nil
+	})
+	p := &Playlist{Running: true, Entries: []Entry{
+		{URL: "https://a.example", Dwell: 10},
+		{URL: "https://b.example", Dwell: 20},
+	}}
+	now := at(12, 0)
+
+	if wait := d.step(p, now); wait != pollInterval {
+		t.Errorf("expected poll interval wait, got %s", wait)
+	}
+	now = now.Add(10 * time.Second)
+	d.step(p, now)
+	now = now.Add(5 * time.Second)
+	if wait := d.step(p, now); wait != pollInterval {
+		t.Errorf("expected no advance mid-dwell, got wait %s", wait)
+	}
+	now = now.Add(15 * time.Second)
+	d.step(p, now)
+
+	want := []string{"https://a.example", "https://b.example", "https://a.example"}
+	if len(opened) != len(want) {
+		t.Fatalf("opened %v, want %v", opened, want)
+	}
+	for i := range want {
+		if opened[i] != want[i] {
+			t.Fatalf("opened %v, want %v", opened, want)
+		}
+	}
+
+	// Inserting an entry before the current one keeps the rotation in place.
+	p.Entries = append([]Entry{{URL: "https://new.example", Dwell: 10}}, p.Entries...)
+	now = now.Add(time.Second)
+	d.step(p, now)
+	if len(opened) != 3 {
+		t.Errorf("expected no navigation after insert, got %v", opened)
+	}
+
+	// A single active entry is not reloaded when its dwell time ends.
+	single := &Playlist{Running: true, Entries: []Entry{{URL: "https://a.example", Dwell: 10}}}
+	d2 := NewDriver("", func(url string) error {
+		opened = append(opened, url)
+		return nil
+	})
+	opened = nil
+	d2.step(single, now)
+	d2.step(single, now.Add(time.Minute))
+	if len(opened) != 1 {
+		t.Errorf("expected a single navigation, got %v", opened)
+	}
+
+	// Stopping resets the rotation.
+	p.Running = false
+	d.step(p, now)
+	if d.idx != -1 {
+		t.Errorf("expected rotation reset after stop, got index %d", d.idx)
+	}
+}
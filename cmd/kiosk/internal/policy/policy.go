@@ -0,0 +1,204 @@
+// Package policy decides which URLs the kiosk may navigate to.
+//
+// The policy is kept in the config as two space-separated lists of
+// patterns, NAV_ALLOW and NAV_DENY. A URL is blocked if it matches a deny
+// pattern, or if the allowlist is not empty and it matches no allow
+// pattern. The kiosk process applies the same rules to every navigation
+// (src/kiosk.c); this package checks API and CLI requests up front and
+// explains decisions for "kiosk policy test".
+package policy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Config keys holding the policy.
+const (
+	AllowKey = "NAV_ALLOW"
+	DenyKey  = "NAV_DENY"
+)
+
+// alwaysAllowed are internal pages that frames rely on and that can never
+// leave the kiosk.
+var alwaysAllowed = map[string]bool{"about:blank": true, "about:srcdoc": true}
+
+// Rule is one allow or deny pattern. Patterns use * (any characters,
+// including /) and ? (one character) as wildcards and take one of these
+// forms:
+//
+//	example.com, *.example.com     host, any scheme and path
+//	example.com/shop/*             host and path, any scheme
+//	https://example.com            scheme and host, any path
+//	https://example.com/shop/*     scheme, host and path
+//	file:///opt/app/*, about:*     URLs without a host
+type Rule struct {
+	Pattern string `json:"pattern"`
+	List    string `json:"list"` // "allow" or "deny"
+}
+
+// hostOnly reports whether the rule matches hosts rather than URLs.
+func (r Rule) hostOnly() bool {
+	return !strings.ContainsAny(r.Pattern, ":/")
+}
+
+// urlPattern returns the pattern matched against the normalized URL.
+func (r Rule) urlPattern() string {
+	p := r.Pattern
+	scheme, rest, ok := strings.Cut(p, "://")
+	if !ok {
+		if strings.Contains(p, ":") {
+			return p // about:*, data:*
+		}
+		return "*://" + p
+	}
+	if !strings.Contains(rest, "/") {
+		rest += "/*"
+	}
+	return strings.ToLower(scheme) + "://" + rest
+}
+
+// Matches reports whether the parsed URL u matches the rule.
+func (r Rule) Matches(u *url.URL) bool {
+	if r.hostOnly() {
+		return u.Opaque == "" && match(strings.ToLower(r.Pattern), strings.ToLower(u.Hostname()))
+	}
+	return match(r.urlPattern(), normalize(u))
+}
+
+// normalize returns the form of u that URL patterns are matched against:
+// scheme://host/path, or scheme:opaque for URLs without a host. The port,
+// query and fragment are left out.
+func normalize(u *url.URL) string {
+	if u.Opaque != "" {
+		return strings.ToLower(u.Scheme) + ":" + u.Opaque
+	}
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Hostname()) + path
+}
+
+// CheckPattern reports whether p is a usable pattern.
+func CheckPattern(p string) error {
+	if scheme, _, ok := strings.Cut(p, "://"); ok && scheme == "" {
+		return fmt.Errorf("pattern %q has an empty scheme", p)
+	}
+	if strings.HasPrefix(p, ":") {
+		return fmt.Errorf("pattern %q has an empty scheme", p)
+	}
+	return nil
+}
+
+// Policy is a parsed navigation policy.
+type Policy struct {
+	Allow []Rule `json:"allow"`
+	Deny  []Rule `json:"deny"`
+}
+
+// New parses the space-separated allow and deny lists.
+func New(allow, deny string) (*Policy, error) {
+	p := &Policy{Allow: []Rule{}, Deny: []Rule{}}
+	for _, f := range strings.Fields(allow) {
+		if err := CheckPattern(f); err != nil {
+			return nil, fmt.Errorf("%s: %w", AllowKey, err)
+		}
+		p.Allow = append(p.Allow, Rule{Pattern: f, List: "allow"})
+	}
+	for _, f := range strings.Fields(deny) {
+		if err := CheckPattern(f); err != nil {
+			return nil, fmt.Errorf("%s: %w", DenyKey, err)
+		}
+		p.Deny = append(p.Deny, Rule{Pattern: f, List: "deny"})
+	}
+	return p, nil
+}
+
+// FromValues builds the policy from config values, e.g. cfg.Get.
+func FromValues(get func(key string) string) (*Policy, error) {
+	return New(get(AllowKey), get(DenyKey))
+}
+
+// Decision is the outcome of checking a URL against the policy.
+type Decision struct {
+	URL     string `json:"url"`
+	Allowed bool   `json:"allowed"`
+	Rule    *Rule  `json:"rule"` // the rule that decided, nil if none did
+	Reason  string `json:"reason"`
+}
+
+// Check decides whether the kiosk may navigate to rawURL.
+func (p *Policy) Check(rawURL string) Decision {
+	d := Decision{URL: rawURL}
+	if alwaysAllowed[rawURL] {
+		d.Allowed, d.Reason = true, "internal page, always allowed"
+		return d
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		d.Allowed = len(p.Allow) == 0
+		d.Reason = "cannot parse URL"
+		return d
+	}
+
+	for i := range p.Deny {
+		if p.Deny[i].Matches(u) {
+			d.Rule = &p.Deny[i]
+			d.Reason = fmt.Sprintf("matches deny rule %q", p.Deny[i].Pattern)
+			return d
+		}
+	}
+	if len(p.Allow) == 0 {
+		d.Allowed, d.Reason = true, "no allowlist, not denied"
+		return d
+	}
+	for i := range p.Allow {
+		if p.Allow[i].Matches(u) {
+			d.Allowed, d.Rule = true, &p.Allow[i]
+			d.Reason = fmt.Sprintf("matches allow rule %q", p.Allow[i].Pattern)
+			return d
+		}
+	}
+	d.Reason = "matches no allow rule"
+	return d
+}
+
+// Err returns an error describing a blocked decision, or nil if the URL
+// is allowed.
+func (d Decision) Err() error {
+	if d.Allowed {
+		return nil
+	}
+	return fmt.Errorf("navigation to %s blocked by policy: %s", d.URL, d.Reason)
+}
+
+// match reports whether s matches pattern, where * matches any run of
+// characters and ? a single character, like g_pattern_match_simple used
+// by the kiosk.
+func match(pattern, s string) bool {
+	p, t := []rune(pattern), []rune(s)
+	pi, ti := 0, 0
+	star, mark := -1, 0
+	for ti < len(t) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == t[ti]):
+			pi++
+			ti++
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, ti
+			pi++
+		case star >= 0:
+			pi = star + 1
+			mark++
+			ti = mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
@@ -0,0 +1,82 @@
+package policy
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"example.com", "example.com", true},
+		{"*.example.com", "shop.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"https://*/shop/*", "https://example.com/shop/a/b", true},
+		{"exa?ple.com", "example.com", true},
+		{"*", "", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+	}
+	for _, tt := range tests {
+		if got := match(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("match(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p, err := New(
+		"example.com *.example.com https://partner.org/kiosk/* file:///opt/app/*",
+		"example.com/admin/* http://*",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		url     string
+		allowed bool
+		rule    string
+	}{
+		{"https://example.com/", true, "example.com"},
+		{"https://EXAMPLE.com:8443/page?q=1", true, "example.com"},
+		{"https://shop.example.com/cart", true, "*.example.com"},
+		{"https://example.com/admin/users", false, "example.com/admin/*"},
+		{"http://example.com/", false, "http://*"},
+		{"https://partner.org/kiosk/menu", true, "https://partner.org/kiosk/*"},
+		{"https://partner.org/other", false, ""},
+		{"file:///opt/app/index.html", true, "file:///opt/app/*"},
+		{"file:///etc/passwd", false, ""},
+		{"https://evil.com/", false, ""},
+		{"about:blank", true, ""},
+	}
+	for _, tt := range tests {
+		d := p.Check(tt.url)
+		if d.Allowed != tt.allowed {
+			t.Errorf("%s: allowed = %v, want %v (%s)", tt.url, d.Allowed, tt.allowed, d.Reason)
+		}
+		rule := ""
+		if d.Rule != nil {
+			rule = d.Rule.Pattern
+		}
+		if rule != tt.rule {
+			t.Errorf("%s: rule = %q, want %q", tt.url, rule, tt.rule)
+		}
+	}
+}
+
+func TestCheckWithoutAllowlist(t *testing.T) {
+	p, _ := New("", "*.ads.example")
+	if d := p.Check("https://anything.org/"); !d.Allowed || d.Err() != nil {
+		t.Errorf("expected allowed without allowlist, got %+v", d)
+	}
+	d := p.Check("https://x.ads.example/")
+	if d.Allowed || d.Err() == nil {
+		t.Errorf("expected denied, got %+v", d)
+	}
+}
+
+func TestNewRejectsEmptyScheme(t *testing.T) {
+	if _, err := New("://example.com", ""); err == nil {
+		t.Error("expected error for empty scheme")
+	}
+}
@@ -0,0 +1,63 @@
+package schedule
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Runner executes the entries of the schedule file as they fall due. The
+// file is re-read every minute, so changes need no restart.
+type Runner struct {
+	path string
+	exec func(e Entry) error
+}
+
+// NewRunner returns a runner for the schedule at path that performs
+// actions with exec.
+func NewRunner(path string, exec func(e Entry) error) *Runner {
+	return &Runner{path: path, exec: exec}
+}
+
+// Run executes due entries until ctx is cancelled. Minutes missed while
+// the process was not running (or the machine was suspended) are skipped,
+// not caught up.
+func (r *Runner) Run(ctx context.Context) {
+	var lastErr string
+	last := time.Now().Truncate(time.Minute)
+	for {
+		next := last.Add(time.Minute)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+
+		now := time.Now().Truncate(time.Minute)
+		if now.Before(next) {
+			continue // woke up early
+		}
+		last = now
+
+		s, err := Load(r.path)
+		if err != nil {
+			if err.Error() != lastErr {
+				log.Printf("Schedule: %v", err)
+			}
+			lastErr = err.Error()
+			continue
+		}
+		lastErr = ""
+		r.runDue(s, now)
+	}
+}
+
+func (r *Runner) runDue(s *Schedule, t time.Time) {
+	for _, e := range s.Due(t) {
+		if err := r.exec(e); err != nil {
+			log.Printf("Schedule: %s failed: %v", e.String(), err)
+		} else {
+			log.Printf("Schedule: %s", e.String())
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package schedule
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/atomicfile"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
+)
+
+const DefaultPath = "/etc/wpe-webkit-kiosk/schedule"
+
+// Scheduled actions.
+const (
+	ActionNavigate = "navigate" // open the URL given as argument
+	ActionRestart  = "restart"  // restart the kiosk service
+	ActionStop     = "stop"     // stop the kiosk service
+	ActionStart    = "start"    // start the kiosk service
+	ActionMute     = "mute"
+	ActionUnmute   = "unmute"
+	ActionClear    = "clear" // clear browsing data: cache, cookies or all (default)
+)
+
+// Actions lists the valid actions in the order they are documented.
+var Actions = []string{ActionNavigate, ActionRestart, ActionStop, ActionStart, ActionMute, ActionUnmute, ActionClear}
+
+var clearScopes = map[string]bool{"cache": true, "cookies": true, "all": true}
+
+// Entry is one scheduled action. In the schedule file it is a line of the
+// form "<cron expression> <action> [argument]", e.g.
+//
+//	0 22 * * *    stop
+//	30 7 * * 1-5  navigate https://example.com/opening
+type Entry struct {
+	Spec   string `json:"spec"`
+	Action string `json:"action"`
+	Arg    string `json:"arg,omitempty"`
+}
+
+// Validate checks the cron expression, the action and its argument.
+func (e *Entry) Validate() error {
+	if _, err := ParseSpec(e.Spec); err != nil {
+		return err
+	}
+	switch e.Action {
+	case ActionNavigate:
+		if e.Arg == "" {
+			return fmt.Errorf("action %q needs a URL", e.Action)
+		}
+		return config.Validate("URL", e.Arg)
+	case ActionClear:
+		if e.Arg != "" && !clearScopes[e.Arg] {
+			return fmt.Errorf("invalid clear scope %q (valid: cache, cookies, all)", e.Arg)
+		}
+	case ActionRestart, ActionStop, ActionStart, ActionMute, ActionUnmute:
+		if e.Arg != "" {
+			return fmt.Errorf("action %q takes no argument", e.Action)
+		}
+	default:
+		return fmt.Errorf("unknown action %q (valid: %s)", e.Action, strings.Join(Actions, ", "))
+	}
+	return nil
+}
+
+func (e *Entry) String() string {
+	s := e.Spec + " " + e.Action
+	if e.Arg != "" {
+		s += " " + e.Arg
+	}
+	return s
+}
+
+// parseLine splits a schedule line into its entry. Macros take one field,
+// cron expressions five.
+func parseLine(line string) (Entry, error) {
+	parts := strings.Fields(line)
+	n := 5
+	if len(parts) > 0 && strings.HasPrefix(parts[0], "@") {
+		n = 1
+	}
+	if len(parts) < n+1 {
+		return Entry{}, fmt.Errorf("expected a cron expression followed by an action")
+	}
+	e := Entry{
+		Spec:   strings.Join(parts[:n], " "),
+		Action: parts[n],
+		Arg:    strings.Join(parts[n+1:], " "),
+	}
+	return e, e.Validate()
+}
+
+// Schedule is the list of scheduled actions kept in the schedule file.
+type Schedule struct {
+	Entries []Entry
+	header  []string // leading comment lines, kept on save
+	path    string
+}
+
+// Load reads the schedule file at path. A missing file yields an empty
+// schedule. Blank lines and lines starting with # are ignored.
+func Load(path string) (*Schedule, error) {
+	s := &Schedule{path: path, Entries: []Entry{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("cannot read schedule: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			if len(s.Entries) == 0 {
+				s.header = append(s.header, scanner.Text())
+			}
+			continue
+		}
+		e, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %s line %d: %w", path, n, err)
+		}
+		s.Entries = append(s.Entries, e)
+	}
+	return s, scanner.Err()
+}
+
+// Add validates e and appends it.
+func (s *Schedule) Add(e Entry) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	s.Entries = append(s.Entries, e)
+	return nil
+}
+
+// Remove deletes the entry at the 1-based position pos and returns it.
+func (s *Schedule) Remove(pos int) (Entry, error) {
+	if pos < 1 || pos > len(s.Entries) {
+		return Entry{}, fmt.Errorf("no schedule entry at position %d", pos)
+	}
+	e := s.Entries[pos-1]
+	s.Entries = append(s.Entries[:pos-1], s.Entries[pos:]...)
+	return e, nil
+}
+
+// Occurrence is a future run of a scheduled entry.
+type Occurrence struct {
+	Time     time.Time `json:"time"`
+	Position int       `json:"position"` // 1-based position of the entry
+	Entry
+}
+
+// Upcoming returns the next n runs after now across all entries, earliest
+// first.
+func (s *Schedule) Upcoming(now time.Time, n int) []Occurrence {
+	result := []Occurrence{}
+	if n <= 0 {
+		return result
+	}
+	for i, e := range s.Entries {
+		spec, err := ParseSpec(e.Spec)
+		if err != nil {
+			continue
+		}
+		t := now
+		for k := 0; k < n; k++ {
+			if t = spec.Next(t); t.IsZero() {
+				break
+			}
+			result = append(result, Occurrence{Time: t, Position: i + 1, Entry: e})
+		}
+	}
+	sort.SliceStable(result, func(a, b int) bool { return result[a].Time.Before(result[b].Time) })
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// Listing is the schedule as shown by "kiosk schedule list" and
+// GET /schedule: the entries with their positions and the next runs.
+type Listing struct {
+	Entries  []Listed     `json:"entries"`
+	Upcoming []Occurrence `json:"upcoming"`
+}
+
+// Listed is an entry with its 1-based position.
+type Listed struct {
+	Position int `json:"position"`
+	Entry
+}
+
+// Listing returns the entries and their next n runs after now.
+func (s *Schedule) Listing(now time.Time, n int) Listing {
+	l := Listing{Entries: make([]Listed, len(s.Entries)), Upcoming: s.Upcoming(now, n)}
+	for i, e := range s.Entries {
+		l.Entries[i] = Listed{Position: i + 1, Entry: e}
+	}
+	return l
+}
+
+// Due returns the entries that fire in the minute containing t.
+func (s *Schedule) Due(t time.Time) []Entry {
+	var due []Entry
+	for _, e := range s.Entries {
+		if spec, err := ParseSpec(e.Spec); err == nil && spec.Matches(t) {
+			due = append(due, e)
+		}
+	}
+	return due
+}
+
+// Save writes the schedule back to disk, keeping the leading comments.
+// The file is replaced atomically, through sudo if needed.
+func (s *Schedule) Save() error {
+	var buf bytes.Buffer
+	for _, line := range s.header {
+		buf.WriteString(line + "\n")
+	}
+	for _, e := range s.Entries {
+		buf.WriteString(e.String() + "\n")
+	}
+
+	if err := atomicfile.Write(s.path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("cannot write schedule: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package schedule
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(day, hour, min int) time.Time {
+	// March 2026: the 2nd is a Monday.
+	return time.Date(2026, 3, day, hour, min, 0, 0, time.UTC)
+}
+
+func TestParseSpecErrors(t *testing.T) {
+	bad := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"@sometimes",
+	}
+	for _, expr := range bad {
+		if _, err := ParseSpec(expr); err == nil {
+			t.Errorf("expected error for %q", expr)
+		}
+	}
+}
+
+func TestSpecNext(t *testing.T) {
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"0 22 * * *", date(2, 21, 59), date(2, 22, 0)},
+		{"0 22 * * *", date(2, 22, 0), date(3, 22, 0)},
+		{"*/15 * * * *", date(2, 10, 7), date(2, 10, 15)},
+		{"30 7 * * 1-5", date(6, 8, 0), date(9, 7, 30)}, // Friday after 7:30 -> Monday
+		{"0 0 1 * *", date(2, 0, 0), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", date(2, 0, 0), date(8, 9, 0)}, // 7 is Sunday
+		{"@daily", date(2, 12, 0), date(3, 0, 0)},
+		{"0 12 1 * 1", date(2, 13, 0), date(9, 12, 0)}, // both day fields: either matches
+		{"5/20 * * * *", date(2, 10, 6), date(2, 10, 25)},
+	}
+	for _, tt := range tests {
+		spec, err := ParseSpec(tt.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.expr, err)
+		}
+		if got := spec.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q after %s: got %s, want %s", tt.expr, tt.from, got, tt.want)
+		}
+	}
+
+	never, _ := ParseSpec("0 0 30 2 *")
+	if got := never.Next(date(2, 0, 0)); !got.IsZero() {
+		t.Errorf("expected no run for 30 February, got %s", got)
+	}
+}
+
+func TestEntryValidate(t *testing.T) {
+	valid := []Entry{
+		{Spec: "0 22 * * *", Action: ActionStop},
+		{Spec: "0 7 * * *", Action: ActionNavigate, Arg: "https://example.com"},
+		{Spec: "@daily", Action: ActionClear},
+		{Spec: "@daily", Action: ActionClear, Arg: "cookies"},
+	}
+	for _, e := range valid {
+		if err := e.Validate(); err != nil {
+			t.Errorf("%+v: unexpected error %v", e, err)
+		}
+	}
+
+	invalid := []Entry{
+		{Spec: "0 22 * *", Action: ActionStop},
+		{Spec: "0 22 * * *", Action: "reboot"},
+		{Spec: "0 22 * * *", Action: ActionNavigate},
+		{Spec: "0 22 * * *", Action: ActionNavigate, Arg: "not a url"},
+		{Spec: "0 22 * * *", Action: ActionMute, Arg: "now"},
+		{Spec: "0 22 * * *", Action: ActionClear, Arg: "everything"},
+	}
+	for _, e := range invalid {
+		if err := e.Validate(); err == nil {
+			t.Errorf("%+v: expected error", e)
+		}
+	}
+}
+
+func TestLoadSaveKeepsHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule")
+	content := "# Store hours\n\n0 22 * * * stop\n@daily clear cache\n30 7 * * 1-5 navigate https://example.com/open\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", s.Entries)
+	}
+	if e := s.Entries[2]; e.Spec != "30 7 * * 1-5" || e.Action != ActionNavigate || e.Arg != "https://example.com/open" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+
+	if _, err := s.Remove(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(Entry{Spec: "0 6 * * *", Action: ActionStart}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	want := "# Store hours\n\n@daily clear cache\n30 7 * * 1-5 navigate https://example.com/open\n0 6 * * * start\n"
+	if string(data) != want {
+		t.Errorf("saved schedule:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestLoadRejectsInvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule")
+	os.WriteFile(path, []byte("0 22 * * * stop\n0 25 * * * start\n"), 0644)
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error for line 2, got %v", err)
+	}
+}
+
+func TestUpcomingAndDue(t *testing.T) {
+	s := &Schedule{Entries: []Entry{
+		{Spec: "0 22 * * *", Action: ActionStop},
+		{Spec: "0 6 * * *", Action: ActionStart},
+	}}
+
+	got := s.Upcoming(date(2, 12, 0), 3)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 occurrences, got %d", len(got))
+	}
+	want := []struct {
+		t   time.Time
+		pos int
+	}{{date(2, 22, 0), 1}, {date(3, 6, 0), 2}, {date(3, 22, 0), 1}}
+	for i, w := range want {
+		if !got[i].Time.Equal(w.t) || got[i].Position != w.pos {
+			t.Errorf("occurrence %d: got %s #%d, want %s #%d", i, got[i].Time, got[i].Position, w.t, w.pos)
+		}
+	}
+
+	if due := s.Due(date(2, 22, 0).Add(30 * time.Second)); len(due) != 1 || due[0].Action != ActionStop {
+		t.Errorf("expected stop to be due at 22:00, got %+v", due)
+	}
+	if due := s.Due(date(2, 22, 1)); len(due) != 0 {
+		t.Errorf("expected nothing due at 22:01, got %+v", due)
+	}
+}
@@ -0,0 +1,115 @@
+// Package screenshot post-processes the PNG snapshots taken by the kiosk:
+// downscaling and JPEG encoding for archiving many kiosks' screens.
+package screenshot
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+// MinScale is the smallest scale factor accepted.
+const MinScale = 0.1
+
+// Options control how a snapshot is returned. The zero value returns the
+// kiosk's PNG unchanged.
+type Options struct {
+	Scale   float64 // factor between MinScale and 1; 0 means 1
+	Quality int     // JPEG quality 1-100; 0 keeps PNG
+}
+
+// Validate checks the scale and quality ranges.
+func (o Options) Validate() error {
+	if o.Scale != 0 && (o.Scale < MinScale || o.Scale > 1) {
+		return fmt.Errorf("scale must be between %g and 1", MinScale)
+	}
+	if o.Quality < 0 || o.Quality > 100 {
+		return fmt.Errorf("quality must be between 1 and 100")
+	}
+	return nil
+}
+
+// ContentType is the MIME type of images produced with o.
+func (o Options) ContentType() string {
+	if o.Quality > 0 {
+		return "image/jpeg"
+	}
+	return "image/png"
+}
+
+// Ext is the file extension of images produced with o.
+func (o Options) Ext() string {
+	if o.Quality > 0 {
+		return ".jpg"
+	}
+	return ".png"
+}
+
+// Encode applies o to the PNG data captured by the kiosk.
+func Encode(data []byte, o Options) ([]byte, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	scaled := o.Scale != 0 && o.Scale != 1
+	if !scaled && o.Quality == 0 {
+		return data, nil
+	}
+
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	img := src
+	if scaled {
+		img = downscale(src, o.Scale)
+	}
+
+	var buf bytes.Buffer
+	if o.Quality > 0 {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: o.Quality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// downscale shrinks img by scale, averaging the source pixels that fall
+// into each destination pixel.
+func downscale(img image.Image, scale float64) *image.NRGBA {
+	b := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := max(1, int(float64(sw)*scale)), max(1, int(float64(sh)*scale))
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					for c := range sum {
+						sum[c] += int(p[c])
+					}
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			d := dst.Pix[y*dst.Stride+x*4:]
+			for c := range sum {
+				d[c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,83 @@
+package screenshot
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	// 4x2: left half black, right half white
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			c := color.NRGBA{A: 255}
+			if x >= 2 {
+				c = color.NRGBA{255, 255, 255, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestEncodeUnchanged(t *testing.T) {
+	data := testPNG(t)
+	out, err := Encode(data, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Error("expected the snapshot to be returned unchanged")
+	}
+}
+
+func TestEncodeScale(t *testing.T) {
+	out, err := Encode(testPNG(t), Options{Scale: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 2 || b.Dy() != 1 {
+		t.Fatalf("expected 2x1, got %dx%d", b.Dx(), b.Dy())
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r != 0 {
+		t.Errorf("expected black on the left, got %v", img.At(0, 0))
+	}
+	if r, _, _, _ := img.At(1, 0).RGBA(); r != 0xffff {
+		t.Errorf("expected white on the right, got %v", img.At(1, 0))
+	}
+}
+
+func TestEncodeJPEG(t *testing.T) {
+	o := Options{Quality: 80}
+	out, err := Encode(testPNG(t), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(out)); err != nil {
+		t.Errorf("expected JPEG output: %v", err)
+	}
+	if o.ContentType() != "image/jpeg" || o.Ext() != ".jpg" {
+		t.Errorf("unexpected type %s %s", o.ContentType(), o.Ext())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, o := range []Options{{Scale: 0.05}, {Scale: 2}, {Quality: 101}, {Quality: -1}} {
+		if err := o.Validate(); err == nil {
+			t.Errorf("%+v: expected error", o)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Package service manages the kiosk's systemd units: their state, when
+// they entered it, how often systemd restarted them, and starting,
+// stopping and restarting them.
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Units of the kiosk package.
+const (
+	KioskUnit = "wpe-webkit-kiosk"
+	APIUnit   = "wpe-webkit-kiosk-api"
+	VNCUnit   = "wpe-webkit-kiosk-vnc"
+)
+
+// TimeLayout is the layout systemctl prints timestamps in.
+const TimeLayout = "Mon 2006-01-02 15:04:05 MST"
+
+// ErrNoSuchUnit is returned for a unit systemd does not know.
+var ErrNoSuchUnit = errors.New("no such unit")
+
+// Status is the state of a unit.
+type Status struct {
+	State    string    `json:"state"`     // ActiveState: active, inactive, failed, activating, ...
+	SubState string    `json:"sub_state"` // e.g. running, dead, auto-restart
+	Since    time.Time `json:"since"`     // when the unit last became active, zero if never
+	Restarts uint32    `json:"restarts"`  // automatic restarts since it was last started
+}
+
+// Manager manages systemd units. Units may be given with or without the
+// ".service" suffix. *Systemd implements it; tests can substitute their
+// own implementation.
+type Manager interface {
+	Status(ctx context.Context, unit string) (Status, error)
+	// Property returns a property of the unit or its service, by its
+	// D-Bus name (e.g. "ActiveState", "MainPID", "ExecMainStatus").
+	Property(ctx context.Context, unit, name string) (any, error)
+	Start(ctx context.Context, unit string) error
+	Stop(ctx context.Context, unit string) error
+	Restart(ctx context.Context, unit string) error
+}
+
+// State returns the ActiveState of unit, or "unknown" if it cannot be
+// read.
+func State(ctx context.Context, m Manager, unit string) string {
+	st, err := m.Status(ctx, unit)
+	if err != nil || st.State == "" {
+		return "unknown"
+	}
+	return st.State
+}
+
+// ActiveSince returns when the unit became active, formatted like
+// systemctl does, or "" if it is not active.
+func (s Status) ActiveSince() string {
+	if s.State != "active" || s.Since.IsZero() {
+		return ""
+	}
+	return s.Since.Local().Format(TimeLayout)
+}
+
+// unitName returns the full name of a service unit.
+func unitName(unit string) string {
+	if strings.Contains(unit, ".") {
+		return unit
+	}
+	return unit + ".service"
+}
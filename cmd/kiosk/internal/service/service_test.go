@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestUnitName(t *testing.T) {
+	tests := map[string]string{
+		KioskUnit:                   "wpe-webkit-kiosk.service",
+		"wpe-webkit-kiosk.service":  "wpe-webkit-kiosk.service",
+		"wpe-webkit-kiosk-vnc.path": "wpe-webkit-kiosk-vnc.path",
+	}
+	for in, want := range tests {
+		if got := unitName(in); got != want {
+			t.Errorf("unitName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestActiveSince(t *testing.T) {
+	since := time.Date(2026, 2, 20, 23, 29, 26, 0, time.Local)
+	if got, want := (Status{State: "active", Since: since}).ActiveSince(), since.Format(TimeLayout); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := (Status{State: "failed", Since: since}).ActiveSince(); got != "" {
+		t.Errorf("expected no time for a failed unit, got %q", got)
+	}
+	if got := (Status{State: "active"}).ActiveSince(); got != "" {
+		t.Errorf("expected no time without a timestamp, got %q", got)
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	err := wrapError(dbus.Error{Name: "org.freedesktop.systemd1.NoSuchUnit"}, "nope")
+	if !errors.Is(err, ErrNoSuchUnit) {
+		t.Errorf("expected ErrNoSuchUnit, got %v", err)
+	}
+	other := dbus.Error{Name: "org.freedesktop.DBus.Error.Failed"}
+	if err := wrapError(other, "nope"); errors.Is(err, ErrNoSuchUnit) {
+		t.Errorf("expected other errors unchanged, got %v", err)
+	}
+}
+
+func TestAccessDenied(t *testing.T) {
+	for name, want := range map[string]bool{
+		"org.freedesktop.DBus.Error.AccessDenied":                     true,
+		"org.freedesktop.DBus.Error.InteractiveAuthorizationRequired": true,
+		"org.freedesktop.systemd1.NoSuchUnit":                         false,
+	} {
+		if got := accessDenied(dbus.Error{Name: name}); got != want {
+			t.Errorf("accessDenied(%s) = %v, want %v", name, got, want)
+		}
+	}
+	if accessDenied(errors.New("plain")) {
+		t.Error("expected plain errors not to be access denied")
+	}
+}
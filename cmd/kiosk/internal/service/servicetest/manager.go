@@ -0,0 +1,151 @@
+// Package servicetest provides an in-memory service.Manager for tests.
+package servicetest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
+)
+
+var _ service.Manager = (*Manager)(nil)
+
+// Call is a start, stop or restart received by a Manager.
+type Call struct {
+	Verb string // "start", "stop" or "restart"
+	Unit string
+}
+
+// Manager is a fake service.Manager. Units start out inactive, jobs
+// change their state at once, and errors can be injected per unit.
+type Manager struct {
+	mu    sync.Mutex
+	units map[string]service.Status
+	props map[string]map[string]any
+	fail  map[string]error
+	calls []Call
+}
+
+// NewManager returns a Manager with the given units active.
+func NewManager(active ...string) *Manager {
+	m := &Manager{
+		units: make(map[string]service.Status),
+		props: make(map[string]map[string]any),
+		fail:  make(map[string]error),
+	}
+	for _, unit := range active {
+		m.units[key(unit)] = service.Status{State: "active", SubState: "running", Since: time.Now()}
+	}
+	return m
+}
+
+// key strips the ".service" suffix, so units can be named either way.
+func key(unit string) string {
+	return strings.TrimSuffix(unit, ".service")
+}
+
+// Set replaces the status of unit.
+func (m *Manager) Set(unit string, st service.Status) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.units[key(unit)] = st
+}
+
+// SetProperty sets a property returned by Property.
+func (m *Manager) SetProperty(unit, name string, value any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.props[key(unit)] == nil {
+		m.props[key(unit)] = make(map[string]any)
+	}
+	m.props[key(unit)][name] = value
+}
+
+// Fail makes every call for unit return err. A nil err clears it.
+func (m *Manager) Fail(unit string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err == nil {
+		delete(m.fail, key(unit))
+		return
+	}
+	m.fail[key(unit)] = err
+}
+
+// Calls returns the jobs received so far, oldest first.
+func (m *Manager) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.calls)
+}
+
+// Status returns the status of unit; unknown units are inactive.
+func (m *Manager) Status(ctx context.Context, unit string) (service.Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.fail[key(unit)]; err != nil {
+		return service.Status{}, err
+	}
+	st, ok := m.units[key(unit)]
+	if !ok {
+		st = service.Status{State: "inactive", SubState: "dead"}
+	}
+	return st, nil
+}
+
+// Property returns a property set with SetProperty, or one of the
+// status properties ActiveState, SubState and NRestarts.
+func (m *Manager) Property(ctx context.Context, unit, name string) (any, error) {
+	st, err := m.Status(ctx, unit)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.props[key(unit)][name]; ok {
+		return v, nil
+	}
+	switch name {
+	case "ActiveState":
+		return st.State, nil
+	case "SubState":
+		return st.SubState, nil
+	case "NRestarts":
+		return st.Restarts, nil
+	}
+	return nil, fmt.Errorf("property %s of %s: unknown property", name, unit)
+}
+
+// Start marks unit active.
+func (m *Manager) Start(ctx context.Context, unit string) error {
+	return m.job("start", unit, "active")
+}
+
+// Stop marks unit inactive.
+func (m *Manager) Stop(ctx context.Context, unit string) error {
+	return m.job("stop", unit, "inactive")
+}
+
+// Restart marks unit active again.
+func (m *Manager) Restart(ctx context.Context, unit string) error {
+	return m.job("restart", unit, "active")
+}
+
+func (m *Manager) job(verb, unit, state string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Verb: verb, Unit: key(unit)})
+	if err := m.fail[key(unit)]; err != nil {
+		return fmt.Errorf("failed to %s %s: %w", verb, unit, err)
+	}
+	st := service.Status{State: state, SubState: "dead", Restarts: m.units[key(unit)].Restarts}
+	if state == "active" {
+		st.SubState, st.Since = "running", time.Now()
+	}
+	m.units[key(unit)] = st
+	return nil
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	systemdName      = "org.freedesktop.systemd1"
+	systemdPath      = "/org/freedesktop/systemd1"
+	managerInterface = "org.freedesktop.systemd1.Manager"
+	unitInterface    = "org.freedesktop.systemd1.Unit"
+	serviceInterface = "org.freedesktop.systemd1.Service"
+)
+
+const (
+	// DefaultTimeout bounds queries whose context has no deadline.
+	DefaultTimeout = 5 * time.Second
+	// JobTimeout bounds waiting for a start, stop or restart whose context
+	// has no deadline.
+	JobTimeout = 2 * time.Minute
+)
+
+var _ Manager = (*Systemd)(nil)
+
+// Systemd manages units through systemd's D-Bus API. Like dbus.Client it
+// connects on first use and reconnects after the connection is lost.
+type Systemd struct {
+	// Sudo makes start, stop and restart fall back to "sudo systemctl"
+	// when systemd refuses them for lack of privileges, as it does for
+	// users other than root. The package's sudoers rules allow this for
+	// the kiosk units.
+	Sudo bool
+
+	connect func() (*dbus.Conn, error)
+
+	mu   sync.Mutex
+	conn *dbus.Conn
+}
+
+// NewSystemd returns a Systemd on the system bus, falling back to sudo.
+func NewSystemd() *Systemd {
+	return &Systemd{Sudo: true, connect: func() (*dbus.Conn, error) {
+		return dbus.ConnectSystemBus()
+	}}
+}
+
+var defaultSystemd = sync.OnceValue(NewSystemd)
+
+// Default returns the Systemd shared by the whole process.
+func Default() *Systemd {
+	return defaultSystemd()
+}
+
+// connection returns the current bus connection, connecting first if
+// there is none or the previous one was lost.
+func (s *Systemd) connection() (*dbus.Conn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil && s.conn.Connected() {
+		return s.conn, nil
+	}
+	conn, err := s.connect()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+	// systemd only sends JobRemoved to clients that subscribed.
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	conn.Object(systemdName, systemdPath).CallWithContext(ctx, managerInterface+".Subscribe", 0)
+	s.conn = conn
+	return conn, nil
+}
+
+// unit returns the D-Bus object of unit, loading it if needed.
+func (s *Systemd) unit(ctx context.Context, unit string) (dbus.BusObject, error) {
+	conn, err := s.connection()
+	if err != nil {
+		return nil, err
+	}
+	var path dbus.ObjectPath
+	err = conn.Object(systemdName, systemdPath).
+		CallWithContext(ctx, managerInterface+".LoadUnit", 0, unitName(unit)).Store(&path)
+	if err != nil {
+		return nil, wrapError(err, unit)
+	}
+	return conn.Object(systemdName, path), nil
+}
+
+// Status returns the state of unit. Units systemd does not know are
+// reported as inactive, as systemctl does.
+func (s *Systemd) Status(ctx context.Context, unit string) (Status, error) {
+	ctx, cancel := withTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	var st Status
+	obj, err := s.unit(ctx, unit)
+	if err != nil {
+		return st, err
+	}
+	var props map[string]dbus.Variant
+	err = obj.CallWithContext(ctx, "org.freedesktop.DBus.Properties.GetAll", 0, unitInterface).Store(&props)
+	if err != nil {
+		return st, wrapError(err, unit)
+	}
+	st.State, _ = props["ActiveState"].Value().(string)
+	st.SubState, _ = props["SubState"].Value().(string)
+	if usec, _ := props["ActiveEnterTimestamp"].Value().(uint64); usec > 0 {
+		st.Since = time.UnixMicro(int64(usec))
+	}
+	// NRestarts needs systemd 235 or later.
+	var restarts dbus.Variant
+	err = obj.CallWithContext(ctx, "org.freedesktop.DBus.Properties.Get", 0, serviceInterface, "NRestarts").Store(&restarts)
+	if err == nil {
+		st.Restarts, _ = restarts.Value().(uint32)
+	}
+	return st, nil
+}
+
+// Property returns a property of unit, looked up on its unit interface
+// first and its service interface second.
+func (s *Systemd) Property(ctx context.Context, unit, name string) (any, error) {
+	ctx, cancel := withTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	obj, err := s.unit(ctx, unit)
+	if err != nil {
+		return nil, err
+	}
+	var v dbus.Variant
+	for _, iface := range []string{unitInterface, serviceInterface} {
+		err = obj.CallWithContext(ctx, "org.freedesktop.DBus.Properties.Get", 0, iface, name).Store(&v)
+		if err == nil {
+			return v.Value(), nil
+		}
+	}
+	return nil, fmt.Errorf("property %s of %s: %w", name, unit, wrapError(err, unit))
+}
+
+// Start starts unit and waits until it has started.
+func (s *Systemd) Start(ctx context.Context, unit string) error {
+	return s.job(ctx, "start", "StartUnit", unit)
+}
+
+// Stop stops unit and waits until it has stopped.
+func (s *Systemd) Stop(ctx context.Context, unit string) error {
+	return s.job(ctx, "stop", "StopUnit", unit)
+}
+
+// Restart restarts unit and waits until it has started again.
+func (s *Systemd) Restart(ctx context.Context, unit string) error {
+	return s.job(ctx, "restart", "RestartUnit", unit)
+}
+
+// job queues a job with the Manager method and waits for its result, like
+// "systemctl <verb>" does.
+func (s *Systemd) job(ctx context.Context, verb, method, unit string) error {
+	ctx, cancel := withTimeout(ctx, JobTimeout)
+	defer cancel()
+
+	conn, err := s.connection()
+	if err != nil {
+		return err
+	}
+
+	// Listen before queuing the job, so its removal cannot be missed.
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(systemdPath),
+		dbus.WithMatchInterface(managerInterface),
+		dbus.WithMatchMember("JobRemoved"),
+	}
+	if err := conn.AddMatchSignalContext(ctx, match...); err != nil {
+		return fmt.Errorf("failed to watch systemd jobs: %w", err)
+	}
+	defer conn.RemoveMatchSignal(match...)
+	signals := make(chan *dbus.Signal, 64)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	var job dbus.ObjectPath
+	err = conn.Object(systemdName, systemdPath).
+		CallWithContext(ctx, managerInterface+"."+method, 0, unitName(unit), "replace").Store(&job)
+	if err != nil {
+		if s.Sudo && accessDenied(err) {
+			return sudo(ctx, verb, unit)
+		}
+		return fmt.Errorf("failed to %s %s: %w", verb, unit, wrapError(err, unit))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to %s %s: %w", verb, unit, ctx.Err())
+		case sig := <-signals:
+			// JobRemoved(u id, o job, s unit, s result)
+			if sig.Name != managerInterface+".JobRemoved" || len(sig.Body) != 4 || sig.Body[1] != job {
+				continue
+			}
+			if result, _ := sig.Body[3].(string); result != "done" {
+				return fmt.Errorf("failed to %s %s: job %s", verb, unit, result)
+			}
+			return nil
+		}
+	}
+}
+
+// sudo runs "sudo systemctl <verb> <unit>".
+func sudo(ctx context.Context, verb, unit string) error {
+	out, err := exec.CommandContext(ctx, "sudo", "systemctl", verb, unit).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("failed to %s %s: %s", verb, unit, msg)
+		}
+		return fmt.Errorf("failed to %s %s: %w", verb, unit, err)
+	}
+	return nil
+}
+
+// accessDenied reports whether systemd refused a call for lack of
+// privileges.
+func accessDenied(err error) bool {
+	var dbusErr dbus.Error
+	if !errors.As(err, &dbusErr) {
+		return false
+	}
+	switch dbusErr.Name {
+	case "org.freedesktop.DBus.Error.AccessDenied",
+		"org.freedesktop.DBus.Error.InteractiveAuthorizationRequired":
+		return true
+	}
+	return false
+}
+
+// wrapError maps systemd's D-Bus errors to the errors of this package.
+func wrapError(err error, unit string) error {
+	var dbusErr dbus.Error
+	if errors.As(err, &dbusErr) && dbusErr.Name == "org.freedesktop.systemd1.NoSuchUnit" {
+		return fmt.Errorf("%s: %w", unit, ErrNoSuchUnit)
+	}
+	return err
+}
+
+// withTimeout applies timeout to ctx if it has no deadline.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
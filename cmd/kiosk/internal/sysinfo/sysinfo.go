@@ -0,0 +1,183 @@
+// Package sysinfo reads host telemetry from /proc and /sys: uptime, load,
+// memory, CPU, disk, network and temperature.
+package sysinfo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Collect returns the host telemetry served by GET /system.
+func Collect() map[string]any {
+	info := map[string]any{}
+
+	if data, err := os.ReadFile("/proc/uptime"); err == nil {
+		parts := strings.Fields(string(data))
+		if len(parts) > 0 {
+			if secs, err := strconv.ParseFloat(parts[0], 64); err == nil {
+				info["uptime_seconds"] = secs
+			}
+		}
+	}
+
+	if data, err := os.ReadFile("/proc/loadavg"); err == nil {
+		parts := strings.Fields(string(data))
+		if len(parts) >= 3 {
+			info["load_average"] = map[string]string{
+				"1m": parts[0], "5m": parts[1], "15m": parts[2],
+			}
+		}
+	}
+
+	if data, err := os.ReadFile("/proc/meminfo"); err == nil {
+		info["memory"] = ParseMemInfo(string(data))
+	}
+
+	if data, err := os.ReadFile("/proc/stat"); err == nil {
+		info["cpu"] = ParseCPUStat(string(data))
+	}
+
+	info["disk"] = DiskUsage()
+	info["network"] = NetworkInterfaces()
+	info["temperature"] = Temperature()
+	return info
+}
+
+// ParseMemInfo returns the memory and swap fields of /proc/meminfo, in kB.
+func ParseMemInfo(data string) map[string]int64 {
+	result := map[string]int64{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		parts := strings.Fields(line)
+		if len(parts) < 2 {
+			continue
+		}
+		key := strings.TrimSuffix(parts[0], ":")
+		switch key {
+		case "MemTotal", "MemFree", "MemAvailable", "SwapTotal", "SwapFree":
+			if val, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+				result[key+"_kB"] = val
+			}
+		}
+	}
+	return result
+}
+
+// ParseCPUStat returns the aggregate CPU times of /proc/stat, in ticks.
+func ParseCPUStat(data string) map[string]string {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "cpu ") {
+			fields := strings.Fields(line)
+			if len(fields) >= 5 {
+				return map[string]string{
+					"user":   fields[1],
+					"nice":   fields[2],
+					"system": fields[3],
+					"idle":   fields[4],
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// DiskUsage returns the usage of the root filesystem as df prints it.
+func DiskUsage() []map[string]string {
+	out, err := exec.Command("df", "-h", "--output=target,size,used,avail,pcent", "/").Output()
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) < 2 {
+		return nil
+	}
+	var disks []map[string]string
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) >= 5 {
+			disks = append(disks, map[string]string{
+				"mount": fields[0],
+				"size":  fields[1],
+				"used":  fields[2],
+				"avail": fields[3],
+				"use%":  fields[4],
+			})
+		}
+	}
+	return disks
+}
+
+// NetworkInterfaces returns the byte counters of the network interfaces,
+// except loopback.
+func NetworkInterfaces() []map[string]string {
+	data, err := os.ReadFile("/proc/net/dev")
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(string(data), "\n")
+	var ifaces []map[string]string
+	for _, line := range lines[2:] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name := strings.TrimSpace(parts[0])
+		if name == "lo" {
+			continue
+		}
+		fields := strings.Fields(parts[1])
+		if len(fields) >= 9 {
+			ifaces = append(ifaces, map[string]string{
+				"name":     name,
+				"rx_bytes": fields[0],
+				"tx_bytes": fields[8],
+			})
+		}
+	}
+	return ifaces
+}
+
+// Temperature returns the temperature of each thermal zone, in °C.
+func Temperature() []map[string]string {
+	entries, err := os.ReadDir("/sys/class/thermal")
+	if err != nil {
+		return nil
+	}
+	var temps []map[string]string
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "thermal_zone") {
+			continue
+		}
+		base := filepath.Join("/sys/class/thermal", entry.Name())
+		typeData, err := os.ReadFile(filepath.Join(base, "type"))
+		if err != nil {
+			continue
+		}
+		tempData, err := os.ReadFile(filepath.Join(base, "temp"))
+		if err != nil {
+			continue
+		}
+		millideg, err := strconv.ParseInt(strings.TrimSpace(string(tempData)), 10, 64)
+		if err != nil {
+			continue
+		}
+		temps = append(temps, map[string]string{
+			"sensor": entry.Name(),
+			"zone":   strings.TrimSpace(string(typeData)),
+			"temp":   fmt.Sprintf("%.1f", float64(millideg)/1000.0),
+		})
+	}
+	return temps
+}
@@ -0,0 +1,177 @@
+package targets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// Local is the reserved target name that selects the local machine.
+const Local = "local"
+
+// DefaultPort is the kiosk API port assumed when a target has none.
+const DefaultPort = 8100
+
+var nameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Target is a named remote kiosk reachable over the REST API.
+type Target struct {
+	Name        string `json:"name"`
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	Token       string `json:"token"`
+	TLS         bool   `json:"tls,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"` // pinned SHA-256 of the server certificate
+	Insecure    bool   `json:"insecure,omitempty"`    // skip TLS verification
+}
+
+// BaseURL returns the scheme, host and port of the target's API.
+func (t *Target) BaseURL() string {
+	scheme := "http"
+	if t.TLS {
+		scheme = "https"
+	}
+	port := t.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+	return scheme + "://" + net.JoinHostPort(t.Host, strconv.Itoa(port))
+}
+
+// Store holds the targets saved in the user's targets file.
+type Store struct {
+	Current string   `json:"current,omitempty"`
+	Targets []Target `json:"targets"`
+	path    string
+}
+
+// DefaultPath returns the per-user targets file,
+// e.g. ~/.config/wpe-webkit-kiosk/targets.json.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "wpe-webkit-kiosk", "targets.json"), nil
+}
+
+// Load reads the targets file at path. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("cannot read targets: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid targets file: %w", err)
+	}
+	return s, nil
+}
+
+// Get returns the target with the given name, if any.
+func (s *Store) Get(name string) (*Target, bool) {
+	i := s.find(name)
+	if i < 0 {
+		return nil, false
+	}
+	return &s.Targets[i], true
+}
+
+// Set adds t, replacing a target with the same name. It reports whether
+// an existing target was replaced.
+func (s *Store) Set(t Target) (bool, error) {
+	if t.Name == Local || !nameRe.MatchString(t.Name) {
+		return false, fmt.Errorf("invalid target name %q (use letters, digits, '.', '_' or '-'; %q is reserved)", t.Name, Local)
+	}
+	if t.Host == "" {
+		return false, fmt.Errorf("target host is required")
+	}
+	if t.Port < 0 || t.Port > 65535 {
+		return false, fmt.Errorf("invalid port %d", t.Port)
+	}
+	if i := s.find(t.Name); i >= 0 {
+		s.Targets[i] = t
+		return true, nil
+	}
+	s.Targets = append(s.Targets, t)
+	return false, nil
+}
+
+// Remove deletes the named target. Removing the current target
+// switches back to the local machine.
+func (s *Store) Remove(name string) error {
+	i := s.find(name)
+	if i < 0 {
+		return fmt.Errorf("target %q not found", name)
+	}
+	s.Targets = append(s.Targets[:i], s.Targets[i+1:]...)
+	if s.Current == name {
+		s.Current = ""
+	}
+	return nil
+}
+
+// Use makes name the current target. Local (or "") selects the local machine.
+func (s *Store) Use(name string) error {
+	if name == Local || name == "" {
+		s.Current = ""
+		return nil
+	}
+	if s.find(name) < 0 {
+		return fmt.Errorf("target %q not found", name)
+	}
+	s.Current = name
+	return nil
+}
+
+// Resolve returns the target selected by name, falling back to the
+// current target when name is empty. It returns nil for the local machine.
+func (s *Store) Resolve(name string) (*Target, error) {
+	if name == "" {
+		name = s.Current
+	}
+	if name == "" || name == Local {
+		return nil, nil
+	}
+	t, ok := s.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("target %q not found (see: kiosk target list)", name)
+	}
+	return t, nil
+}
+
+func (s *Store) find(name string) int {
+	for i, t := range s.Targets {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Save writes the store back to disk. The file holds API tokens,
+// so it is only readable by the owner.
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("cannot create targets directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("cannot write targets: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(s.path, 0600)
+}
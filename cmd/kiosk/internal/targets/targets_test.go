@@ -0,0 +1,117 @@
+package targets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMissingFileIsEmpty(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "targets.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Targets) != 0 || s.Current != "" {
+		t.Errorf("expected empty store, got %+v", s)
+	}
+}
+
+func TestSetUseSaveResolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "targets.json")
+	s, _ := Load(path)
+
+	if _, err := s.Set(Target{Name: "store-12", Host: "10.0.0.12", Port: 8100, Token: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Use("store-12"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %o", info.Mode().Perm())
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tgt, err := reloaded.Resolve("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tgt == nil || tgt.Name != "store-12" || tgt.Token != "secret" {
+		t.Fatalf("expected current target store-12, got %+v", tgt)
+	}
+
+	if tgt, err := reloaded.Resolve(Local); err != nil || tgt != nil {
+		t.Errorf("expected %q to resolve to the local machine, got %+v, %v", Local, tgt, err)
+	}
+	if _, err := reloaded.Resolve("missing"); err == nil {
+		t.Error("expected error for unknown target")
+	}
+}
+
+func TestSetReplacesExisting(t *testing.T) {
+	s, _ := Load(filepath.Join(t.TempDir(), "targets.json"))
+	s.Set(Target{Name: "a", Host: "old"})
+	replaced, err := s.Set(Target{Name: "a", Host: "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !replaced || len(s.Targets) != 1 || s.Targets[0].Host != "new" {
+		t.Errorf("expected target to be replaced, got %+v", s.Targets)
+	}
+}
+
+func TestSetRejectsInvalidInput(t *testing.T) {
+	s, _ := Load(filepath.Join(t.TempDir(), "targets.json"))
+	cases := []Target{
+		{Name: Local, Host: "h"},
+		{Name: "bad name", Host: "h"},
+		{Name: "ok"},
+		{Name: "ok", Host: "h", Port: 70000},
+	}
+	for _, c := range cases {
+		if _, err := s.Set(c); err == nil {
+			t.Errorf("expected error for %+v", c)
+		}
+	}
+}
+
+func TestRemoveCurrentSwitchesToLocal(t *testing.T) {
+	s, _ := Load(filepath.Join(t.TempDir(), "targets.json"))
+	s.Set(Target{Name: "a", Host: "h"})
+	s.Use("a")
+	if err := s.Remove("a"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Current != "" {
+		t.Errorf("expected current target cleared, got %q", s.Current)
+	}
+	if err := s.Remove("a"); err == nil {
+		t.Error("expected error removing unknown target")
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	cases := []struct {
+		target Target
+		want   string
+	}{
+		{Target{Host: "10.0.0.12"}, "http://10.0.0.12:8100"},
+		{Target{Host: "kiosk.local", Port: 9000, TLS: true}, "https://kiosk.local:9000"},
+		{Target{Host: "fe80::1", Port: 8100}, "http://[fe80::1]:8100"},
+	}
+	for _, c := range cases {
+		if got := c.target.BaseURL(); got != c.want {
+			t.Errorf("BaseURL(%+v) = %q, want %q", c.target, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/atomicfile"
+)
+
+const DefaultPath = "/etc/wpe-webkit-kiosk/tokens.json"
+
+// Scopes granted to API tokens. ScopeAdmin implies every other scope.
+const (
+	ScopeRead     = "read"
+	ScopeNavigate = "navigate"
+	ScopeConfig   = "config"
+	ScopeEval     = "eval" // run JavaScript in the page
+	ScopeAdmin    = "admin"
+)
+
+// ValidScopes is the set of recognized token scopes.
+var ValidScopes = map[string]bool{
+	ScopeRead:     true,
+	ScopeNavigate: true,
+	ScopeConfig:   true,
+	ScopeEval:     true,
+	ScopeAdmin:    true,
+}
+
+// ScopeNames returns the recognized token scopes, sorted.
+func ScopeNames() []string {
+	names := make([]string, 0, len(ValidScopes))
+	for sc := range ValidScopes {
+		names = append(names, sc)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var nameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Token is a named API credential. Only the SHA-256 hash of the secret is stored.
+type Token struct {
+	Name    string     `json:"name"`
+	Hash    string     `json:"hash"`
+	Scopes  []string   `json:"scopes"`
+	Created time.Time  `json:"created"`
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// Expired reports whether the token has passed its expiry time.
+func (t *Token) Expired(now time.Time) bool {
+	return t.Expires != nil && !now.Before(*t.Expires)
+}
+
+// HasScope reports whether the token grants the given scope.
+func (t *Token) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Store holds the named tokens persisted in the token file.
+type Store struct {
+	Tokens []Token `json:"tokens"`
+	path   string
+}
+
+// Load reads the token store at path. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("cannot read token store: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid token store: %w", err)
+	}
+	return s, nil
+}
+
+// Create adds a new token and returns its plaintext secret.
+// The secret cannot be recovered later; only its hash is kept.
+func (s *Store) Create(name string, scopes []string, expires *time.Time) (string, error) {
+	if !nameRe.MatchString(name) {
+		return "", fmt.Errorf("invalid token name %q (use letters, digits, '.', '_' or '-')", name)
+	}
+	if s.find(name) >= 0 {
+		return "", fmt.Errorf("token %q already exists", name)
+	}
+	if len(scopes) == 0 {
+		return "", fmt.Errorf("at least one scope is required")
+	}
+	for _, sc := range scopes {
+		if !ValidScopes[sc] {
+			return "", fmt.Errorf("unknown scope %q (valid: %s)", sc, strings.Join(ScopeNames(), ", "))
+		}
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	s.Tokens = append(s.Tokens, Token{
+		Name:    name,
+		Hash:    hashSecret(secret),
+		Scopes:  scopes,
+		Created: time.Now().UTC(),
+		Expires: expires,
+	})
+	return secret, nil
+}
+
+// Revoke removes the named token.
+func (s *Store) Revoke(name string) error {
+	i := s.find(name)
+	if i < 0 {
+		return fmt.Errorf("token %q not found", name)
+	}
+	s.Tokens = append(s.Tokens[:i], s.Tokens[i+1:]...)
+	return nil
+}
+
+// Lookup returns the token matching the given secret, if any.
+// Every stored hash is compared in constant time.
+func (s *Store) Lookup(secret string) (*Token, bool) {
+	hash := []byte(hashSecret(secret))
+	var found *Token
+	for i := range s.Tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(s.Tokens[i].Hash)) == 1 {
+			found = &s.Tokens[i]
+		}
+	}
+	return found, found != nil
+}
+
+// Get returns the token with the given name, if any.
+func (s *Store) Get(name string) (*Token, bool) {
+	i := s.find(name)
+	if i < 0 {
+		return nil, false
+	}
+	return &s.Tokens[i], true
+}
+
+func (s *Store) find(name string) int {
+	for i, t := range s.Tokens {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Save writes the store back to disk atomically, through sudo if needed.
+// A new token file is readable only by its owner.
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if err := atomicfile.Write(s.path, data, 0600); err != nil {
+		return fmt.Errorf("cannot write token store: %w", err)
+	}
+	return nil
+}
+
+// ParseScopes splits a comma-separated scope list.
+func ParseScopes(list string) []string {
+	var scopes []string
+	for _, sc := range strings.Split(list, ",") {
+		if sc = strings.TrimSpace(sc); sc != "" {
+			scopes = append(scopes, sc)
+		}
+	}
+	return scopes
+}
+
+// ParseExpiry parses a relative duration ("12h", "30d") or an absolute
+// date ("2026-12-31") into an expiry time. Empty input means no expiry.
+func ParseExpiry(value string, now time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if strings.HasSuffix(value, "d") {
+		var days int
+		if _, err := fmt.Sscanf(value, "%dd", &days); err == nil && days > 0 {
+			t := now.Add(time.Duration(days) * 24 * time.Hour).UTC()
+			return &t, nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		t := now.Add(d).UTC()
+		return &t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return &t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		t = t.UTC()
+		return &t, nil
+	}
+	return nil, fmt.Errorf("invalid expiry %q (use e.g. 12h, 30d or 2026-12-31)", value)
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
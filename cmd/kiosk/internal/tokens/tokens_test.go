@@ -0,0 +1,150 @@
+package tokens
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFileIsEmpty(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "tokens.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Tokens) != 0 {
+		t.Errorf("expected empty store, got %d tokens", len(s.Tokens))
+	}
+}
+
+func TestCreateSaveLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	s, _ := Load(path)
+
+	secret, err := s.Create("deploy", []string{ScopeNavigate, ScopeConfig}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok, ok := reloaded.Lookup(secret)
+	if !ok {
+		t.Fatal("expected token to be found by secret")
+	}
+	if tok.Name != "deploy" {
+		t.Errorf("expected name 'deploy', got %q", tok.Name)
+	}
+	if tok.Hash == secret {
+		t.Error("secret must not be stored in plaintext")
+	}
+	if _, ok := reloaded.Lookup("wrong"); ok {
+		t.Error("expected lookup with wrong secret to fail")
+	}
+}
+
+func TestSaveCreatesPrivateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	s, _ := Load(path)
+	if _, err := s.Create("deploy", []string{ScopeRead}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("expected mode 0600, got %04o", mode)
+	}
+}
+
+func TestCreateRejectsInvalidInput(t *testing.T) {
+	s, _ := Load(filepath.Join(t.TempDir(), "tokens.json"))
+
+	if _, err := s.Create("bad name", []string{ScopeRead}, nil); err == nil {
+		t.Error("expected error for invalid name")
+	}
+	if _, err := s.Create("ok", []string{"superuser"}, nil); err == nil {
+		t.Error("expected error for unknown scope")
+	} else if !strings.Contains(err.Error(), ScopeEval) {
+		t.Errorf("expected the valid scopes listed, got %v", err)
+	}
+	if _, err := s.Create("ok", nil, nil); err == nil {
+		t.Error("expected error for missing scopes")
+	}
+	if _, err := s.Create("ok", []string{ScopeRead}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Create("ok", []string{ScopeRead}, nil); err == nil {
+		t.Error("expected error for duplicate name")
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	s, _ := Load(filepath.Join(t.TempDir(), "tokens.json"))
+	secret, _ := s.Create("temp", []string{ScopeRead}, nil)
+
+	if err := s.Revoke("temp"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Lookup(secret); ok {
+		t.Error("expected revoked token to be gone")
+	}
+	if err := s.Revoke("temp"); err == nil {
+		t.Error("expected error revoking unknown token")
+	}
+}
+
+func TestHasScopeAdminImpliesAll(t *testing.T) {
+	admin := Token{Scopes: []string{ScopeAdmin}}
+	reader := Token{Scopes: []string{ScopeRead}}
+
+	if !admin.HasScope(ScopeConfig) {
+		t.Error("admin should imply config scope")
+	}
+	if !reader.HasScope(ScopeRead) {
+		t.Error("reader should have read scope")
+	}
+	if reader.HasScope(ScopeNavigate) {
+		t.Error("reader should not have navigate scope")
+	}
+}
+
+func TestParseExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"12h", now.Add(12 * time.Hour)},
+		{"30d", now.Add(30 * 24 * time.Hour)},
+		{"2026-12-31", time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseExpiry(tt.in, now)
+		if err != nil {
+			t.Errorf("ParseExpiry(%q) error: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseExpiry(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if got, err := ParseExpiry("", now); err != nil || got != nil {
+		t.Errorf("expected no expiry for empty input, got %v, %v", got, err)
+	}
+	if _, err := ParseExpiry("soon", now); err == nil {
+		t.Error("expected error for invalid expiry")
+	}
+}
@@ -0,0 +1,177 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/logs"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// logsLimit is how many of the most recent entries the Logs tab shows.
+const logsLimit = 500
+
+// logUnits are the unit filters the Logs tab cycles through; "" is all.
+var logUnits = append([]string{""}, logs.Units...)
+
+// logPriorities are the priority filters the Logs tab cycles through.
+var logPriorities = []string{"", "info", "notice", "warning", "err"}
+
+// logsMsg carries the entries read for filter, oldest first.
+type logsMsg struct {
+	filter  logs.Filter
+	entries []logs.Entry
+	err     error
+}
+
+// logFilter returns the journal filter selected on the Logs tab.
+func (m model) logFilter() logs.Filter {
+	f := logs.Filter{
+		Priority: logPriorities[m.logPriority],
+		Grep:     m.logGrep,
+		Limit:    logsLimit,
+	}
+	if u := logUnits[m.logUnit]; u != "" {
+		f.Units = []string{u}
+	}
+	return f
+}
+
+// sameLogFilter reports whether a and b select the same entries.
+func sameLogFilter(a, b logs.Filter) bool {
+	return slices.Equal(a.Units, b.Units) && a.Priority == b.Priority && a.Grep == b.Grep
+}
+
+// logsCmd reads the entries selected on the Logs tab, if it is shown.
+func (m model) logsCmd() tea.Cmd {
+	if m.activeTab != tabLogs {
+		return nil
+	}
+	f := m.logFilter()
+	return func() tea.Msg {
+		page, err := logs.Query(context.Background(), f)
+		if err != nil {
+			return logsMsg{filter: f, err: err}
+		}
+		entries := page.Entries
+		slices.Reverse(entries)
+		return logsMsg{filter: f, entries: entries}
+	}
+}
+
+// applyLogs shows newly read entries. The view keeps following the newest
+// entry unless it was scrolled up.
+func (m model) applyLogs(msg logsMsg) model {
+	if !sameLogFilter(msg.filter, m.logFilter()) {
+		return m // the filter changed while reading
+	}
+	if msg.err != nil {
+		m.logsErr = msg.err.Error()
+		return m
+	}
+	following := m.tabCursors[tabLogs] >= len(m.logs)-1
+	m.logs, m.logsErr = msg.entries, ""
+	if following || m.tabCursors[tabLogs] >= len(m.logs) {
+		m.tabCursors[tabLogs] = max(len(m.logs)-1, 0)
+	}
+	return m
+}
+
+// handleLogsKey handles the keys specific to the Logs tab.
+func (m model) handleLogsKey(key string) (model, tea.Cmd, bool) {
+	page := m.logsHeight()
+	switch key {
+	case "u":
+		m.logUnit = (m.logUnit + 1) % len(logUnits)
+	case "p":
+		m.logPriority = (m.logPriority + 1) % len(logPriorities)
+	case "/":
+		m.mode = modeEdit
+		m.editField = "grep"
+		m.input = m.logGrep
+		return m, nil, true
+	case "pgup":
+		m.tabCursors[tabLogs] = max(m.tabCursors[tabLogs]-page, 0)
+		return m, nil, true
+	case "pgdown":
+		m.tabCursors[tabLogs] = max(min(m.tabCursors[tabLogs]+page, len(m.logs)-1), 0)
+		return m, nil, true
+	case "end", "G":
+		m.tabCursors[tabLogs] = max(len(m.logs)-1, 0)
+		return m, nil, true
+	default:
+		return m, nil, false
+	}
+	m.logs, m.logsErr = nil, ""
+	m.tabCursors[tabLogs] = 0
+	return m, m.logsCmd(), true
+}
+
+// logsHeight is how many entries fit on the screen.
+func (m model) logsHeight() int {
+	return max(m.height-12, 5)
+}
+
+func (m model) renderLogsTab(b *strings.Builder) {
+	unit := logUnits[m.logUnit]
+	if unit == "" {
+		unit = "all"
+	}
+	priority := logPriorities[m.logPriority]
+	if priority == "" {
+		priority = "all"
+	}
+	grep := m.logGrep
+	switch {
+	case m.mode == modeEdit && m.editField == "grep":
+		grep = m.input + "_"
+	case grep == "":
+		grep = "-"
+	}
+	b.WriteString(helpStyle.Render(fmt.Sprintf("  Unit: %s  Priority: %s  Grep: %s", unit, priority, grep)))
+	b.WriteString("\n\n")
+
+	switch {
+	case m.logsErr != "":
+		b.WriteString(inactiveStyle.Render("  " + m.logsErr))
+		b.WriteString("\n")
+		return
+	case len(m.logs) == 0:
+		b.WriteString(helpStyle.Render("  No log entries"))
+		b.WriteString("\n")
+		return
+	}
+
+	// Show a window of entries that keeps the cursor in view.
+	height := m.logsHeight()
+	cursor := m.tabCursors[tabLogs]
+	end := min(max(cursor+1, height), len(m.logs))
+	start := max(end-height, 0)
+
+	for i := start; i < end; i++ {
+		e := m.logs[i]
+		prefix := "  "
+		if i == cursor {
+			prefix = cursorStyle.Render("> ")
+		}
+		unit := strings.TrimPrefix(strings.TrimPrefix(e.Unit, "wpe-webkit-kiosk"), "-")
+		if unit == "" {
+			unit = "kiosk"
+		}
+		meta := fmt.Sprintf("%s %-5s %-7s ", e.Time.Format("Jan 02 15:04:05"), unit, e.PriorityName())
+		msg := e.Message
+		if r, width := []rune(msg), m.width-len(meta)-2; width > 10 && len(r) > width {
+			msg = string(r[:width-1]) + "…"
+		}
+		switch {
+		case e.Priority <= 3:
+			msg = inactiveStyle.Render(msg)
+		case e.Priority == 4:
+			msg = messageStyle.Render(msg)
+		}
+		b.WriteString(prefix + helpStyle.Render(meta) + msg + "\n")
+	}
+}
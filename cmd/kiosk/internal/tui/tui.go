@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -10,16 +11,26 @@ import (
 	"time"
 
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/audio"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/audit"
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/config"
 	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/logs"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/playlist"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/service"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-const serviceName = "wpe-webkit-kiosk"
-const vncServiceName = "wpe-webkit-kiosk-vnc"
-const apiServiceName = "wpe-webkit-kiosk-api"
+const serviceName = service.KioskUnit
+const vncServiceName = service.VNCUnit
+const apiServiceName = service.APIUnit
+
+// kiosk is the launcher's D-Bus interface, shared by all commands.
+var kiosk dbus.Kiosk = dbus.Default()
+
+// services manages the kiosk's systemd units.
+var services service.Manager = service.Default()
 
 // -- Styles --
 
@@ -75,10 +86,12 @@ const (
 	tabConfig
 	tabFeatures
 	tabExtensions
+	tabPlaylist
+	tabLogs
 	tabCount
 )
 
-var tabNames = [tabCount]string{"Status", "Config", "Features", "Extensions"}
+var tabNames = [tabCount]string{"Status", "Config", "Features", "Extensions", "Playlist", "Logs"}
 
 // -- Extension info --
 
@@ -135,6 +148,7 @@ type tickMsg time.Time
 type refreshMsg struct {
 	state     string
 	url       string
+	page      dbus.PageInfo
 	since     string
 	cfgURL    string
 	cfgInsp   string
@@ -148,9 +162,17 @@ type refreshMsg struct {
 	muted     bool
 	audioErr  bool
 	exts      []extInfo
+	playlist  playlist.Status
 }
 type actionDoneMsg struct{ text string }
 
+// kioskEventMsg is a signal from the kiosk; ok is false once the
+// subscription has ended.
+type kioskEventMsg struct {
+	ev dbus.Event
+	ok bool
+}
+
 // -- Mode --
 
 type mode int
@@ -166,6 +188,7 @@ const (
 type model struct {
 	state     string
 	url       string
+	page      dbus.PageInfo
 	since     string
 	cfgURL    string
 	cfgInsp   string
@@ -179,6 +202,13 @@ type model struct {
 	muted     bool
 	audioErr  bool
 	exts      []extInfo
+	playlist  playlist.Status
+
+	logs        []logs.Entry // oldest first
+	logsErr     string
+	logUnit     int // index into logUnits
+	logPriority int // index into logPriorities
+	logGrep     string
 
 	activeTab  tab
 	tabCursors [tabCount]int
@@ -187,6 +217,8 @@ type model struct {
 	editField string
 	input     string
 
+	signals <-chan dbus.Event // nil if the kiosk could not be subscribed to
+
 	message  string
 	quitting bool
 	width    int
@@ -196,19 +228,23 @@ type model struct {
 func (m model) tabItemCount() int {
 	switch m.activeTab {
 	case tabStatus:
-		return 7
+		return 12
 	case tabConfig:
 		return 3
 	case tabFeatures:
 		return 4
 	case tabExtensions:
 		return len(m.exts)
+	case tabPlaylist:
+		return 1
+	case tabLogs:
+		return len(m.logs)
 	}
 	return 0
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(tickCmd(), refreshCmd())
+	return tea.Batch(tickCmd(), refreshCmd(), waitEventCmd(m.signals))
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -219,11 +255,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tickMsg:
-		return m, tea.Batch(tickCmd(), refreshCmd())
+		return m, tea.Batch(tickCmd(), refreshCmd(), m.logsCmd())
+
+	case logsMsg:
+		return m.applyLogs(msg), nil
 
 	case refreshMsg:
 		m.state = msg.state
 		m.url = msg.url
+		m.page = msg.page
 		m.since = msg.since
 		m.cfgURL = msg.cfgURL
 		m.cfgInsp = msg.cfgInsp
@@ -237,6 +277,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.muted = msg.muted
 		m.audioErr = msg.audioErr
 		m.exts = msg.exts
+		m.playlist = msg.playlist
 		if m.tabCursors[tabExtensions] >= len(m.exts) && len(m.exts) > 0 {
 			m.tabCursors[tabExtensions] = len(m.exts) - 1
 		}
@@ -249,6 +290,19 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.message = msg.text
 		return m, refreshCmd()
 
+	case kioskEventMsg:
+		if !msg.ok {
+			m.signals = nil
+			return m, nil
+		}
+		switch msg.ev.Signal {
+		case dbus.SignalLoadFailed:
+			m.message = "Load failed: " + msg.ev.URL + ": " + msg.ev.Error
+		case dbus.SignalWebProcessTerminated:
+			m.message = "Web process " + msg.ev.Reason + ", reloading"
+		}
+		return m, tea.Batch(refreshCmd(), waitEventCmd(m.signals))
+
 	case tea.KeyMsg:
 		if m.mode == modeEdit {
 			return m.handleEdit(msg)
@@ -264,6 +318,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // -- Key handlers --
 
 func (m model) handleNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.activeTab == tabLogs {
+		if m, cmd, ok := m.handleLogsKey(msg.String()); ok {
+			return m, cmd
+		}
+	}
 	switch msg.String() {
 	case "q", "ctrl+c":
 		m.quitting = true
@@ -275,14 +334,14 @@ func (m model) handleNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		} else {
 			m.activeTab--
 		}
-		return m, nil
+		return m, m.logsCmd()
 
 	case "right", "l":
 		m.activeTab++
 		if m.activeTab >= tabCount {
 			m.activeTab = 0
 		}
-		return m, nil
+		return m, m.logsCmd()
 
 	case "up", "k":
 		if m.tabCursors[m.activeTab] > 0 {
@@ -311,13 +370,22 @@ func (m model) handleActivate() (tea.Model, tea.Cmd) {
 	switch m.activeTab {
 	case tabStatus:
 		switch cursor {
-		case 4:
+		case 6:
 			m.message = "Reloading..."
 			return m, reloadCmd()
-		case 5:
+		case 7:
+			m.message = "Going back..."
+			return m, goBackCmd()
+		case 8:
+			m.message = "Going forward..."
+			return m, goForwardCmd()
+		case 9:
+			m.message = "Stopping..."
+			return m, stopLoadingCmd()
+		case 10:
 			m.message = "Restarting service..."
 			return m, restartCmd()
-		case 6:
+		case 11:
 			m.message = "Clearing all data..."
 			return m, clearDataCmd()
 		}
@@ -356,6 +424,15 @@ func (m model) handleActivate() (tea.Model, tea.Cmd) {
 			m.message = "Toggling " + ext.name + "..."
 			return m, toggleExtensionCmd(ext)
 		}
+	case tabPlaylist:
+		if cursor == 0 {
+			if m.playlist.Running {
+				m.message = "Stopping rotation..."
+			} else {
+				m.message = "Starting rotation..."
+			}
+			return m, togglePlaylistCmd()
+		}
 	}
 	return m, nil
 }
@@ -384,6 +461,12 @@ func (m model) handleVolume(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// editFieldKeys maps edit mode fields to the config keys they set.
+var editFieldKeys = map[string]string{
+	"url": "URL",
+	"tty": "TTY",
+}
+
 func (m model) handleEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
@@ -392,10 +475,25 @@ func (m model) handleEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.mode = modeNormal
 		m.input = ""
 		m.editField = ""
+		if field == "grep" {
+			if err := (&logs.Filter{Grep: value}).Validate(); err != nil {
+				m.message = err.Error()
+				return m, nil
+			}
+			m.logGrep, m.logs, m.message = value, nil, ""
+			m.tabCursors[tabLogs] = 0
+			return m, m.logsCmd()
+		}
 		if value == "" {
 			m.message = ""
 			return m, nil
 		}
+		if key, ok := editFieldKeys[field]; ok {
+			if err := config.Validate(key, value); err != nil {
+				m.message = err.Error()
+				return m, nil
+			}
+		}
 		switch field {
 		case "url":
 			m.message = "Opening " + value + "..."
@@ -460,6 +558,10 @@ func (m model) View() string {
 		m.renderFeaturesTab(&b)
 	case tabExtensions:
 		m.renderExtensionsTab(&b)
+	case tabPlaylist:
+		m.renderPlaylistTab(&b)
+	case tabLogs:
+		m.renderLogsTab(&b)
 	}
 
 	// Status bar
@@ -482,6 +584,12 @@ func (m model) View() string {
 		help = "[enter] confirm  [esc] cancel"
 	case modeVolume:
 		help = "[↑/↓] adjust  [m] mute/unmute  [esc] back"
+	case modeNormal:
+		if m.activeTab == tabLogs {
+			help = "[←/→] tab  [↑/↓/pgup/pgdn] scroll  [u] unit  [p] priority  [/] grep  [q] quit"
+			break
+		}
+		fallthrough
 	default:
 		help = "[←/→] tab  [↑/↓] select  [enter] activate  [q] quit"
 	}
@@ -528,6 +636,21 @@ func (m model) renderStatusTab(b *strings.Builder) {
 		sinceStr = "-"
 	}
 
+	titleStr := m.page.Title
+	if titleStr == "" {
+		titleStr = "-"
+	}
+	pageStr := "-"
+	switch {
+	case m.url == "":
+	case m.page.Loading:
+		pageStr = fmt.Sprintf("loading %d%%", int(m.page.Progress*100))
+	case m.page.LastError != "":
+		pageStr = inactiveStyle.Render("failed") + helpStyle.Render(" "+m.page.LastError)
+	default:
+		pageStr = activeStyle.Render("loaded")
+	}
+
 	apiStr := m.cfgAPI
 	if apiStr == "" {
 		apiStr = "8100"
@@ -545,11 +668,16 @@ func (m model) renderStatusTab(b *strings.Builder) {
 	m.renderInfoRow(b, 0, "Service", stateStr)
 	m.renderInfoRow(b, 1, "Uptime", sinceStr)
 	m.renderInfoRow(b, 2, "URL", urlStr)
-	m.renderInfoRow(b, 3, "API", apiInfo)
+	m.renderInfoRow(b, 3, "Title", titleStr)
+	m.renderInfoRow(b, 4, "Page", pageStr)
+	m.renderInfoRow(b, 5, "API", apiInfo)
 	b.WriteString("\n")
-	m.renderActionRow(b, 4, "Reload page")
-	m.renderActionRow(b, 5, "Restart service")
-	m.renderActionRow(b, 6, "Clear all data")
+	m.renderActionRow(b, 6, "Reload page")
+	m.renderActionRow(b, 7, "Go back")
+	m.renderActionRow(b, 8, "Go forward")
+	m.renderActionRow(b, 9, "Stop loading")
+	m.renderActionRow(b, 10, "Restart service")
+	m.renderActionRow(b, 11, "Clear all data")
 }
 
 func (m model) renderConfigTab(b *strings.Builder) {
@@ -620,36 +748,93 @@ func (m model) renderExtensionsTab(b *strings.Builder) {
 	}
 }
 
+func (m model) renderPlaylistTab(b *strings.Builder) {
+	pl := m.playlist
+	rotationStr := inactiveStyle.Render("stopped")
+	if pl.Running {
+		rotationStr = activeStyle.Render("running")
+	}
+	entryStr := func(pos *int) string {
+		if pos == nil {
+			return "-"
+		}
+		e := pl.Entries[*pos-1]
+		return fmt.Sprintf("%s %s", e.URL, helpStyle.Render(fmt.Sprintf("(%ds)", e.Dwell)))
+	}
+
+	m.renderInfoRow(b, 0, "Rotation", rotationStr)
+	m.renderInfoRow(b, 1, "Current", entryStr(pl.Current))
+	m.renderInfoRow(b, 2, "Next", entryStr(pl.Next))
+	b.WriteString("\n")
+
+	if len(pl.Entries) == 0 {
+		b.WriteString(helpStyle.Render("  Playlist is empty. Add pages with: kiosk playlist add <url>"))
+		b.WriteString("\n")
+		return
+	}
+	for i, e := range pl.Entries {
+		line := fmt.Sprintf("  %d. %s", i+1, e.URL)
+		detail := fmt.Sprintf("  %ds", e.Dwell)
+		if e.Window != "" {
+			detail += "  " + e.Window
+		}
+		if pl.Current != nil && *pl.Current == i+1 {
+			b.WriteString(activeStyle.Render(line))
+		} else {
+			b.WriteString(line)
+		}
+		b.WriteString(helpStyle.Render(detail))
+		b.WriteString("\n")
+	}
+}
+
 // -- Commands --
 
+// recordAudit logs a mutating TUI action. Errors are ignored since the
+// dashboard has no place to show them without hiding the action result.
+func recordAudit(action, target string, oldValue, newValue any, actionErr error) {
+	if action == "config.set" {
+		// Secret values never reach the log.
+		if v, ok := oldValue.(string); ok {
+			oldValue = config.Redact(target, v)
+		}
+		if v, ok := newValue.(string); ok {
+			newValue = config.Redact(target, v)
+		}
+	}
+	audit.RecordLocal(audit.SourceTUI, action, target, oldValue, newValue, actionErr)
+}
+
 func tickCmd() tea.Cmd {
 	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
+// waitEventCmd waits for the next kiosk signal, so the dashboard updates
+// as soon as the page changes instead of on the next tick.
+func waitEventCmd(signals <-chan dbus.Event) tea.Cmd {
+	if signals == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		ev, ok := <-signals
+		return kioskEventMsg{ev, ok}
+	}
+}
+
 func refreshCmd() tea.Cmd {
 	return func() tea.Msg {
 		msg := refreshMsg{}
 
-		if out, err := exec.Command("systemctl", "show", serviceName,
-			"--property=ActiveState", "--value").Output(); err == nil {
-			msg.state = strings.TrimSpace(string(out))
-		} else {
-			msg.state = "unknown"
-		}
-
-		if msg.state == "active" {
-			if out, err := exec.Command("systemctl", "show", serviceName,
-				"--property=ActiveEnterTimestamp", "--value").Output(); err == nil {
-				msg.since = strings.TrimSpace(string(out))
-			}
+		msg.state = "unknown"
+		if st, err := services.Status(context.Background(), serviceName); err == nil {
+			msg.state, msg.since = st.State, st.ActiveSince()
 		}
 
-		if client, err := dbus.NewClient(); err == nil {
-			if url, err := client.GetUrl(); err == nil {
-				msg.url = url
-			}
+		if page, err := kiosk.GetPageInfo(context.Background()); err == nil {
+			msg.url = page.URI
+			msg.page = page
 		}
 
 		if cfg, err := config.Load(config.DefaultPath); err == nil {
@@ -662,12 +847,7 @@ func refreshCmd() tea.Cmd {
 			msg.cfgAPI = cfg.Get("API_PORT")
 		}
 
-		if out, err := exec.Command("systemctl", "show", apiServiceName,
-			"--property=ActiveState", "--value").Output(); err == nil {
-			msg.apiState = strings.TrimSpace(string(out))
-		} else {
-			msg.apiState = "unknown"
-		}
+		msg.apiState = service.State(context.Background(), services, apiServiceName)
 
 		if level, muted, err := audio.GetVolume(); err == nil {
 			msg.volume = level
@@ -678,26 +858,70 @@ func refreshCmd() tea.Cmd {
 
 		msg.exts = scanExtensions()
 
+		if pl, err := playlist.Load(playlist.DefaultPath); err == nil {
+			msg.playlist = pl.Status(msg.url, time.Now())
+		}
+
 		return msg
 	}
 }
 
 func reloadCmd() tea.Cmd {
 	return func() tea.Msg {
-		client, err := dbus.NewClient()
+		err := kiosk.Reload(context.Background())
+		recordAudit("reload", "", nil, nil, err)
 		if err != nil {
 			return actionDoneMsg{"Reload failed: " + err.Error()}
 		}
-		if err := client.Reload(); err != nil {
-			return actionDoneMsg{"Reload failed: " + err.Error()}
-		}
 		return actionDoneMsg{"Page reloaded"}
 	}
 }
 
+func goBackCmd() tea.Cmd {
+	return pageActionCmd("back", "Went back", func(ctx context.Context, p dbus.PageInfo) error {
+		if !p.CanGoBack {
+			return fmt.Errorf("there is no previous page")
+		}
+		return kiosk.GoBack(ctx)
+	})
+}
+
+func goForwardCmd() tea.Cmd {
+	return pageActionCmd("forward", "Went forward", func(ctx context.Context, p dbus.PageInfo) error {
+		if !p.CanGoForward {
+			return fmt.Errorf("there is no next page")
+		}
+		return kiosk.GoForward(ctx)
+	})
+}
+
+func stopLoadingCmd() tea.Cmd {
+	return pageActionCmd("stop", "Loading stopped", func(ctx context.Context, p dbus.PageInfo) error {
+		return kiosk.StopLoading(ctx)
+	})
+}
+
+// pageActionCmd runs a history action on the current page.
+func pageActionCmd(action, done string, run func(context.Context, dbus.PageInfo) error) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		page, err := kiosk.GetPageInfo(ctx)
+		if err == nil {
+			err = run(ctx, page)
+		}
+		recordAudit(action, "", page.URI, nil, err)
+		if err != nil {
+			return actionDoneMsg{"Failed: " + err.Error()}
+		}
+		return actionDoneMsg{done}
+	}
+}
+
 func restartCmd() tea.Cmd {
 	return func() tea.Msg {
-		if err := exec.Command("sudo", "systemctl", "restart", serviceName).Run(); err != nil {
+		err := services.Restart(context.Background(), serviceName)
+		recordAudit("restart", serviceName, nil, nil, err)
+		if err != nil {
 			return actionDoneMsg{"Restart failed: " + err.Error()}
 		}
 		return actionDoneMsg{"Service restarted"}
@@ -706,15 +930,19 @@ func restartCmd() tea.Cmd {
 
 func openCmd(url string) tea.Cmd {
 	return func() tea.Msg {
-		client, err := dbus.NewClient()
-		if err != nil {
-			return actionDoneMsg{"Open failed: " + err.Error()}
+		cfg, cfgErr := config.Load(config.DefaultPath)
+		var oldURL string
+		if cfgErr == nil {
+			oldURL = cfg.Get("URL")
 		}
-		if err := client.Open(url); err != nil {
+
+		err := kiosk.Open(context.Background(), url)
+		recordAudit("navigate", "", oldURL, url, err)
+		if err != nil {
 			return actionDoneMsg{"Open failed: " + err.Error()}
 		}
 
-		if cfg, err := config.Load(config.DefaultPath); err == nil {
+		if cfgErr == nil {
 			cfg.Set("URL", url)
 			cfg.Save()
 		}
@@ -725,13 +953,11 @@ func openCmd(url string) tea.Cmd {
 
 func clearDataCmd() tea.Cmd {
 	return func() tea.Msg {
-		client, err := dbus.NewClient()
+		err := kiosk.ClearData(context.Background(), "all")
+		recordAudit("clear", "all", nil, nil, err)
 		if err != nil {
 			return actionDoneMsg{"Clear failed: " + err.Error()}
 		}
-		if err := client.ClearData("all"); err != nil {
-			return actionDoneMsg{"Clear failed: " + err.Error()}
-		}
 		return actionDoneMsg{"All browsing data cleared"}
 	}
 }
@@ -750,11 +976,13 @@ func toggleVNCCmd() tea.Cmd {
 			cfg.Set("VNC_ENABLED", "true")
 		}
 
-		if err := cfg.Save(); err != nil {
+		err = cfg.Save()
+		recordAudit("config.set", "VNC_ENABLED", current, cfg.Get("VNC_ENABLED"), err)
+		if err != nil {
 			return actionDoneMsg{"VNC toggle failed: " + err.Error()}
 		}
 
-		if err := exec.Command("sudo", "systemctl", "restart", vncServiceName).Run(); err != nil {
+		if err := services.Restart(context.Background(), vncServiceName); err != nil {
 			return actionDoneMsg{"VNC config saved, but service restart failed: " + err.Error()}
 		}
 
@@ -779,11 +1007,13 @@ func toggleCursorCmd() tea.Cmd {
 			cfg.Set("CURSOR_VISIBLE", "false")
 		}
 
-		if err := cfg.Save(); err != nil {
+		err = cfg.Save()
+		recordAudit("config.set", "CURSOR_VISIBLE", current, cfg.Get("CURSOR_VISIBLE"), err)
+		if err != nil {
 			return actionDoneMsg{"Cursor toggle failed: " + err.Error()}
 		}
 
-		if err := exec.Command("sudo", "systemctl", "restart", serviceName).Run(); err != nil {
+		if err := services.Restart(context.Background(), serviceName); err != nil {
 			return actionDoneMsg{"Cursor config saved, but service restart failed: " + err.Error()}
 		}
 
@@ -796,28 +1026,20 @@ func toggleCursorCmd() tea.Cmd {
 
 func setTTYCmd(value string) tea.Cmd {
 	return func() tea.Msg {
-		n := 0
-		for _, c := range value {
-			if c < '0' || c > '9' {
-				return actionDoneMsg{"Invalid TTY number: " + value}
-			}
-			n = n*10 + int(c-'0')
-		}
-		if n < 1 || n > 12 {
-			return actionDoneMsg{"TTY must be between 1 and 12"}
-		}
-
 		cfg, err := config.Load(config.DefaultPath)
 		if err != nil {
 			return actionDoneMsg{"TTY set failed: " + err.Error()}
 		}
 
+		oldValue := cfg.Get("TTY")
 		cfg.Set("TTY", value)
-		if err := cfg.Save(); err != nil {
+		err = cfg.Save()
+		recordAudit("config.set", "TTY", oldValue, value, err)
+		if err != nil {
 			return actionDoneMsg{"TTY set failed: " + err.Error()}
 		}
 
-		if err := exec.Command("sudo", "systemctl", "restart", serviceName).Run(); err != nil {
+		if err := services.Restart(context.Background(), serviceName); err != nil {
 			return actionDoneMsg{"TTY set to " + value + ", but service restart failed: " + err.Error()}
 		}
 		return actionDoneMsg{"TTY set to " + value + " (service restarted)"}
@@ -838,8 +1060,10 @@ func toggleExtensionCmd(ext extInfo) tea.Cmd {
 		var err error
 		if ext.enabled {
 			err = exec.Command("sudo", "/usr/bin/touch", disabledPath).Run()
+			recordAudit("extension.disable", ext.dirName, nil, nil, err)
 		} else {
 			err = exec.Command("sudo", "/usr/bin/rm", disabledPath).Run()
+			recordAudit("extension.enable", ext.dirName, nil, nil, err)
 		}
 		if err != nil {
 			action := "enable"
@@ -854,16 +1078,45 @@ func toggleExtensionCmd(ext extInfo) tea.Cmd {
 			action = "disabled"
 		}
 
-		if err := exec.Command("sudo", "/usr/bin/systemctl", "restart", serviceName).Run(); err != nil {
+		if err := services.Restart(context.Background(), serviceName); err != nil {
 			return actionDoneMsg{ext.name + " " + action + ", but restart failed: " + err.Error()}
 		}
 		return actionDoneMsg{ext.name + " " + action + " (service restarted)"}
 	}
 }
 
+func togglePlaylistCmd() tea.Cmd {
+	return func() tea.Msg {
+		pl, err := playlist.Load(playlist.DefaultPath)
+		if err != nil {
+			return actionDoneMsg{"Playlist toggle failed: " + err.Error()}
+		}
+		if !pl.Running && len(pl.Entries) == 0 {
+			return actionDoneMsg{"Playlist is empty"}
+		}
+
+		pl.Running = !pl.Running
+		action := "playlist.stop"
+		if pl.Running {
+			action = "playlist.start"
+		}
+		err = pl.Save()
+		recordAudit(action, "", !pl.Running, pl.Running, err)
+		if err != nil {
+			return actionDoneMsg{"Playlist toggle failed: " + err.Error()}
+		}
+		if pl.Running {
+			return actionDoneMsg{"Rotation started"}
+		}
+		return actionDoneMsg{"Rotation stopped"}
+	}
+}
+
 func volumeSetCmd(level int) tea.Cmd {
 	return func() tea.Msg {
-		if err := audio.SetVolume(level); err != nil {
+		err := audio.SetVolume(level)
+		recordAudit("volume.set", "", nil, level, err)
+		if err != nil {
 			return actionDoneMsg{fmt.Sprintf("Volume failed: %s", err)}
 		}
 		return actionDoneMsg{fmt.Sprintf("Volume: %d%%", level)}
@@ -872,7 +1125,9 @@ func volumeSetCmd(level int) tea.Cmd {
 
 func volumeToggleMuteCmd() tea.Cmd {
 	return func() tea.Msg {
-		if err := audio.ToggleMute(); err != nil {
+		err := audio.ToggleMute()
+		recordAudit("volume.toggle_mute", "", nil, nil, err)
+		if err != nil {
 			return actionDoneMsg{fmt.Sprintf("Mute toggle failed: %s", err)}
 		}
 		return actionDoneMsg{"Mute toggled"}
@@ -881,7 +1136,12 @@ func volumeToggleMuteCmd() tea.Cmd {
 
 // Run starts the TUI dashboard.
 func Run() error {
-	p := tea.NewProgram(model{state: "loading..."}, tea.WithAltScreen())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := model{state: "loading..."}
+	m.signals, _ = kiosk.Subscribe(ctx)
+	p := tea.NewProgram(m, tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
@@ -13,7 +13,19 @@ var rootCmd = &cobra.Command{
 	Use:   "kiosk",
 	Short: "WPE WebKit Kiosk management tool",
 	Long:  "CLI and TUI tool for managing the WPE WebKit Kiosk service.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkOutputFormat(); err != nil {
+			return err
+		}
+		if isLocalOnly(cmd) {
+			return requireLocal(cmd)
+		}
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireLocal(cmd); err != nil {
+			return err
+		}
 		return tui.Run()
 	},
 }
@@ -21,6 +33,10 @@ var rootCmd = &cobra.Command{
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "Output format: table, json or yaml")
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/client"
+	"github.com/tomasz-mizak/wpe-webkit-kiosk/cmd/kiosk/internal/dbus"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats selected with --output.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// Exit codes. exitServiceDown follows the LSB convention for
+// "program is not running".
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitServiceDown = 3
+)
+
+var outputFormat string
+
+func checkOutputFormat() error {
+	switch outputFormat {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid --output %q (valid: table, json, yaml)", outputFormat)
+}
+
+// structuredOutput reports whether a machine-readable format was requested.
+func structuredOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// printResult writes v in the requested structured format, or calls table
+// to print the human-readable form.
+func printResult(v any, table func()) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSONTo(os.Stdout, v)
+	case outputYAML:
+		return writeYAMLTo(os.Stdout, v)
+	}
+	table()
+	return nil
+}
+
+// printStructured writes v if a structured format was requested. Commands
+// that print their table output as they go use it for the final result.
+func printStructured(v any) error {
+	if !structuredOutput() {
+		return nil
+	}
+	return printResult(v, nil)
+}
+
+// humanf prints progress and hint messages that only belong in table output.
+func humanf(format string, a ...any) {
+	if !structuredOutput() {
+		fmt.Printf(format, a...)
+	}
+}
+
+// humanOut is where prompts and command chatter go, keeping stdout clean
+// for structured output.
+func humanOut() io.Writer {
+	if structuredOutput() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func writeJSONTo(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeYAMLTo renders v as YAML using its JSON field names, so both formats
+// share one documented structure.
+func writeYAMLTo(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	resetStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// resetStyle drops the flow and quoting styles inherited from JSON so the
+// encoder picks block style and quotes only where needed.
+func resetStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetStyle(c)
+	}
+}
+
+// serviceDownError marks a failure caused by the kiosk service not running.
+type serviceDownError struct {
+	err error
+}
+
+func (e *serviceDownError) Error() string { return e.err.Error() }
+func (e *serviceDownError) Unwrap() error { return e.err }
+
+// exitCode maps a command error to the process exit status.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var down *serviceDownError
+	if errors.As(err, &down) || errors.Is(err, dbus.ErrNotRunning) {
+		return exitServiceDown
+	}
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusServiceUnavailable {
+		return exitServiceDown
+	}
+	return exitError
+}
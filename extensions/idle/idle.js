@@ -0,0 +1,22 @@
+(function () {
+  'use strict';
+
+  var kiosk = window.__kiosk;
+  if (!kiosk) return;
+
+  /* Report at most one activity message per interval; the watchdog only
+     needs second resolution. */
+  var INTERVAL = 5000;
+  var last = 0;
+
+  function report() {
+    var now = Date.now();
+    if (now - last < INTERVAL) return;
+    last = now;
+    kiosk.sendMessage('activity').catch(function () {});
+  }
+
+  ['pointerdown', 'pointermove', 'keydown', 'touchstart', 'wheel', 'scroll'].forEach(function (type) {
+    window.addEventListener(type, report, { capture: true, passive: true });
+  });
+})();
@@ -13,6 +13,39 @@
 
 static WebKitWebView *g_web_view = NULL;
 static WebKitNetworkSession *g_session = NULL;
+static GDBusConnection *g_dbus_conn = NULL; /* set once the bus is acquired */
+
+/* Counters reported by GetStats */
+static guint32 g_page_loads = 0;
+static guint32 g_web_process_crashes = 0;
+
+/* Error of the last failed load, reported by GetPageInfo */
+static gchar *g_last_load_error = NULL;
+
+/* ---- Idle watchdog ---- */
+
+#define IDLE_ACTION_HOME  "home"   /* navigate to the configured URL */
+#define IDLE_ACTION_RESET "reset"  /* also clear cookies and session storage */
+
+static guint g_idle_timeout = 0;        /* seconds, 0 = disabled */
+static gboolean g_idle_clear = FALSE;   /* IDLE_ACTION=reset */
+static gint64 g_last_activity = 0;      /* monotonic, microseconds */
+static gboolean g_idle_at_home = TRUE;  /* no activity since the last reset */
+static gboolean g_idle_resetting = TRUE; /* next load is the home page */
+static const gchar *g_start_url = NULL;
+
+/* ---- Navigation policy ---- */
+
+#define BLOCKED_HISTORY 16
+
+typedef struct {
+    gchar *url;
+    gchar *reason;
+} BlockedNavigation;
+
+/* Last blocked navigations, a ring indexed by g_blocked_total */
+static BlockedNavigation g_blocked[BLOCKED_HISTORY];
+static guint32 g_blocked_total = 0;
 
 /* ---- Extension metadata ---- */
 
@@ -184,6 +217,250 @@ static void scan_extensions(const gchar *extensions_dir)
     g_dir_close(dir);
 }
 
+/* ---- Config file ---- */
+
+/* Returns the value of key in the config file (WPE_KIOSK_CONFIG), or NULL
+ * if it is not set. Used for keys applied without a restart. */
+static gchar *read_config_value(const gchar *key)
+{
+    const char *path = getenv("WPE_KIOSK_CONFIG");
+    gchar *contents = NULL;
+    gchar *result = NULL;
+
+    if (!path || !g_file_get_contents(path, &contents, NULL, NULL))
+        return NULL;
+
+    gchar *prefix = g_strconcat(key, "=", NULL);
+    gchar **lines = g_strsplit(contents, "\n", -1);
+    for (int i = 0; lines[i]; i++) {
+        gchar *line = g_strstrip(lines[i]);
+        if (!g_str_has_prefix(line, prefix))
+            continue;
+        gchar *value = line + strlen(prefix);
+        gsize len = strlen(value);
+        if (len >= 2 && (value[0] == '"' || value[0] == '\'') &&
+            value[len - 1] == value[0]) {
+            value[len - 1] = '\0';
+            value++;
+        }
+        g_free(result);
+        result = g_strdup(value);
+    }
+    g_strfreev(lines);
+    g_free(prefix);
+    g_free(contents);
+    return result;
+}
+
+/* Returns the URL currently set in the config file, so that a URL changed
+ * with "kiosk config set" is used without a restart. Falls back to the URL
+ * the kiosk was started with. */
+static gchar *read_home_url(void)
+{
+    gchar *url = read_config_value("URL");
+    if (url && *url)
+        return url;
+    g_free(url);
+    return g_strdup(g_start_url);
+}
+
+/* ---- Idle watchdog ---- */
+
+static void idle_touch(void)
+{
+    g_last_activity = g_get_monotonic_time();
+}
+
+static guint idle_seconds(void)
+{
+    return (guint)((g_get_monotonic_time() - g_last_activity) / G_USEC_PER_SEC);
+}
+
+static void idle_go_home(void)
+{
+    if (!g_web_view) return;
+    gchar *url = read_home_url();
+    g_message("Idle for %u s, returning to %s", g_idle_timeout, url);
+    g_idle_resetting = TRUE;
+    webkit_web_view_load_uri(g_web_view, url);
+    g_free(url);
+}
+
+static void on_idle_clear_finished(GObject *source, GAsyncResult *result,
+                                   gpointer user_data)
+{
+    (void)user_data;
+    GError *error = NULL;
+
+    if (!webkit_website_data_manager_clear_finish(
+            WEBKIT_WEBSITE_DATA_MANAGER(source), result, &error)) {
+        g_warning("Idle reset: cannot clear session data: %s", error->message);
+        g_error_free(error);
+    }
+    idle_go_home();
+}
+
+static gboolean on_idle_check(gpointer user_data)
+{
+    (void)user_data;
+
+    if (g_idle_at_home || idle_seconds() < g_idle_timeout)
+        return G_SOURCE_CONTINUE;
+
+    g_idle_at_home = TRUE;
+    idle_touch();
+
+    if (g_idle_clear && g_session) {
+        WebKitWebsiteDataManager *manager =
+            webkit_network_session_get_website_data_manager(g_session);
+        webkit_website_data_manager_clear(manager,
+            WEBKIT_WEBSITE_DATA_COOKIES | WEBKIT_WEBSITE_DATA_SESSION_STORAGE,
+            0, NULL, on_idle_clear_finished, NULL);
+    } else {
+        idle_go_home();
+    }
+    return G_SOURCE_CONTINUE;
+}
+
+static void setup_idle_watchdog(void)
+{
+    const char *timeout = getenv("WPE_KIOSK_IDLE_TIMEOUT");
+    const char *action = getenv("WPE_KIOSK_IDLE_ACTION");
+
+    g_idle_timeout = timeout ? (guint)strtoul(timeout, NULL, 10) : 0;
+    g_idle_clear = g_strcmp0(action, IDLE_ACTION_RESET) == 0;
+    idle_touch();
+
+    if (g_idle_timeout == 0) return;
+
+    g_message("Idle watchdog: %s after %u s",
+              g_idle_clear ? IDLE_ACTION_RESET : IDLE_ACTION_HOME,
+              g_idle_timeout);
+    g_timeout_add_seconds(1, on_idle_check, NULL);
+}
+
+/* ---- Navigation policy ---- */
+
+/* The rules mirror internal/policy in the kiosk CLI: a URL is blocked if
+ * it matches a NAV_DENY pattern, or if NAV_ALLOW is set and it matches
+ * none of its patterns. Both are re-read on every navigation, so policy
+ * changes apply without a restart. */
+
+/* Returns the string URL patterns are matched against: scheme://host/path,
+ * or scheme:path for URLs without a host (about:blank). Sets *host to the
+ * lowercase host, or NULL for the latter. Returns NULL if uri is invalid. */
+static gchar *navigation_key(const gchar *uri, gchar **host)
+{
+    GUri *u = g_uri_parse(uri, G_URI_FLAGS_NONE, NULL);
+    *host = NULL;
+    if (!u) return NULL;
+
+    gchar *scheme = g_ascii_strdown(g_uri_get_scheme(u), -1);
+    const gchar *h = g_uri_get_host(u);
+    const gchar *path = g_uri_get_path(u);
+    gchar *key;
+
+    if ((!h || !*h) && path[0] != '/') {
+        key = g_strconcat(scheme, ":", path, NULL);
+    } else {
+        *host = g_ascii_strdown(h ? h : "", -1);
+        key = g_strconcat(scheme, "://", *host, *path ? path : "/", NULL);
+    }
+    g_free(scheme);
+    g_uri_unref(u);
+    return key;
+}
+
+static gboolean pattern_matches(const gchar *pattern, const gchar *host,
+                                const gchar *key)
+{
+    gboolean match;
+
+    if (!strpbrk(pattern, ":/")) {
+        /* Host pattern: example.com, *.example.com */
+        if (!host) return FALSE;
+        gchar *lower = g_ascii_strdown(pattern, -1);
+        match = g_pattern_match_simple(lower, host);
+        g_free(lower);
+        return match;
+    }
+
+    gchar *full;
+    const gchar *sep = strstr(pattern, "://");
+    if (!sep) {
+        /* about:*, or host/path with any scheme */
+        full = strchr(pattern, ':') ? g_strdup(pattern)
+                                    : g_strconcat("*://", pattern, NULL);
+    } else {
+        gchar *scheme = g_ascii_strdown(pattern, sep - pattern);
+        const gchar *rest = sep + 3;
+        full = g_strconcat(scheme, "://", rest,
+                           strchr(rest, '/') ? "" : "/*", NULL);
+        g_free(scheme);
+    }
+    match = g_pattern_match_simple(full, key);
+    g_free(full);
+    return match;
+}
+
+/* Returns why navigating to uri is blocked, or NULL if it is allowed. */
+static gchar *navigation_blocked(const gchar *uri)
+{
+    if (g_strcmp0(uri, "about:blank") == 0 || g_strcmp0(uri, "about:srcdoc") == 0)
+        return NULL;
+
+    gchar *allow_value = read_config_value("NAV_ALLOW");
+    gchar *deny_value = read_config_value("NAV_DENY");
+    gchar **allow = g_strsplit_set(allow_value ? allow_value : "", " \t", -1);
+    gchar **deny = g_strsplit_set(deny_value ? deny_value : "", " \t", -1);
+    g_free(allow_value);
+    g_free(deny_value);
+
+    gboolean has_allow = FALSE;
+    for (int i = 0; allow[i]; i++)
+        if (*allow[i]) has_allow = TRUE;
+
+    gchar *host = NULL;
+    gchar *key = navigation_key(uri, &host);
+    gchar *reason = NULL;
+
+    if (!key) {
+        if (has_allow)
+            reason = g_strdup("cannot parse URL");
+        goto out;
+    }
+    for (int i = 0; deny[i]; i++) {
+        if (*deny[i] && pattern_matches(deny[i], host, key)) {
+            reason = g_strdup_printf("matches deny rule \"%s\"", deny[i]);
+            goto out;
+        }
+    }
+    if (!has_allow)
+        goto out;
+    for (int i = 0; allow[i]; i++) {
+        if (*allow[i] && pattern_matches(allow[i], host, key))
+            goto out;
+    }
+    reason = g_strdup("matches no allow rule");
+
+out:
+    g_free(key);
+    g_free(host);
+    g_strfreev(allow);
+    g_strfreev(deny);
+    return reason;
+}
+
+static void record_blocked(const gchar *uri, const gchar *reason)
+{
+    BlockedNavigation *b = &g_blocked[g_blocked_total % BLOCKED_HISTORY];
+    g_free(b->url);
+    g_free(b->reason);
+    b->url = g_strdup(uri);
+    b->reason = g_strdup(reason);
+    g_blocked_total++;
+}
+
 /* ---- Overlay setup ---- */
 
 static const gchar OVERLAY_SCRIPT_TEMPLATE[] =
@@ -564,6 +841,13 @@ static gboolean on_script_message(WebKitUserContentManager *manager,
         g_object_unref(result);
         g_free(result_str);
         g_free(stats);
+    } else if (g_strcmp0(type, "activity") == 0) {
+        /* Sent by the idle extension on user input */
+        idle_touch();
+        g_idle_at_home = FALSE;
+        JSCValue *result = jsc_value_new_string(ctx, "ok");
+        webkit_script_message_reply_return_value(reply, result);
+        g_object_unref(result);
     } else {
         g_message("Extension message: %s", str);
         JSCValue *result = jsc_value_new_string(ctx, "ok");
@@ -660,15 +944,82 @@ static const gchar introspection_xml[] =
     "    <method name='GetUrl'>"
     "      <arg type='s' name='url' direction='out'/>"
     "    </method>"
+    "    <method name='GoBack'/>"
+    "    <method name='GoForward'/>"
+    "    <method name='StopLoading'/>"
+    "    <method name='GetPageInfo'>"
+    "      <arg type='s' name='uri' direction='out'/>"
+    "      <arg type='s' name='title' direction='out'/>"
+    "      <arg type='d' name='progress' direction='out'/>"
+    "      <arg type='b' name='is_loading' direction='out'/>"
+    "      <arg type='b' name='can_go_back' direction='out'/>"
+    "      <arg type='b' name='can_go_forward' direction='out'/>"
+    "      <arg type='s' name='last_error' direction='out'/>"
+    "    </method>"
     "    <method name='ClearData'>"
     "      <arg type='s' name='scope' direction='in'/>"
     "    </method>"
     "    <method name='ListExtensions'>"
     "      <arg type='a(ssb)' name='extensions' direction='out'/>"
     "    </method>"
+    "    <method name='GetStats'>"
+    "      <arg type='u' name='page_loads' direction='out'/>"
+    "      <arg type='u' name='web_process_crashes' direction='out'/>"
+    "    </method>"
+    "    <method name='EvaluateScript'>"
+    "      <arg type='s' name='script' direction='in'/>"
+    "      <arg type='s' name='result_json' direction='out'/>"
+    "    </method>"
+    "    <method name='Screenshot'>"
+    "      <arg type='ay' name='png' direction='out'/>"
+    "    </method>"
+    "    <method name='GetBlocked'>"
+    "      <arg type='u' name='total' direction='out'/>"
+    "      <arg type='a(ss)' name='recent' direction='out'/>"
+    "    </method>"
+    "    <method name='GetIdle'>"
+    "      <arg type='u' name='timeout' direction='out'/>"
+    "      <arg type='s' name='action' direction='out'/>"
+    "      <arg type='u' name='idle_seconds' direction='out'/>"
+    "    </method>"
+    "    <signal name='LoadStarted'>"
+    "      <arg type='s' name='uri'/>"
+    "    </signal>"
+    "    <signal name='LoadFinished'>"
+    "      <arg type='s' name='uri'/>"
+    "    </signal>"
+    "    <signal name='LoadFailed'>"
+    "      <arg type='s' name='uri'/>"
+    "      <arg type='s' name='error'/>"
+    "    </signal>"
+    "    <signal name='WebProcessTerminated'>"
+    "      <arg type='s' name='reason'/>"
+    "    </signal>"
+    "    <signal name='UrlChanged'>"
+    "      <arg type='s' name='uri'/>"
+    "    </signal>"
+    "    <signal name='TitleChanged'>"
+    "      <arg type='s' name='title'/>"
+    "    </signal>"
     "  </interface>"
     "</node>";
 
+/* Emit a com.wpe.Kiosk signal; dropped until the bus name is acquired */
+static void emit_signal(const gchar *name, GVariant *params)
+{
+    if (!g_dbus_conn) {
+        g_variant_unref(g_variant_ref_sink(params));
+        return;
+    }
+    GError *error = NULL;
+    if (!g_dbus_connection_emit_signal(g_dbus_conn, NULL, "/",
+                                       "com.wpe.Kiosk", name, params,
+                                       &error)) {
+        g_warning("D-Bus signal %s failed: %s", name, error->message);
+        g_error_free(error);
+    }
+}
+
 static void on_clear_data_finished(GObject *source, GAsyncResult *result,
                                    gpointer user_data)
 {
@@ -688,6 +1039,90 @@ static void on_clear_data_finished(GObject *source, GAsyncResult *result,
     }
 }
 
+static void on_evaluate_finished(GObject *source, GAsyncResult *result,
+                                 gpointer user_data)
+{
+    GDBusMethodInvocation *invocation = (GDBusMethodInvocation *)user_data;
+    GError *error = NULL;
+
+    JSCValue *value = webkit_web_view_evaluate_javascript_finish(
+        WEBKIT_WEB_VIEW(source), result, &error);
+    if (!value) {
+        /* Uncaught exceptions end up here with their message */
+        g_dbus_method_invocation_return_dbus_error(invocation,
+            "com.wpe.Kiosk.Error.ScriptException", error->message);
+        g_error_free(error);
+        return;
+    }
+
+    gchar *json = jsc_value_is_undefined(value)
+        ? g_strdup("null") : jsc_value_to_json(value, 0);
+    if (json) {
+        g_dbus_method_invocation_return_value(invocation,
+                                              g_variant_new("(s)", json));
+    } else {
+        JSCContext *ctx = jsc_value_get_context(value);
+        jsc_context_clear_exception(ctx);
+        g_dbus_method_invocation_return_dbus_error(invocation,
+            "com.wpe.Kiosk.Error.ScriptException",
+            "Result cannot be serialized to JSON");
+    }
+    g_free(json);
+    g_object_unref(value);
+}
+
+static void on_snapshot_png_loaded(GObject *source, GAsyncResult *result,
+                                  gpointer user_data)
+{
+    GDBusMethodInvocation *invocation = (GDBusMethodInvocation *)user_data;
+    GError *error = NULL;
+
+    GInputStream *in = g_loadable_icon_load_finish(G_LOADABLE_ICON(source),
+                                                   result, NULL, &error);
+    g_object_unref(source);
+    if (!in) {
+        g_dbus_method_invocation_return_gerror(invocation, error);
+        g_error_free(error);
+        return;
+    }
+
+    GOutputStream *out = g_memory_output_stream_new_resizable();
+    if (g_output_stream_splice(out, in,
+            G_OUTPUT_STREAM_SPLICE_CLOSE_SOURCE |
+            G_OUTPUT_STREAM_SPLICE_CLOSE_TARGET, NULL, &error) < 0) {
+        g_dbus_method_invocation_return_gerror(invocation, error);
+        g_error_free(error);
+    } else {
+        GBytes *png = g_memory_output_stream_steal_as_bytes(
+            G_MEMORY_OUTPUT_STREAM(out));
+        GVariant *data = g_variant_new_from_bytes(G_VARIANT_TYPE_BYTESTRING,
+                                                  png, TRUE);
+        g_dbus_method_invocation_return_value(invocation,
+                                              g_variant_new_tuple(&data, 1));
+        g_bytes_unref(png);
+    }
+    g_object_unref(out);
+    g_object_unref(in);
+}
+
+static void on_snapshot_ready(GObject *source, GAsyncResult *result,
+                              gpointer user_data)
+{
+    GDBusMethodInvocation *invocation = (GDBusMethodInvocation *)user_data;
+    GError *error = NULL;
+
+    WebKitImage *image = webkit_web_view_get_snapshot_finish(
+        WEBKIT_WEB_VIEW(source), result, &error);
+    if (!image) {
+        g_dbus_method_invocation_return_gerror(invocation, error);
+        g_error_free(error);
+        return;
+    }
+    /* WebKitImage is a GLoadableIcon that loads as PNG */
+    g_loadable_icon_load_async(G_LOADABLE_ICON(image), 0, NULL,
+                               on_snapshot_png_loaded, invocation);
+}
+
 static void handle_method_call(GDBusConnection *conn,
                                const gchar *sender,
                                const gchar *object_path,
@@ -715,6 +1150,35 @@ static void handle_method_call(GDBusConnection *conn,
             ? webkit_web_view_get_uri(g_web_view) : "";
         g_dbus_method_invocation_return_value(
             invocation, g_variant_new("(s)", url ? url : ""));
+    } else if (g_strcmp0(method_name, "GoBack") == 0) {
+        if (g_web_view)
+            webkit_web_view_go_back(g_web_view);
+        g_dbus_method_invocation_return_value(invocation, NULL);
+    } else if (g_strcmp0(method_name, "GoForward") == 0) {
+        if (g_web_view)
+            webkit_web_view_go_forward(g_web_view);
+        g_dbus_method_invocation_return_value(invocation, NULL);
+    } else if (g_strcmp0(method_name, "StopLoading") == 0) {
+        if (g_web_view)
+            webkit_web_view_stop_loading(g_web_view);
+        g_dbus_method_invocation_return_value(invocation, NULL);
+    } else if (g_strcmp0(method_name, "GetPageInfo") == 0) {
+        const gchar *uri = NULL, *title = NULL;
+        gdouble progress = 0;
+        gboolean loading = FALSE, back = FALSE, forward = FALSE;
+        if (g_web_view) {
+            uri = webkit_web_view_get_uri(g_web_view);
+            title = webkit_web_view_get_title(g_web_view);
+            progress = webkit_web_view_get_estimated_load_progress(g_web_view);
+            loading = webkit_web_view_is_loading(g_web_view);
+            back = webkit_web_view_can_go_back(g_web_view);
+            forward = webkit_web_view_can_go_forward(g_web_view);
+        }
+        g_dbus_method_invocation_return_value(
+            invocation, g_variant_new("(ssdbbbs)", uri ? uri : "",
+                                      title ? title : "", progress,
+                                      loading, back, forward,
+                                      g_last_load_error ? g_last_load_error : ""));
     } else if (g_strcmp0(method_name, "ClearData") == 0) {
         const gchar *scope = NULL;
         g_variant_get(parameters, "(&s)", &scope);
@@ -767,6 +1231,50 @@ static void handle_method_call(GDBusConnection *conn,
         }
         g_dbus_method_invocation_return_value(
             invocation, g_variant_new("(a(ssb))", &builder));
+    } else if (g_strcmp0(method_name, "GetStats") == 0) {
+        g_dbus_method_invocation_return_value(
+            invocation, g_variant_new("(uu)", g_page_loads,
+                                      g_web_process_crashes));
+    } else if (g_strcmp0(method_name, "EvaluateScript") == 0) {
+        const gchar *script = NULL;
+        g_variant_get(parameters, "(&s)", &script);
+        if (!g_web_view) {
+            g_dbus_method_invocation_return_dbus_error(invocation,
+                "com.wpe.Kiosk.Error.NotReady",
+                "Kiosk web view not initialized");
+            return;
+        }
+        webkit_web_view_evaluate_javascript(g_web_view, script, -1, NULL,
+                                            NULL, NULL, on_evaluate_finished,
+                                            invocation);
+    } else if (g_strcmp0(method_name, "Screenshot") == 0) {
+        if (!g_web_view) {
+            g_dbus_method_invocation_return_dbus_error(invocation,
+                "com.wpe.Kiosk.Error.NotReady",
+                "Kiosk web view not initialized");
+            return;
+        }
+        webkit_web_view_get_snapshot(g_web_view,
+                                     WEBKIT_SNAPSHOT_REGION_VISIBLE,
+                                     WEBKIT_SNAPSHOT_OPTIONS_NONE, NULL,
+                                     on_snapshot_ready, invocation);
+    } else if (g_strcmp0(method_name, "GetBlocked") == 0) {
+        /* Oldest first */
+        GVariantBuilder builder;
+        g_variant_builder_init(&builder, G_VARIANT_TYPE("a(ss)"));
+        guint32 n = MIN(g_blocked_total, BLOCKED_HISTORY);
+        for (guint32 i = g_blocked_total - n; i < g_blocked_total; i++) {
+            BlockedNavigation *b = &g_blocked[i % BLOCKED_HISTORY];
+            g_variant_builder_add(&builder, "(ss)", b->url, b->reason);
+        }
+        g_dbus_method_invocation_return_value(
+            invocation, g_variant_new("(ua(ss))", g_blocked_total, &builder));
+    } else if (g_strcmp0(method_name, "GetIdle") == 0) {
+        g_dbus_method_invocation_return_value(
+            invocation, g_variant_new("(usu)", g_idle_timeout,
+                                      g_idle_clear ? IDLE_ACTION_RESET
+                                                   : IDLE_ACTION_HOME,
+                                      idle_seconds()));
     }
 }
 
@@ -792,6 +1300,8 @@ static void on_bus_acquired(GDBusConnection *conn, const gchar *name,
     if (error) {
         g_warning("D-Bus register error: %s", error->message);
         g_error_free(error);
+    } else {
+        g_dbus_conn = conn;
     }
 
     g_dbus_node_info_unref(node);
@@ -813,10 +1323,98 @@ static void on_web_process_terminated(WebKitWebView *view,
     case WEBKIT_WEB_PROCESS_TERMINATED_BY_API:
         desc = "terminated by API"; break;
     }
+    if (reason != WEBKIT_WEB_PROCESS_TERMINATED_BY_API)
+        g_web_process_crashes++;
     g_warning("Web process %s, reloading...", desc);
+    emit_signal("WebProcessTerminated", g_variant_new("(s)", desc));
     webkit_web_view_reload(view);
 }
 
+static gboolean on_decide_policy(WebKitWebView *view,
+                                 WebKitPolicyDecision *decision,
+                                 WebKitPolicyDecisionType type,
+                                 gpointer data)
+{
+    (void)view; (void)data;
+    if (type != WEBKIT_POLICY_DECISION_TYPE_NAVIGATION_ACTION &&
+        type != WEBKIT_POLICY_DECISION_TYPE_NEW_WINDOW_ACTION)
+        return FALSE;
+
+    WebKitNavigationAction *action =
+        webkit_navigation_policy_decision_get_navigation_action(
+            WEBKIT_NAVIGATION_POLICY_DECISION(decision));
+    const gchar *uri = webkit_uri_request_get_uri(
+        webkit_navigation_action_get_request(action));
+
+    gchar *reason = navigation_blocked(uri);
+    if (!reason)
+        return FALSE; /* default handling */
+
+    g_warning("Navigation to %s blocked: %s", uri, reason);
+    record_blocked(uri, reason);
+    g_free(reason);
+    webkit_policy_decision_ignore(decision);
+    return TRUE;
+}
+
+static void on_load_changed(WebKitWebView *view, WebKitLoadEvent event,
+                            gpointer data)
+{
+    (void)data;
+    const gchar *uri = webkit_web_view_get_uri(view);
+    if (!uri)
+        uri = "";
+    if (event == WEBKIT_LOAD_COMMITTED) {
+        /* Navigation is activity, except the load started by the watchdog */
+        idle_touch();
+        if (g_idle_resetting)
+            g_idle_resetting = FALSE;
+        else
+            g_idle_at_home = FALSE;
+    }
+    if (event == WEBKIT_LOAD_STARTED) {
+        g_clear_pointer(&g_last_load_error, g_free);
+        emit_signal("LoadStarted", g_variant_new("(s)", uri));
+    }
+    if (event == WEBKIT_LOAD_FINISHED) {
+        g_page_loads++;
+        emit_signal("LoadFinished", g_variant_new("(s)", uri));
+    }
+}
+
+static gboolean on_load_failed(WebKitWebView *view, WebKitLoadEvent event,
+                               const gchar *failing_uri, GError *error,
+                               gpointer data)
+{
+    (void)view; (void)event; (void)data;
+    /* Stopping a load or a blocked navigation is not an error */
+    if (g_error_matches(error, WEBKIT_NETWORK_ERROR,
+                        WEBKIT_NETWORK_ERROR_CANCELLED) ||
+        g_error_matches(error, WEBKIT_POLICY_ERROR,
+                        WEBKIT_POLICY_ERROR_FRAME_LOAD_INTERRUPTED_BY_POLICY_CHANGE))
+        return FALSE;
+
+    g_free(g_last_load_error);
+    g_last_load_error = g_strdup_printf("%s: %s", failing_uri, error->message);
+    g_warning("Load failed: %s", g_last_load_error);
+    emit_signal("LoadFailed", g_variant_new("(ss)", failing_uri, error->message));
+    return FALSE; /* show the default error page */
+}
+
+static void on_uri_changed(GObject *object, GParamSpec *pspec, gpointer data)
+{
+    (void)pspec; (void)data;
+    const gchar *uri = webkit_web_view_get_uri(WEBKIT_WEB_VIEW(object));
+    emit_signal("UrlChanged", g_variant_new("(s)", uri ? uri : ""));
+}
+
+static void on_title_changed(GObject *object, GParamSpec *pspec, gpointer data)
+{
+    (void)pspec; (void)data;
+    const gchar *title = webkit_web_view_get_title(WEBKIT_WEB_VIEW(object));
+    emit_signal("TitleChanged", g_variant_new("(s)", title ? title : ""));
+}
+
 /* ---- Application ---- */
 
 static void activate(GApplication *app, gpointer user_data)
@@ -863,6 +1461,16 @@ static void activate(GApplication *app, gpointer user_data)
 
     g_signal_connect(view, "web-process-terminated",
                      G_CALLBACK(on_web_process_terminated), NULL);
+    g_signal_connect(view, "load-changed",
+                     G_CALLBACK(on_load_changed), NULL);
+    g_signal_connect(view, "load-failed",
+                     G_CALLBACK(on_load_failed), NULL);
+    g_signal_connect(view, "notify::uri",
+                     G_CALLBACK(on_uri_changed), NULL);
+    g_signal_connect(view, "notify::title",
+                     G_CALLBACK(on_title_changed), NULL);
+    g_signal_connect(view, "decide-policy",
+                     G_CALLBACK(on_decide_policy), NULL);
 
     WPEView *wpe_view = webkit_web_view_get_wpe_view(view);
     if (wpe_view) {
@@ -871,6 +1479,8 @@ static void activate(GApplication *app, gpointer user_data)
         wpe_toplevel_set_title(toplevel, "WPE Kiosk");
     }
 
+    g_start_url = url;
+    setup_idle_watchdog();
     webkit_web_view_load_uri(view, url);
 
     g_bus_own_name(G_BUS_TYPE_SYSTEM,